@@ -24,6 +24,8 @@ func main() {
 	if api.GetConfig().Mode == "dev" {
 		/*if err := api.DB.AutoMigrate(
 			&models.User{},
+			&models.ApiToken{},
+			&models.Session{},
 			&models.Job{},
 			&models.Node{},
 			&models.Port{},
@@ -37,6 +39,7 @@ func main() {
 			&models.TriggerRule{},
 			&models.TriggerJob{},
 			&models.TriggerExecution{},
+			&models.AuditLog{},
 		); err != nil {
 			api.Logger.Fatal().Err(err).Msg("Failed to migrate database")
 		}
@@ -52,6 +55,11 @@ func main() {
 	defer stop()
 	defer router.Close()
 
+	// c.ClientIP() only reads X-Forwarded-For from the configured proxies
+	if err := router.SetTrustedProxies(api.GetConfig().TrustedProxies); err != nil {
+		api.Logger.Fatal().Err(err).Msg("Invalid TRUSTED_PROXIES")
+	}
+
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "PATCH", "OPTIONS"},
@@ -83,4 +91,8 @@ func initAPI(router *graceful.Graceful) {
 	endpoints.JobHandler(router)
 	endpoints.SqlHandler(router)
 	endpoints.TriggerHandler(router)
+	endpoints.SecretHandler(router)
+	endpoints.ApiTokenHandler(router)
+	endpoints.SessionHandler(router)
+	endpoints.AuditHandler(router)
 }
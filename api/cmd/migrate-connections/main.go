@@ -0,0 +1,38 @@
+// Command migrate-connections makes the db nodes and database triggers whose inline connection
+// matches a saved database reference it, so its credentials are kept and rotated in one place.
+//
+//	go run ./cmd/migrate-connections [-dry-run]
+package main
+
+import (
+	"api"
+	"api/internal/api/service"
+	"flag"
+	"fmt"
+	"log"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report the connections that would be migrated without changing them")
+	flag.Parse()
+
+	api.InitConfig(".env")
+
+	migrated, err := service.NewMetadataService().MigrateInlineConnections(*dryRun)
+	if err != nil {
+		log.Fatalf("migration failed: %v", err)
+	}
+
+	for _, m := range migrated {
+		if m.TriggerID != 0 {
+			fmt.Printf("trigger %d -> database %d\n", m.TriggerID, m.MetadataDatabaseID)
+			continue
+		}
+		fmt.Printf("job %d node %d -> database %d\n", m.JobID, m.NodeID, m.MetadataDatabaseID)
+	}
+	if *dryRun {
+		fmt.Printf("%d connection(s) would be migrated\n", len(migrated))
+		return
+	}
+	fmt.Printf("%d connection(s) migrated\n", len(migrated))
+}
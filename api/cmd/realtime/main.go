@@ -1,6 +1,7 @@
 package main
 
 import (
+	"api"
 	"api/internal/realtime"
 	"log"
 	"net/http"
@@ -17,6 +18,9 @@ func main() {
 		log.Fatal("JWT_SECRET is required")
 	}
 
+	db := api.ConnectToPostgres(cfg.DBHost, cfg.DBUser, cfg.DBPassword, cfg.DBName, cfg.DBPort, cfg.DBSSLMode)
+	access := realtime.NewDBJobAccess(db)
+
 	hub := realtime.NewHub()
 	go hub.Run()
 
@@ -31,7 +35,7 @@ func main() {
 	}
 
 	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		realtime.ServeWS(hub, cfg.JWTSecret, w, r)
+		realtime.ServeWS(hub, access, cfg.JWTSecret, w, r)
 	})
 
 	log.Printf("Realtime service listening on %s", cfg.RealtimePort)
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"api/internal/secret"
 	"context"
 	"database/sql"
 	"fmt"
@@ -24,6 +25,7 @@ import (
 type AppConfig struct {
 	Mode               string
 	ApiPort            string
+	TrustedProxies     []string // proxies whose X-Forwarded-For gives the client IP, none when empty
 	OllamaHost         string
 	OllamaMessageLimit int
 	LogConfig          struct {
@@ -69,6 +71,67 @@ type AppConfig struct {
 		Password string
 		DB       int
 	}
+	SecretConfig struct {
+		// MasterKeys encrypt the stored credentials: "id:base64key,...", the first one is active
+		MasterKeys string
+	}
+	// OIDCConfig enables single sign-on with an OpenID Connect provider when Issuer is set
+	OIDCConfig struct {
+		Issuer       string
+		ClientID     string
+		ClientSecret string
+		RedirectURL  string
+		Scopes       []string
+		// GroupsClaim holds the groups of the user, nested claims use dots ("realm_access.roles")
+		GroupsClaim string
+		// AdminGroups are mapped to RoleAdmin, other users get RoleUser. Empty: roles are not synced
+		AdminGroups []string
+		// FrontendURL receives the tokens in its fragment after login, JSON is returned when empty
+		FrontendURL string
+	}
+	// LDAPConfig checks passwords against an LDAP / Active Directory server when URL is set
+	LDAPConfig struct {
+		URL                string
+		StartTLS           bool
+		InsecureSkipVerify bool
+		BindDN             string
+		BindPassword       string
+		UserBaseDN         string
+		// UserFilter finds the user entry, {username} is replaced by the login
+		UserFilter         string
+		EmailAttribute     string
+		FirstNameAttribute string
+		LastNameAttribute  string
+		GroupAttribute     string
+		// AdminGroups (DNs or common names) are mapped to RoleAdmin, other users get RoleUser.
+		// Empty: roles are not synced
+		AdminGroups []string
+		// SyncUsers creates the users of the directory on their first login and updates their
+		// names and email from it; otherwise only existing users with the same email can use it
+		SyncUsers bool
+		// LinkExisting links a directory entry to the local account with the same email on its
+		// first login. Off: only accounts created from the directory can use it. Admins are never linked
+		LinkExisting bool
+	}
+	// RateLimitConfig throttles logins, registrations and the expensive endpoints with sliding
+	// windows kept in Redis
+	RateLimitConfig struct {
+		Enabled bool
+		// LoginPerIP and LoginPerAccount bound the login attempts in LoginWindow
+		LoginPerIP      int
+		LoginPerAccount int
+		LoginWindow     int // in seconds
+		RegisterPerIP   int
+		RegisterWindow  int // in seconds
+		// LockoutThreshold failed logins of an account within LockoutDuration lock it for
+		// LockoutDuration. 0 disables the lockout
+		LockoutThreshold int
+		LockoutDuration  int // in minutes
+		// ExpensivePerUser bounds the calls of a user to each expensive endpoint (AI queries, job
+		// executions) in ExpensiveWindow
+		ExpensivePerUser int
+		ExpensiveWindow  int // in seconds
+	}
 }
 
 var config AppConfig
@@ -81,6 +144,7 @@ func InitConfig(envfile string) {
 	config = AppConfig{
 		Mode:               getEnvOrPanic("RUN_MODE"),
 		ApiPort:            getEnvOrPanic("API_PORT"),
+		TrustedProxies:     getListEnv("TRUSTED_PROXIES", ","),
 		OllamaHost:         getEnvOrPanic("OLLAMA_HOST"),
 		OllamaMessageLimit: getIntEnvOrPanic("OLLAMA_MESSAGE_LIMIT"),
 		SMTP: struct {
@@ -176,11 +240,99 @@ func InitConfig(envfile string) {
 			Password: GetEnv("REDIS_PASSWORD", ""),
 			DB:       getIntEnvOrDefault("REDIS_DB", 0),
 		},
+		SecretConfig: struct {
+			MasterKeys string
+		}{
+			MasterKeys: GetEnv("SECRET_MASTER_KEYS", ""),
+		},
+		OIDCConfig: struct {
+			Issuer       string
+			ClientID     string
+			ClientSecret string
+			RedirectURL  string
+			Scopes       []string
+			GroupsClaim  string
+			AdminGroups  []string
+			FrontendURL  string
+		}{
+			Issuer:       GetEnv("OIDC_ISSUER", ""),
+			ClientID:     GetEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret: GetEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:  GetEnv("OIDC_REDIRECT_URL", ""),
+			Scopes:       getListEnv("OIDC_SCOPES", ","),
+			GroupsClaim:  GetEnv("OIDC_GROUPS_CLAIM", "groups"),
+			AdminGroups:  getListEnv("OIDC_ADMIN_GROUPS", ","),
+			FrontendURL:  GetEnv("OIDC_FRONTEND_URL", ""),
+		},
+		LDAPConfig: struct {
+			URL                string
+			StartTLS           bool
+			InsecureSkipVerify bool
+			BindDN             string
+			BindPassword       string
+			UserBaseDN         string
+			UserFilter         string
+			EmailAttribute     string
+			FirstNameAttribute string
+			LastNameAttribute  string
+			GroupAttribute     string
+			AdminGroups        []string
+			SyncUsers          bool
+			LinkExisting       bool
+		}{
+			URL:                GetEnv("LDAP_URL", ""),
+			StartTLS:           getEnvBoolOrDefault("LDAP_START_TLS", false),
+			InsecureSkipVerify: getEnvBoolOrDefault("LDAP_INSECURE_SKIP_VERIFY", false),
+			BindDN:             GetEnv("LDAP_BIND_DN", ""),
+			BindPassword:       GetEnv("LDAP_BIND_PASSWORD", ""),
+			UserBaseDN:         GetEnv("LDAP_USER_BASE_DN", ""),
+			UserFilter:         GetEnv("LDAP_USER_FILTER", "(&(objectClass=person)(|(uid={username})(mail={username})))"),
+			EmailAttribute:     GetEnv("LDAP_EMAIL_ATTRIBUTE", "mail"),
+			FirstNameAttribute: GetEnv("LDAP_FIRST_NAME_ATTRIBUTE", "givenName"),
+			LastNameAttribute:  GetEnv("LDAP_LAST_NAME_ATTRIBUTE", "sn"),
+			GroupAttribute:     GetEnv("LDAP_GROUP_ATTRIBUTE", "memberOf"),
+			// DNs contain commas
+			AdminGroups:  getListEnv("LDAP_ADMIN_GROUPS", ";"),
+			SyncUsers:    getEnvBoolOrDefault("LDAP_SYNC_USERS", false),
+			LinkExisting: getEnvBoolOrDefault("LDAP_LINK_EXISTING", false),
+		},
+		RateLimitConfig: struct {
+			Enabled          bool
+			LoginPerIP       int
+			LoginPerAccount  int
+			LoginWindow      int
+			RegisterPerIP    int
+			RegisterWindow   int
+			LockoutThreshold int
+			LockoutDuration  int
+			ExpensivePerUser int
+			ExpensiveWindow  int
+		}{
+			Enabled:          getEnvBoolOrDefault("RATE_LIMIT_ENABLED", true),
+			LoginPerIP:       getIntEnvOrDefault("RATE_LIMIT_LOGIN_PER_IP", 30),
+			LoginPerAccount:  getIntEnvOrDefault("RATE_LIMIT_LOGIN_PER_ACCOUNT", 10),
+			LoginWindow:      getIntEnvOrDefault("RATE_LIMIT_LOGIN_WINDOW_SECONDS", 300),
+			RegisterPerIP:    getIntEnvOrDefault("RATE_LIMIT_REGISTER_PER_IP", 5),
+			RegisterWindow:   getIntEnvOrDefault("RATE_LIMIT_REGISTER_WINDOW_SECONDS", 3600),
+			LockoutThreshold: getIntEnvOrDefault("LOGIN_LOCKOUT_THRESHOLD", 5),
+			LockoutDuration:  getIntEnvOrDefault("LOGIN_LOCKOUT_MINUTES", 15),
+			ExpensivePerUser: getIntEnvOrDefault("RATE_LIMIT_EXPENSIVE_PER_USER", 20),
+			ExpensiveWindow:  getIntEnvOrDefault("RATE_LIMIT_EXPENSIVE_WINDOW_SECONDS", 60),
+		},
 	}
 
-	DB = connectToPostgres(config.MainDatabase.Host, config.MainDatabase.User, config.MainDatabase.Password, config.MainDatabase.DatabaseName, config.MainDatabase.Port, config.MainDatabase.SSLMode)
+	DB = ConnectToPostgres(config.MainDatabase.Host, config.MainDatabase.User, config.MainDatabase.Password, config.MainDatabase.DatabaseName, config.MainDatabase.Port, config.MainDatabase.SSLMode)
 	Logger = initLogger()
 	Redis = connectToRedis(config.RedisConfig.Host, config.RedisConfig.Port, config.RedisConfig.Password, config.RedisConfig.DB)
+
+	keyring, err := secret.ParseKeyring(config.SecretConfig.MasterKeys)
+	if err != nil {
+		log.Fatalf("SECRET_MASTER_KEYS: %s", err)
+	}
+	if keyring == nil {
+		Logger.Warn().Msg("SECRET_MASTER_KEYS is not set, credentials are stored in plaintext")
+	}
+	secret.SetKeyring(keyring)
 }
 
 func GetConfig() AppConfig {
@@ -223,6 +375,17 @@ func getIntEnvOrDefault(key string, defaultValue int) int {
 	return value
 }
 
+// getListEnv splits a variable on sep, nil when it is not set
+func getListEnv(key, sep string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), sep) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 func getEnvBoolOrDefault(key string, defaultValue bool) bool {
 	raw := os.Getenv(key)
 	if raw == "" {
@@ -235,7 +398,8 @@ func getEnvBoolOrDefault(key string, defaultValue bool) bool {
 	return value
 }
 
-func connectToPostgres(host string, username string, password string, dbname string, port string, ssl string) *gorm.DB {
+// ConnectToPostgres opens the main database, panicking when it cannot
+func ConnectToPostgres(host string, username string, password string, dbname string, port string, ssl string) *gorm.DB {
 	var err error
 	var db *gorm.DB
 	var conn *sql.DB
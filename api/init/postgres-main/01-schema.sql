@@ -13,14 +13,54 @@ CREATE TABLE IF NOT EXISTS users (
     nom TEXT NOT NULL,
     role VARCHAR(50) DEFAULT 'user',
     actif BOOLEAN DEFAULT true,
-    refresh_token TEXT,
     created_at TIMESTAMPTZ DEFAULT now(),
     updated_at TIMESTAMPTZ DEFAULT now(),
-    deleted_at TIMESTAMPTZ
+    deleted_at TIMESTAMPTZ,
+    service_account BOOLEAN DEFAULT false,
+    oidc_subject TEXT UNIQUE,
+    ldap_dn TEXT UNIQUE
 );
 
 CREATE INDEX IF NOT EXISTS idx_users_deleted_at ON users(deleted_at);
 
+-- ============================================================
+-- Personal Access Tokens (only the SHA-256 of the token is stored)
+-- ============================================================
+CREATE TABLE IF NOT EXISTS api_token (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name TEXT NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    scopes JSONB NOT NULL DEFAULT '[]',
+    expires_at TIMESTAMPTZ,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    CONSTRAINT fk_api_token_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_token_user_id ON api_token(user_id);
+
+-- ============================================================
+-- Sessions (one per login and device, refresh tokens are rotated)
+-- ============================================================
+CREATE TABLE IF NOT EXISTS session (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    generation BIGINT NOT NULL DEFAULT 1,
+    user_agent VARCHAR(255) DEFAULT '',
+    ip VARCHAR(64) DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT now(),
+    last_used_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ,
+    revoked_reason VARCHAR(32) DEFAULT '',
+    CONSTRAINT fk_session_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_user_id ON session(user_id);
+
 -- ============================================================
 -- Jobs
 -- ============================================================
@@ -33,6 +73,9 @@ CREATE TABLE IF NOT EXISTS job (
     active BOOLEAN DEFAULT false,
     visibility TEXT DEFAULT 'private',
     output_path TEXT DEFAULT '',
+    retry_policy JSONB,
+    max_runtime INTEGER DEFAULT 0,
+    parameters JSONB,
     created_at TIMESTAMPTZ DEFAULT now(),
     updated_at TIMESTAMPTZ DEFAULT now()
 );
@@ -89,7 +132,9 @@ CREATE TABLE IF NOT EXISTS metadata_database (
     database_name TEXT NOT NULL DEFAULT '',
     ssl_mode TEXT DEFAULT 'disable',
     extra TEXT DEFAULT '',
-    db_type VARCHAR(20) DEFAULT ''
+    db_type VARCHAR(20) DEFAULT '',
+    pool JSONB,
+    tls JSONB
 );
 
 CREATE INDEX IF NOT EXISTS idx_metadata_database_host ON metadata_database(host);
@@ -172,6 +217,7 @@ CREATE TABLE IF NOT EXISTS trigger_job (
     priority INTEGER DEFAULT 0,
     active BOOLEAN DEFAULT true,
     pass_event_data BOOLEAN DEFAULT false,
+    retry_policy JSONB,
     CONSTRAINT fk_trigger_job_trigger FOREIGN KEY (trigger_id) REFERENCES trigger(id) ON DELETE CASCADE,
     CONSTRAINT fk_trigger_job_job FOREIGN KEY (job_id) REFERENCES job(id) ON DELETE CASCADE
 );
@@ -194,3 +240,68 @@ CREATE TABLE IF NOT EXISTS trigger_execution (
 );
 
 CREATE INDEX IF NOT EXISTS idx_trigger_execution_trigger_id ON trigger_execution(trigger_id);
+
+-- ============================================================
+-- Job Runs (one row per execution attempt)
+-- ============================================================
+CREATE TABLE IF NOT EXISTS job_run (
+    id SERIAL PRIMARY KEY,
+    job_id BIGINT NOT NULL,
+    trigger_id BIGINT,
+    attempt INTEGER NOT NULL DEFAULT 1,
+    max_attempts INTEGER NOT NULL DEFAULT 1,
+    status VARCHAR(20) DEFAULT '',
+    error_class VARCHAR(20) DEFAULT '',
+    error TEXT DEFAULT '',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ,
+    CONSTRAINT fk_job_run_job FOREIGN KEY (job_id) REFERENCES job(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_run_job_id ON job_run(job_id);
+
+-- ============================================================
+-- Job Watermarks (high-water marks of incremental db_input nodes)
+-- ============================================================
+CREATE TABLE IF NOT EXISTS job_watermark (
+    job_id BIGINT NOT NULL,
+    node_id INTEGER NOT NULL,
+    value TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (job_id, node_id),
+    CONSTRAINT fk_job_watermark_job FOREIGN KEY (job_id) REFERENCES job(id) ON DELETE CASCADE
+);
+
+-- ============================================================
+-- Audit Log (append-only, no foreign keys: entries outlive their users and resources)
+-- ============================================================
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    user_id BIGINT,
+    user_email TEXT DEFAULT '',
+    api_token_id BIGINT,
+    ip VARCHAR(64) DEFAULT '',
+    action VARCHAR(64) NOT NULL,
+    resource_type VARCHAR(32) DEFAULT '',
+    resource_id BIGINT,
+    before JSONB,
+    after JSONB,
+    details JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_user_id ON audit_log(user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_log_action ON audit_log(action);
+CREATE INDEX IF NOT EXISTS idx_audit_log_resource ON audit_log(resource_type, resource_id);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
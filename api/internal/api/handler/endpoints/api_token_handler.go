@@ -0,0 +1,227 @@
+package endpoints
+
+import (
+	"api"
+	"api/internal/api/handler/middleware"
+	"api/internal/api/handler/request"
+	"api/internal/api/handler/response"
+	"api/internal/api/service"
+	"api/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-contrib/graceful"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type apiTokenHandler struct {
+	logger       zerolog.Logger
+	config       api.AppConfig
+	tokenService *service.ApiTokenService
+}
+
+func newApiTokenHandler() *apiTokenHandler {
+	return &apiTokenHandler{
+		logger:       api.Logger,
+		config:       api.GetConfig(),
+		tokenService: service.NewApiTokenService(),
+	}
+}
+
+// ApiTokenHandler sets up the personal access token and service account routes
+func ApiTokenHandler(router *graceful.Graceful) {
+	h := newApiTokenHandler()
+
+	tokens := router.Group("/api/v1/tokens")
+	tokens.Use(middleware.AuthMiddleware(h.config))
+	{
+		tokens.GET("", h.getAll)
+		tokens.POST("", h.create)
+		tokens.DELETE("/:id", h.revoke)
+	}
+
+	accounts := router.Group("/api/v1/admin/service-accounts")
+	accounts.Use(middleware.AuthMiddleware(h.config))
+	accounts.Use(middleware.RequireRole("admin"))
+	{
+		accounts.GET("", h.getServiceAccounts)
+		accounts.POST("", h.createServiceAccount)
+		accounts.DELETE("/:id", h.disableServiceAccount)
+		accounts.GET("/:id/tokens", h.getServiceAccountTokens)
+		accounts.POST("/:id/tokens", h.createServiceAccountToken)
+		accounts.DELETE("/:id/tokens/:tokenId", h.revokeServiceAccountToken)
+	}
+}
+
+// getAll lists the tokens of the current user
+func (slf *apiTokenHandler) getAll(c *gin.Context) {
+	userID, ok := pkg.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := slf.tokenService.FindForUser(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to list tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// create issues a token for the current user, the response holds the token once
+func (slf *apiTokenHandler) create(c *gin.Context) {
+	userID, ok := pkg.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req request.CreateApiToken
+	if err := pkg.ParseAndValidate(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
+		return
+	}
+
+	created, err := slf.tokenService.Create(userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// revoke revokes a token of the current user
+func (slf *apiTokenHandler) revoke(c *gin.Context) {
+	userID, ok := pkg.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: "Invalid ID"})
+		return
+	}
+
+	if err := slf.tokenService.Revoke(userID, uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, response.APIError{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "revoked": true})
+}
+
+func (slf *apiTokenHandler) getServiceAccounts(c *gin.Context) {
+	accounts, err := slf.tokenService.FindServiceAccounts()
+	if err != nil {
+		slf.logger.Error().Err(err).Msg("Failed to list service accounts")
+		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to list service accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+func (slf *apiTokenHandler) createServiceAccount(c *gin.Context) {
+	var req request.CreateServiceAccount
+	if err := pkg.ParseAndValidate(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
+		return
+	}
+
+	account, err := slf.tokenService.CreateServiceAccount(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, account)
+}
+
+// disableServiceAccount deactivates a service account and revokes its tokens
+func (slf *apiTokenHandler) disableServiceAccount(c *gin.Context) {
+	id, ok := slf.serviceAccountID(c)
+	if !ok {
+		return
+	}
+
+	if err := slf.tokenService.DisableServiceAccount(id); err != nil {
+		c.JSON(http.StatusNotFound, response.APIError{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "disabled": true})
+}
+
+func (slf *apiTokenHandler) getServiceAccountTokens(c *gin.Context) {
+	id, ok := slf.serviceAccountID(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := slf.tokenService.FindForUser(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to list tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (slf *apiTokenHandler) createServiceAccountToken(c *gin.Context) {
+	id, ok := slf.serviceAccountID(c)
+	if !ok {
+		return
+	}
+
+	var req request.CreateApiToken
+	if err := pkg.ParseAndValidate(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
+		return
+	}
+
+	created, err := slf.tokenService.Create(id, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+func (slf *apiTokenHandler) revokeServiceAccountToken(c *gin.Context) {
+	id, ok := slf.serviceAccountID(c)
+	if !ok {
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(c.Param("tokenId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: "Invalid token ID"})
+		return
+	}
+
+	if err := slf.tokenService.Revoke(id, uint(tokenID)); err != nil {
+		c.JSON(http.StatusNotFound, response.APIError{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"id": tokenID, "revoked": true})
+}
+
+// serviceAccountID parses the :id parameter and checks it is a service account
+func (slf *apiTokenHandler) serviceAccountID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: "Invalid ID"})
+		return 0, false
+	}
+
+	if _, err := slf.tokenService.FindServiceAccount(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, response.APIError{Message: err.Error()})
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,109 @@
+package endpoints
+
+import (
+	"api"
+	"api/internal/api/handler/mapper"
+	"api/internal/api/handler/middleware"
+	"api/internal/api/handler/request"
+	"api/internal/api/handler/response"
+	"api/internal/api/service"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-contrib/graceful"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/rs/zerolog"
+)
+
+type auditHandler struct {
+	auditService *service.AuditService
+	auditMapper  mapper.AuditMapper
+	validator    *validator.Validate
+	logger       zerolog.Logger
+	config       api.AppConfig
+}
+
+func newAuditHandler() *auditHandler {
+	return &auditHandler{
+		auditService: service.NewAuditService(),
+		auditMapper:  mapper.NewAuditMapper(),
+		validator:    validator.New(),
+		logger:       api.Logger,
+		config:       api.GetConfig(),
+	}
+}
+
+func AuditHandler(router *graceful.Graceful) {
+	h := newAuditHandler()
+
+	admin := router.Group("/api/v1/admin/audit")
+	admin.Use(middleware.AuthMiddleware(h.config))
+	admin.Use(middleware.RequireRole("admin"))
+	{
+		admin.GET("", h.getAll)
+		admin.GET("/export", h.export)
+	}
+}
+
+// auditActor returns the authenticated user and the address of a request
+func auditActor(c *gin.Context) service.AuditActor {
+	actor := service.AuditActor{Email: c.GetString("userEmail"), IP: c.ClientIP()}
+	if userID, ok := c.Get("userID"); ok {
+		id := userID.(uint)
+		actor.UserID = &id
+	}
+	if tokenID, ok := c.Get("apiTokenID"); ok {
+		id := tokenID.(uint)
+		actor.ApiTokenID = &id
+	}
+	return actor
+}
+
+func (slf *auditHandler) parseQuery(c *gin.Context) (request.AuditQuery, bool) {
+	var query request.AuditQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
+		return query, false
+	}
+	if err := slf.validator.Struct(query); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
+		return query, false
+	}
+	return query, true
+}
+
+// getAll returns a page of the audit trail, newest first
+func (slf *auditHandler) getAll(c *gin.Context) {
+	query, ok := slf.parseQuery(c)
+	if !ok {
+		return
+	}
+
+	entries, total, err := slf.auditService.Find(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to retrieve audit entries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.AuditLogPage{Items: slf.auditMapper.ToAuditLogResponses(entries), Total: total})
+}
+
+// export streams the entries matching the filters as CSV, oldest first
+func (slf *auditHandler) export(c *gin.Context) {
+	query, ok := slf.parseQuery(c)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("audit-%s.csv", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	// The status is sent with the first rows, an error can only cut the file short
+	if err := slf.auditService.ExportCSV(query, c.Writer); err != nil {
+		slf.logger.Error().Err(err).Msg("Audit export interrupted")
+	}
+}
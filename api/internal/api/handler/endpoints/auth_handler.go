@@ -5,9 +5,15 @@ import (
 	"api/internal/api/handler/middleware"
 	"api/internal/api/handler/request"
 	"api/internal/api/handler/response"
+	"api/internal/api/models"
 	"api/internal/api/service"
+	"api/internal/oidc"
 	"api/pkg"
+	"errors"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/gin-contrib/graceful"
 	"github.com/gin-gonic/gin"
@@ -15,30 +21,53 @@ import (
 	"github.com/rs/zerolog"
 )
 
+// oidcStateCookie keeps the state of a single sign-on login until the provider redirects back
+const (
+	oidcStateCookie = "dos_oidc"
+	oidcStateTTL    = 10 * time.Minute
+)
+
 type authHandler struct {
-	userService *service.UserService
-	validator   *validator.Validate
-	logger      zerolog.Logger
-	config      api.AppConfig
+	userService    *service.UserService
+	oidcService    *service.OIDCService
+	ldapService    *service.LDAPService
+	sessionService *service.SessionService
+	loginGuard     *service.LoginGuardService
+	auditService   *service.AuditService
+	validator      *validator.Validate
+	logger         zerolog.Logger
+	config         api.AppConfig
 }
 
 func newAuthHandler() *authHandler {
 	return &authHandler{
-		userService: service.NewUserService(),
-		validator:   validator.New(),
-		logger:      api.Logger,
-		config:      api.GetConfig(),
+		userService:    service.NewUserService(),
+		oidcService:    service.NewOIDCService(),
+		ldapService:    service.NewLDAPService(),
+		sessionService: service.NewSessionService(),
+		loginGuard:     service.NewLoginGuardService(),
+		auditService:   service.NewAuditService(),
+		validator:      validator.New(),
+		logger:         api.Logger,
+		config:         api.GetConfig(),
 	}
 }
 
 func AuthHandler(router *graceful.Graceful) {
 	h := newAuthHandler()
 
+	rl := h.config.RateLimitConfig
+	registerLimit := middleware.RateLimit(h.config, "register", rl.RegisterPerIP, time.Duration(rl.RegisterWindow)*time.Second, middleware.ByIP)
+	loginLimit := middleware.RateLimit(h.config, "login", rl.LoginPerIP, time.Duration(rl.LoginWindow)*time.Second, middleware.ByIP)
+
 	auth := router.Group("/api/v1/auth")
 	{
-		auth.POST("/register", h.register)
-		auth.POST("/login", h.login)
+		auth.POST("/register", registerLimit, h.register)
+		auth.POST("/login", loginLimit, h.login)
 		auth.POST("/refresh", h.refreshToken)
+		auth.POST("/logout", h.logout)
+		auth.GET("/oidc/login", h.oidcLogin)
+		auth.GET("/oidc/callback", h.oidcCallback)
 	}
 
 	protected := router.Group("/api/v1")
@@ -52,7 +81,7 @@ func AuthHandler(router *graceful.Graceful) {
 	admin.Use(middleware.AuthMiddleware(h.config))
 	admin.Use(middleware.RequireRole("admin"))
 	{
-		// Add admin-only routes here
+		admin.POST("/ldap/sync", h.ldapSync)
 	}
 }
 
@@ -67,7 +96,7 @@ func (slf *authHandler) register(c *gin.Context) {
 	}
 
 	// Call service
-	authResponse, err := slf.userService.Register(registerDTO)
+	authResponse, err := slf.userService.Register(registerDTO, sessionClient(c))
 	if err != nil {
 		slf.logger.Error().Err(err).Msg("Error registering user")
 		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
@@ -86,17 +115,54 @@ func (slf *authHandler) login(c *gin.Context) {
 		return
 	}
 
+	actor := service.AuditActor{Email: loginDTO.Email, IP: c.ClientIP()}
+	if err := slf.loginGuard.Check(loginDTO.Email); err != nil {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			middleware.SetRetryAfter(c, throttled.RetryAfter)
+		}
+		slf.auditService.Record(actor, service.AuditEvent{
+			Action:  models.AuditLoginFailed,
+			Details: map[string]any{"reason": err.Error()},
+		})
+		c.JSON(http.StatusTooManyRequests, response.APIError{Message: err.Error()})
+		return
+	}
+
 	// Call service
-	authResponse, err := slf.userService.Login(loginDTO)
+	authResponse, err := slf.userService.Login(loginDTO, sessionClient(c))
 	if err != nil {
 		slf.logger.Error().Err(err).Msg("Error logging in user")
+		slf.auditService.Record(actor, service.AuditEvent{
+			Action:  models.AuditLoginFailed,
+			Details: map[string]any{"reason": err.Error()},
+		})
+		if slf.loginGuard.Failed(loginDTO.Email) {
+			slf.auditService.Record(actor, service.AuditEvent{
+				Action:  models.AuditAccountLocked,
+				Details: map[string]any{"minutes": slf.config.RateLimitConfig.LockoutDuration},
+			})
+		}
 		c.JSON(http.StatusUnauthorized, response.APIError{Message: err.Error()})
 		return
 	}
+	slf.loginGuard.Succeeded(loginDTO.Email)
+	slf.auditLogin(c, authResponse, "password")
 
 	c.JSON(http.StatusOK, authResponse)
 }
 
+// auditLogin records a successful login, method is "password" (local or LDAP) or "oidc"
+func (slf *authHandler) auditLogin(c *gin.Context, authResponse *response.AuthResponseDTO, method string) {
+	userID := authResponse.User.ID
+	slf.auditService.Record(service.AuditActor{UserID: &userID, Email: authResponse.User.Email, IP: c.ClientIP()}, service.AuditEvent{
+		Action:       models.AuditLogin,
+		ResourceType: models.AuditResourceUser,
+		ResourceID:   userID,
+		Details:      map[string]any{"method": method},
+	})
+}
+
 func (slf *authHandler) getMe(c *gin.Context) {
 	// Get user ID from context (set by auth middleware)
 	userID, exists := c.Get("userID")
@@ -143,7 +209,7 @@ func (slf *authHandler) refreshToken(c *gin.Context) {
 	}
 
 	// Call service
-	authResponse, err := slf.userService.RefreshToken(refreshDTO.RefreshToken)
+	authResponse, err := slf.userService.RefreshToken(refreshDTO.RefreshToken, sessionClient(c))
 	if err != nil {
 		slf.logger.Error().Err(err).Msg("Error refreshing token")
 		c.JSON(http.StatusUnauthorized, response.APIError{Message: err.Error()})
@@ -152,3 +218,127 @@ func (slf *authHandler) refreshToken(c *gin.Context) {
 
 	c.JSON(http.StatusOK, authResponse)
 }
+
+// logout ends the session of a refresh token, its access tokens are rejected from then on
+func (slf *authHandler) logout(c *gin.Context) {
+	var refreshDTO request.RefreshTokenDTO
+	if err := pkg.ParseAndValidate(c, &refreshDTO); err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
+		return
+	}
+
+	session, err := slf.sessionService.Logout(refreshDTO.RefreshToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, response.APIError{Message: err.Error()})
+		return
+	}
+	userID := session.UserID
+	slf.auditService.Record(service.AuditActor{UserID: &userID, IP: c.ClientIP()}, service.AuditEvent{
+		Action:       models.AuditLogout,
+		ResourceType: models.AuditResourceUser,
+		ResourceID:   userID,
+		Details:      map[string]any{"sessionId": session.ID},
+	})
+
+	c.Status(http.StatusNoContent)
+}
+
+// sessionClient describes the device of a request, for the session it opens or refreshes
+func sessionClient(c *gin.Context) service.SessionClient {
+	return service.SessionClient{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+// ldapSync updates the users linked to the LDAP directory and deactivates the removed ones
+func (slf *authHandler) ldapSync(c *gin.Context) {
+	if !slf.ldapService.Enabled() {
+		c.JSON(http.StatusNotFound, response.APIError{Message: "LDAP is not configured"})
+		return
+	}
+
+	result, err := slf.ldapService.SyncUsers()
+	if err != nil {
+		slf.logger.Error().Err(err).Msg("Error syncing LDAP users")
+		c.JSON(http.StatusBadGateway, response.APIError{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// oidcLogin redirects the browser to the single sign-on provider. The state, nonce and PKCE
+// verifier are kept in a signed cookie scoped to the callback.
+func (slf *authHandler) oidcLogin(c *gin.Context) {
+	if !slf.oidcService.Enabled() {
+		c.JSON(http.StatusNotFound, response.APIError{Message: service.ErrOIDCDisabled.Error()})
+		return
+	}
+
+	state, nonce, verifier := oidc.RandomString(), oidc.RandomString(), oidc.GenerateVerifier()
+	authURL, err := slf.oidcService.AuthCodeURL(c.Request.Context(), state, nonce, verifier)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, response.APIError{Message: "Single sign-on provider unavailable"})
+		return
+	}
+
+	cookie, err := pkg.GenerateOIDCStateToken(state, nonce, verifier, slf.config.JWTConfig.Secret, oidcStateTTL)
+	if err != nil {
+		slf.logger.Error().Err(err).Msg("Error generating OIDC state")
+		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to start single sign-on"})
+		return
+	}
+	slf.setOIDCStateCookie(c, cookie, int(oidcStateTTL.Seconds()))
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+// oidcCallback completes a single sign-on login. The tokens are passed to OIDC_FRONTEND_URL in
+// the URL fragment, or returned as JSON when it is not set.
+func (slf *authHandler) oidcCallback(c *gin.Context) {
+	if !slf.oidcService.Enabled() {
+		c.JSON(http.StatusNotFound, response.APIError{Message: service.ErrOIDCDisabled.Error()})
+		return
+	}
+	if providerErr := c.Query("error"); providerErr != "" {
+		slf.logger.Warn().Str("error", providerErr).Str("description", c.Query("error_description")).Msg("OIDC provider returned an error")
+		c.JSON(http.StatusUnauthorized, response.APIError{Message: "Single sign-on failed: " + providerErr})
+		return
+	}
+
+	raw, err := c.Cookie(oidcStateCookie)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: "Single sign-on session expired, please retry"})
+		return
+	}
+	slf.setOIDCStateCookie(c, "", -1)
+
+	state, err := pkg.ValidateOIDCStateToken(raw, slf.config.JWTConfig.Secret)
+	if err != nil || state.State != c.Query("state") {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: "Invalid single sign-on state"})
+		return
+	}
+
+	authResponse, err := slf.oidcService.Login(c.Request.Context(), c.Query("code"), state.Verifier, state.Nonce, sessionClient(c))
+	if err != nil {
+		slf.auditService.Record(service.AuditActor{IP: c.ClientIP()}, service.AuditEvent{
+			Action:  models.AuditLoginFailed,
+			Details: map[string]any{"method": "oidc", "reason": err.Error()},
+		})
+		c.JSON(http.StatusUnauthorized, response.APIError{Message: err.Error()})
+		return
+	}
+	slf.auditLogin(c, authResponse, "oidc")
+
+	if frontendURL := slf.config.OIDCConfig.FrontendURL; frontendURL != "" {
+		fragment := url.Values{"token": {authResponse.Token}, "refreshToken": {authResponse.RefreshToken}}
+		c.Redirect(http.StatusFound, frontendURL+"#"+fragment.Encode())
+		return
+	}
+	c.JSON(http.StatusOK, authResponse)
+}
+
+// setOIDCStateCookie sets the state cookie, Lax so it is sent on the redirect back from the provider
+func (slf *authHandler) setOIDCStateCookie(c *gin.Context, value string, maxAge int) {
+	secure := strings.HasPrefix(slf.config.OIDCConfig.RedirectURL, "https://")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcStateCookie, value, maxAge, "/api/v1/auth/oidc", "", secure, true)
+}
@@ -40,6 +40,7 @@ func DbNodeHandler(router *graceful.Graceful) {
 	routes.Use(middleware.AuthMiddleware(h.config))
 	{
 		routes.POST("/guess-schema", h.guessSchema)
+		routes.POST("/ddl-preview", h.previewTableDDL)
 	}
 }
 
@@ -63,17 +64,7 @@ func (slf *dbNodeHandler) guessSchema(c *gin.Context) {
 		slf.logger.Error().Err(err).Msg("Failed to find connection for guessing schema")
 	}
 
-	node.Connection = models.DBConnectionConfig{
-		Type:     conn.DbType,
-		Host:     conn.Host,
-		Port:     conn.Port,
-		Database: conn.DatabaseName,
-		Username: conn.User,
-		Password: conn.Password,
-		SSLMode:  conn.SSLMode,
-		Extra:    nil,
-		DSN:      "",
-	}
+	node.Connection = conn.ConnectionConfig()
 
 	// execute schema introspection
 	if err := node.FillDataModels(); err != nil {
@@ -93,3 +84,37 @@ func (slf *dbNodeHandler) guessSchema(c *gin.Context) {
 		DataModels: node.DataModels,
 	})
 }
+
+// previewTableDDL returns the CREATE TABLE or ALTER TABLE statements of a db_output target table
+func (slf *dbNodeHandler) previewTableDDL(c *gin.Context) {
+	var req request.TableDDLPreviewRequest
+	if err := pkg.ParseAndValidate(c, &req); err != nil {
+		slf.logger.Error().Err(err).Msg("Failed to parse DDL preview request")
+		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
+		return
+	}
+
+	conn, err := slf.metadataService.FindByID(req.ConnectionID)
+	if err != nil {
+		slf.logger.Error().Err(err).Msg("Failed to find connection for DDL preview")
+		c.JSON(http.StatusNotFound, response.APIError{Message: "Connection not found"})
+		return
+	}
+
+	config := models.DBOutputConfig{
+		Table:      req.Table,
+		DbSchema:   req.DbSchema,
+		DataModels: req.DataModels,
+		KeyColumns: req.KeyColumns,
+		Connection: conn.ConnectionConfig(),
+	}
+
+	preview, err := service.PreviewTableDDL(config)
+	if err != nil {
+		slf.logger.Error().Err(err).Msg("Failed to preview table DDL")
+		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to preview DDL: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, preview)
+}
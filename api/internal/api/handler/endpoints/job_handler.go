@@ -18,24 +18,29 @@ import (
 )
 
 type jobHandler struct {
-	jobService *service.JobService
-	jobMapper  mapper.JobMapper
-	config     api.AppConfig
-	logger     zerolog.Logger
+	jobService   *service.JobService
+	auditService *service.AuditService
+	jobMapper    mapper.JobMapper
+	config       api.AppConfig
+	logger       zerolog.Logger
 }
 
 func newJobHandler() *jobHandler {
 	return &jobHandler{
-		jobService: service.NewJobService(),
-		jobMapper:  mapper.NewJobMapper(),
-		config:     api.GetConfig(),
-		logger:     api.Logger,
+		jobService:   service.NewJobService(),
+		auditService: service.NewAuditService(),
+		jobMapper:    mapper.NewJobMapper(),
+		config:       api.GetConfig(),
+		logger:       api.Logger,
 	}
 }
 
 func JobHandler(router *graceful.Graceful) {
 	h := newJobHandler()
 
+	// Execute and resume share a budget
+	executeLimit := middleware.ExpensiveRateLimit(h.config, "job-execute")
+
 	routes := router.Group("/api/v1/jobs")
 	routes.Use(middleware.AuthMiddleware(h.config))
 	{
@@ -49,9 +54,11 @@ func JobHandler(router *graceful.Graceful) {
 		routes.POST("/:id/share", h.share)
 		routes.DELETE("/:id/share", h.unshare)
 
-		routes.POST("/:id/execute", h.execute)
+		routes.POST("/:id/execute", executeLimit, h.execute)
+		routes.POST("/:id/resume", executeLimit, h.resume)
 		routes.POST("/:id/print-code", h.printCode)
 		routes.POST("/:id/stop", h.stop)
+		routes.GET("/:id/runs", h.getRuns)
 
 		// Notification contacts
 		routes.POST("/:id/notification-contacts", h.addNotificationContact)
@@ -88,6 +95,20 @@ func (slf *jobHandler) checkAccess(c *gin.Context, jobID, userID uint, requiredR
 	return true
 }
 
+// auditShare records a change of the users a job is shared with
+func (slf *jobHandler) auditShare(c *gin.Context, action models.AuditAction, jobID uint, userIDs []uint, role models.OwningJob) {
+	details := map[string]any{"userIds": userIDs}
+	if role != "" {
+		details["role"] = role
+	}
+	slf.auditService.Record(auditActor(c), service.AuditEvent{
+		Action:       action,
+		ResourceType: models.AuditResourceJob,
+		ResourceID:   jobID,
+		Details:      details,
+	})
+}
+
 // getAll returns all jobs visible to the current user (optionally filtered by filePath)
 func (slf *jobHandler) getAll(c *gin.Context) {
 	userID, ok := pkg.GetUserID(c)
@@ -162,6 +183,12 @@ func (slf *jobHandler) create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
 		return
 	}
+	if req.RetryPolicy != nil {
+		if err := req.RetryPolicy.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
+			return
+		}
+	}
 
 	job := slf.jobMapper.CreateJob(req)
 	job.CreatorID = userID
@@ -173,12 +200,20 @@ func (slf *jobHandler) create(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to create job"})
 		return
 	}
+	slf.auditService.Record(auditActor(c), service.AuditEvent{
+		Action:       models.AuditJobCreate,
+		ResourceType: models.AuditResourceJob,
+		ResourceID:   created.ID,
+		After:        mapper.ToJobResponseWithNodes(*created, nil),
+	})
 
 	// Share with specified users if any
 	if len(req.SharedWith) > 0 {
 		if err := slf.jobService.ShareJob(created.ID, req.SharedWith, models.Viewer); err != nil {
 			slf.logger.Error().Err(err).Msg("Failed to share job with users")
 			// Don't fail the create, just log the error
+		} else {
+			slf.auditShare(c, models.AuditJobShare, created.ID, req.SharedWith, models.Viewer)
 		}
 	}
 
@@ -217,10 +252,22 @@ func (slf *jobHandler) update(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
 		return
 	}
+	if req.RetryPolicy != nil {
+		if err := req.RetryPolicy.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
+			return
+		}
+	}
 
 	patch := slf.jobMapper.PatchJob(req)
 	nodes := mapper.JobWithNodeToModel(req)
 
+	before, err := slf.jobService.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIError{Message: "Job not found"})
+		return
+	}
+
 	var updated *models.Job
 	if len(nodes) > 0 {
 		// Update with nodes replacement
@@ -235,6 +282,13 @@ func (slf *jobHandler) update(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to update job"})
 		return
 	}
+	slf.auditService.Record(auditActor(c), service.AuditEvent{
+		Action:       models.AuditJobUpdate,
+		ResourceType: models.AuditResourceJob,
+		ResourceID:   uint(id),
+		Before:       mapper.ToJobResponseWithNodes(*before, nil),
+		After:        mapper.ToJobResponseWithNodes(*updated, nil),
+	})
 
 	// Update sharing if specified (only owner can change sharing)
 	if req.SharedWith != nil {
@@ -242,6 +296,13 @@ func (slf *jobHandler) update(c *gin.Context) {
 		if canAccess && role == models.Owner {
 			if err := slf.jobService.UpdateJobSharing(uint(id), req.SharedWith, models.Viewer); err != nil {
 				slf.logger.Error().Err(err).Msg("Failed to update job sharing")
+			} else {
+				slf.auditService.Record(auditActor(c), service.AuditEvent{
+					Action:       models.AuditJobShare,
+					ResourceType: models.AuditResourceJob,
+					ResourceID:   uint(id),
+					Details:      map[string]any{"userIds": req.SharedWith, "role": models.Viewer, "replace": true},
+				})
 			}
 		}
 	}
@@ -274,11 +335,23 @@ func (slf *jobHandler) delete(c *gin.Context) {
 		return
 	}
 
+	before, accessList, err := slf.jobService.FindByIDWithAccess(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIError{Message: "Job not found"})
+		return
+	}
+
 	if err := slf.jobService.Delete(uint(id)); err != nil {
 		slf.logger.Error().Err(err).Uint64("id", id).Msg("Failed to delete job")
 		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to delete job"})
 		return
 	}
+	slf.auditService.Record(auditActor(c), service.AuditEvent{
+		Action:       models.AuditJobDelete,
+		ResourceType: models.AuditResourceJob,
+		ResourceID:   uint(id),
+		Before:       mapper.ToJobResponseWithNodes(*before, accessList),
+	})
 
 	c.JSON(http.StatusOK, gin.H{"id": id, "deleted": true})
 }
@@ -313,6 +386,7 @@ func (slf *jobHandler) share(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to share job"})
 		return
 	}
+	slf.auditShare(c, models.AuditJobShare, uint(id), req.UserIDs, req.Role)
 
 	// Return updated job with access list
 	job, accessList, err := slf.jobService.FindByIDWithAccess(uint(id))
@@ -354,6 +428,7 @@ func (slf *jobHandler) unshare(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to unshare job"})
 		return
 	}
+	slf.auditShare(c, models.AuditJobUnshare, uint(id), req.UserIDs, "")
 
 	// Return updated job with access list
 	job, accessList, err := slf.jobService.FindByIDWithAccess(uint(id))
@@ -366,12 +441,23 @@ func (slf *jobHandler) unshare(c *gin.Context) {
 }
 
 func (slf *jobHandler) execute(ctx *gin.Context) {
+	userID, ok := pkg.GetUserID(ctx)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, response.APIError{Message: "Invalid ID"})
 		return
 	}
 
+	if !slf.checkAccess(ctx, uint(id), userID, models.Editor) {
+		return
+	}
+
+	slf.auditService.Record(auditActor(ctx), service.AuditEvent{Action: models.AuditJobExecute, ResourceType: models.AuditResourceJob, ResourceID: uint(id)})
+
 	go func() {
 		if err := slf.jobService.Execute(uint(id)); err != nil {
 			slf.logger.Error().Err(err).Uint64("id", id).Msg("Job execution failed")
@@ -381,22 +467,91 @@ func (slf *jobHandler) execute(ctx *gin.Context) {
 	ctx.JSON(http.StatusAccepted, gin.H{"message": "Job execution started", "jobId": id})
 }
 
+func (slf *jobHandler) resume(ctx *gin.Context) {
+	userID, ok := pkg.GetUserID(ctx)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, response.APIError{Message: "Invalid ID"})
+		return
+	}
+
+	if !slf.checkAccess(ctx, uint(id), userID, models.Editor) {
+		return
+	}
+
+	slf.auditService.Record(auditActor(ctx), service.AuditEvent{Action: models.AuditJobResume, ResourceType: models.AuditResourceJob, ResourceID: uint(id)})
+
+	go func() {
+		if err := slf.jobService.Resume(uint(id)); err != nil {
+			slf.logger.Error().Err(err).Uint64("id", id).Msg("Job resume failed")
+		}
+	}()
+
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "Job resume started", "jobId": id})
+}
+
 func (slf *jobHandler) stop(ctx *gin.Context) {
+	userID, ok := pkg.GetUserID(ctx)
+	if !ok {
+		return
+	}
+
 	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, response.APIError{Message: "Invalid ID"})
 		return
 	}
 
+	if !slf.checkAccess(ctx, uint(id), userID, models.Editor) {
+		return
+	}
+
 	if err := slf.jobService.Stop(uint(id)); err != nil {
 		slf.logger.Error().Err(err).Uint64("id", id).Msg("Failed to stop job")
 		ctx.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to stop job"})
 		return
 	}
+	slf.auditService.Record(auditActor(ctx), service.AuditEvent{Action: models.AuditJobStop, ResourceType: models.AuditResourceJob, ResourceID: uint(id)})
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "Job stopped", "jobId": id})
 }
 
+// getRuns returns the most recent run attempts of a job
+func (slf *jobHandler) getRuns(c *gin.Context) {
+	userID, ok := pkg.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: "Invalid ID"})
+		return
+	}
+
+	if !slf.checkAccess(c, uint(id), userID, models.Viewer) {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+
+	runs, err := slf.jobService.FindRuns(uint(id), limit)
+	if err != nil {
+		slf.logger.Error().Err(err).Uint64("id", id).Msg("Failed to get job runs")
+		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to retrieve job runs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, slf.jobMapper.ToJobRunResponses(runs))
+}
+
 func (slf *jobHandler) addNotificationContact(c *gin.Context) {
 	userID, ok := pkg.GetUserID(c)
 	if !ok {
@@ -19,6 +19,7 @@ import (
 
 type dbMetadataHandler struct {
 	metadataService *service.MetadataService
+	auditService    *service.AuditService
 	logger          zerolog.Logger
 	config          api.AppConfig
 	metadataMapper  mapper.MetadataMapper
@@ -26,6 +27,7 @@ type dbMetadataHandler struct {
 
 type sftpMetadataHandler struct {
 	sftpService    *service.SftpMetadataService
+	auditService   *service.AuditService
 	logger         zerolog.Logger
 	config         api.AppConfig
 	metadataMapper mapper.MetadataMapper
@@ -34,6 +36,7 @@ type sftpMetadataHandler struct {
 type emailMetadataHandler struct {
 	emailService   *service.EmailMetadataService
 	mailService    *service.MailService
+	auditService   *service.AuditService
 	logger         zerolog.Logger
 	config         api.AppConfig
 	metadataMapper mapper.MetadataMapper
@@ -42,6 +45,7 @@ type emailMetadataHandler struct {
 func newDbMetadataHandler() *dbMetadataHandler {
 	return &dbMetadataHandler{
 		metadataService: service.NewMetadataService(),
+		auditService:    service.NewAuditService(),
 		logger:          api.Logger,
 		config:          api.GetConfig(),
 		metadataMapper:  mapper.NewMetadataMapper(),
@@ -51,6 +55,7 @@ func newDbMetadataHandler() *dbMetadataHandler {
 func newSftpMetadataHandler() *sftpMetadataHandler {
 	return &sftpMetadataHandler{
 		sftpService:    service.NewSftpMetadataService(),
+		auditService:   service.NewAuditService(),
 		logger:         api.Logger,
 		config:         api.GetConfig(),
 		metadataMapper: mapper.NewMetadataMapper(),
@@ -61,6 +66,7 @@ func newEmailMetadataHandler() *emailMetadataHandler {
 	return &emailMetadataHandler{
 		emailService:   service.NewEmailMetadataService(),
 		mailService:    service.NewMailService(),
+		auditService:   service.NewAuditService(),
 		logger:         api.Logger,
 		config:         api.GetConfig(),
 		metadataMapper: mapper.NewMetadataMapper(),
@@ -153,7 +159,14 @@ func (slf *dbMetadataHandler) create(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, slf.metadataMapper.ToMetadataResponse(*created))
+	result := slf.metadataMapper.ToMetadataResponse(*created)
+	slf.auditService.Record(auditActor(c), service.AuditEvent{
+		Action:       models.AuditMetadataCreate,
+		ResourceType: models.AuditResourceMetadataDB,
+		ResourceID:   created.ID,
+		After:        result,
+	})
+	c.JSON(http.StatusCreated, result)
 }
 
 // update updates an existing database metadata entry
@@ -171,6 +184,12 @@ func (slf *dbMetadataHandler) update(c *gin.Context) {
 		return
 	}
 
+	before, err := slf.metadataService.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIError{Message: "Metadata not found"})
+		return
+	}
+
 	patch := slf.metadataMapper.PatchDbMetadata(req)
 	updated, err := slf.metadataService.Update(uint(id), patch)
 	if err != nil {
@@ -179,7 +198,15 @@ func (slf *dbMetadataHandler) update(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, slf.metadataMapper.ToMetadataResponse(*updated))
+	result := slf.metadataMapper.ToMetadataResponse(*updated)
+	slf.auditService.Record(auditActor(c), service.AuditEvent{
+		Action:       models.AuditMetadataUpdate,
+		ResourceType: models.AuditResourceMetadataDB,
+		ResourceID:   uint(id),
+		Before:       slf.metadataMapper.ToMetadataResponse(*before),
+		After:        result,
+	})
+	c.JSON(http.StatusOK, result)
 }
 
 // delete removes a database metadata entry
@@ -190,11 +217,23 @@ func (slf *dbMetadataHandler) delete(c *gin.Context) {
 		return
 	}
 
+	before, err := slf.metadataService.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIError{Message: "Metadata not found"})
+		return
+	}
+
 	if err := slf.metadataService.Delete(uint(id)); err != nil {
 		slf.logger.Error().Err(err).Uint64("id", id).Msg("Failed to delete db metadata")
 		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to delete metadata"})
 		return
 	}
+	slf.auditService.Record(auditActor(c), service.AuditEvent{
+		Action:       models.AuditMetadataDelete,
+		ResourceType: models.AuditResourceMetadataDB,
+		ResourceID:   uint(id),
+		Before:       slf.metadataMapper.ToMetadataResponse(*before),
+	})
 
 	c.JSON(http.StatusOK, gin.H{"id": id, "deleted": true})
 }
@@ -216,6 +255,8 @@ func (slf *dbMetadataHandler) testConnection(c *gin.Context) {
 		Username: req.User,
 		Password: req.Password,
 		SSLMode:  req.SSLMode,
+		Pool:     req.Pool,
+		TLS:      req.TLS,
 	}
 
 	result := service.TestDatabaseConnection(cfg)
@@ -269,7 +310,14 @@ func (slf *sftpMetadataHandler) create(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, slf.metadataMapper.ToSftpMetadataResponse(*created))
+	result := slf.metadataMapper.ToSftpMetadataResponse(*created)
+	slf.auditService.Record(auditActor(c), service.AuditEvent{
+		Action:       models.AuditMetadataCreate,
+		ResourceType: models.AuditResourceMetadataSftp,
+		ResourceID:   created.ID,
+		After:        result,
+	})
+	c.JSON(http.StatusCreated, result)
 }
 
 // update updates an existing SFTP metadata entry
@@ -287,6 +335,12 @@ func (slf *sftpMetadataHandler) update(c *gin.Context) {
 		return
 	}
 
+	before, err := slf.sftpService.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIError{Message: "Metadata not found"})
+		return
+	}
+
 	patch := slf.metadataMapper.PatchSftpMetadata(req)
 	updated, err := slf.sftpService.Update(uint(id), patch)
 	if err != nil {
@@ -295,7 +349,15 @@ func (slf *sftpMetadataHandler) update(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, slf.metadataMapper.ToSftpMetadataResponse(*updated))
+	result := slf.metadataMapper.ToSftpMetadataResponse(*updated)
+	slf.auditService.Record(auditActor(c), service.AuditEvent{
+		Action:       models.AuditMetadataUpdate,
+		ResourceType: models.AuditResourceMetadataSftp,
+		ResourceID:   uint(id),
+		Before:       slf.metadataMapper.ToSftpMetadataResponse(*before),
+		After:        result,
+	})
+	c.JSON(http.StatusOK, result)
 }
 
 // delete removes a SFTP metadata entry
@@ -306,11 +368,23 @@ func (slf *sftpMetadataHandler) delete(c *gin.Context) {
 		return
 	}
 
+	before, err := slf.sftpService.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIError{Message: "Metadata not found"})
+		return
+	}
+
 	if err := slf.sftpService.Delete(uint(id)); err != nil {
 		slf.logger.Error().Err(err).Uint64("id", id).Msg("Failed to delete sftp metadata")
 		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to delete metadata"})
 		return
 	}
+	slf.auditService.Record(auditActor(c), service.AuditEvent{
+		Action:       models.AuditMetadataDelete,
+		ResourceType: models.AuditResourceMetadataSftp,
+		ResourceID:   uint(id),
+		Before:       slf.metadataMapper.ToSftpMetadataResponse(*before),
+	})
 
 	c.JSON(http.StatusOK, gin.H{"id": id, "deleted": true})
 }
@@ -361,7 +435,14 @@ func (slf *emailMetadataHandler) create(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, slf.metadataMapper.ToEmailMetadataResponse(*created))
+	result := slf.metadataMapper.ToEmailMetadataResponse(*created)
+	slf.auditService.Record(auditActor(c), service.AuditEvent{
+		Action:       models.AuditMetadataCreate,
+		ResourceType: models.AuditResourceMetadataEmail,
+		ResourceID:   created.ID,
+		After:        result,
+	})
+	c.JSON(http.StatusCreated, result)
 }
 
 func (slf *emailMetadataHandler) update(c *gin.Context) {
@@ -378,6 +459,12 @@ func (slf *emailMetadataHandler) update(c *gin.Context) {
 		return
 	}
 
+	before, err := slf.emailService.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIError{Message: "Metadata not found"})
+		return
+	}
+
 	patch := slf.metadataMapper.PatchEmailMetadata(req)
 	updated, err := slf.emailService.Update(uint(id), patch)
 	if err != nil {
@@ -386,7 +473,15 @@ func (slf *emailMetadataHandler) update(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, slf.metadataMapper.ToEmailMetadataResponse(*updated))
+	result := slf.metadataMapper.ToEmailMetadataResponse(*updated)
+	slf.auditService.Record(auditActor(c), service.AuditEvent{
+		Action:       models.AuditMetadataUpdate,
+		ResourceType: models.AuditResourceMetadataEmail,
+		ResourceID:   uint(id),
+		Before:       slf.metadataMapper.ToEmailMetadataResponse(*before),
+		After:        result,
+	})
+	c.JSON(http.StatusOK, result)
 }
 
 func (slf *emailMetadataHandler) delete(c *gin.Context) {
@@ -396,11 +491,23 @@ func (slf *emailMetadataHandler) delete(c *gin.Context) {
 		return
 	}
 
+	before, err := slf.emailService.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIError{Message: "Metadata not found"})
+		return
+	}
+
 	if err := slf.emailService.Delete(uint(id)); err != nil {
 		slf.logger.Error().Err(err).Uint64("id", id).Msg("Failed to delete email metadata")
 		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to delete metadata"})
 		return
 	}
+	slf.auditService.Record(auditActor(c), service.AuditEvent{
+		Action:       models.AuditMetadataDelete,
+		ResourceType: models.AuditResourceMetadataEmail,
+		ResourceID:   uint(id),
+		Before:       slf.metadataMapper.ToEmailMetadataResponse(*before),
+	})
 
 	c.JSON(http.StatusOK, gin.H{"id": id, "deleted": true})
 }
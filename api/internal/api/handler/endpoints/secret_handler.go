@@ -0,0 +1,51 @@
+package endpoints
+
+import (
+	"api"
+	"api/internal/api/handler/middleware"
+	"api/internal/api/handler/response"
+	"api/internal/api/service"
+	"api/internal/secret"
+	"net/http"
+
+	"github.com/gin-contrib/graceful"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type secretHandler struct {
+	logger        zerolog.Logger
+	config        api.AppConfig
+	secretService *service.SecretService
+}
+
+func newSecretHandler() *secretHandler {
+	return &secretHandler{
+		logger:        api.Logger,
+		config:        api.GetConfig(),
+		secretService: service.NewSecretService(),
+	}
+}
+
+func SecretHandler(router *graceful.Graceful) {
+	h := newSecretHandler()
+
+	admin := router.Group("/api/v1/admin/secrets")
+	admin.Use(middleware.AuthMiddleware(h.config))
+	admin.Use(middleware.RequireRole("admin"))
+	{
+		admin.POST("/rotate", h.rotate)
+	}
+}
+
+// rotate re-encrypts the stored credentials with the active master key
+func (slf *secretHandler) rotate(c *gin.Context) {
+	rotated, err := slf.secretService.RotateKeys()
+	if err != nil {
+		slf.logger.Error().Err(err).Msg("Failed to rotate credentials")
+		c.JSON(http.StatusInternalServerError, response.APIError{Message: err.Error(), Data: rotated})
+		return
+	}
+
+	c.JSON(http.StatusOK, response.SecretRotation{KeyID: secret.Current().ActiveKeyID(), Rotated: rotated})
+}
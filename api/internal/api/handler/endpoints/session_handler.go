@@ -0,0 +1,125 @@
+package endpoints
+
+import (
+	"api"
+	"api/internal/api/handler/middleware"
+	"api/internal/api/handler/response"
+	"api/internal/api/models"
+	"api/internal/api/service"
+	"api/pkg"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-contrib/graceful"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+type sessionHandler struct {
+	logger         zerolog.Logger
+	config         api.AppConfig
+	sessionService *service.SessionService
+	userService    *service.UserService
+	auditService   *service.AuditService
+}
+
+func newSessionHandler() *sessionHandler {
+	return &sessionHandler{
+		logger:         api.Logger,
+		config:         api.GetConfig(),
+		sessionService: service.NewSessionService(),
+		userService:    service.NewUserService(),
+		auditService:   service.NewAuditService(),
+	}
+}
+
+// SessionHandler sets up the routes listing and revoking the sessions of the current user, and
+// the admin force logout
+func SessionHandler(router *graceful.Graceful) {
+	h := newSessionHandler()
+
+	sessions := router.Group("/api/v1/sessions")
+	sessions.Use(middleware.AuthMiddleware(h.config))
+	{
+		sessions.GET("", h.getAll)
+		sessions.DELETE("/:id", h.revoke)
+	}
+
+	admin := router.Group("/api/v1/admin/users")
+	admin.Use(middleware.AuthMiddleware(h.config))
+	admin.Use(middleware.RequireRole("admin"))
+	{
+		admin.POST("/:id/logout", h.forceLogout)
+	}
+}
+
+// getAll lists the active sessions of the current user, the one of the request is flagged current
+func (slf *sessionHandler) getAll(c *gin.Context) {
+	userID, ok := pkg.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := slf.sessionService.FindForUser(userID, c.GetUint("sessionID"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to list sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// revoke revokes a session of the current user, logging that device out
+func (slf *sessionHandler) revoke(c *gin.Context) {
+	userID, ok := pkg.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: "Invalid ID"})
+		return
+	}
+
+	if err := slf.sessionService.Revoke(userID, uint(id), models.SessionRevoked); err != nil {
+		c.JSON(http.StatusNotFound, response.APIError{Message: err.Error()})
+		return
+	}
+	slf.auditService.Record(auditActor(c), service.AuditEvent{
+		Action:       models.AuditSessionRevoke,
+		ResourceType: models.AuditResourceUser,
+		ResourceID:   userID,
+		Details:      map[string]any{"sessionId": id},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "revoked": true})
+}
+
+// forceLogout revokes every session of a user
+func (slf *sessionHandler) forceLogout(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: "Invalid ID"})
+		return
+	}
+
+	if _, err := slf.userService.GetByID(uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, response.APIError{Message: err.Error()})
+		return
+	}
+
+	count, err := slf.sessionService.RevokeAll(uint(id), models.SessionForceLogout)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to revoke sessions"})
+		return
+	}
+	slf.auditService.Record(auditActor(c), service.AuditEvent{
+		Action:       models.AuditForceLogout,
+		ResourceType: models.AuditResourceUser,
+		ResourceID:   uint(id),
+		Details:      map[string]any{"sessions": count},
+	})
+
+	c.JSON(http.StatusOK, gin.H{"id": id, "revokedSessions": count})
+}
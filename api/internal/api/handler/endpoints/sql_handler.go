@@ -34,8 +34,8 @@ func SqlHandler(router *graceful.Graceful) {
 	routes := router.Group("/api/v1/sql")
 	routes.Use(middleware.AuthMiddleware(h.config))
 	{
-		routes.POST("/guess-query", h.guessQuery)
-		routes.POST("/optimize-query", h.optimizeQuery)
+		routes.POST("/guess-query", middleware.ExpensiveRateLimit(h.config, "guess-query"), h.guessQuery)
+		routes.POST("/optimize-query", middleware.ExpensiveRateLimit(h.config, "optimize-query"), h.optimizeQuery)
 		routes.POST("/introspect/test-connection", h.testConnection)
 		routes.POST("/introspect/tables", h.getTables)
 		routes.POST("/introspect/columns", h.getColumns)
@@ -9,6 +9,7 @@ import (
 	"api/internal/api/models"
 	"api/internal/api/service"
 	"api/pkg"
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -19,6 +20,7 @@ import (
 
 type triggerHandler struct {
 	triggerService *service.TriggerService
+	auditService   *service.AuditService
 	triggerMapper  mapper.TriggerMapper
 	config         api.AppConfig
 	logger         zerolog.Logger
@@ -27,6 +29,7 @@ type triggerHandler struct {
 func newTriggerHandler() *triggerHandler {
 	return &triggerHandler{
 		triggerService: service.NewTriggerService(),
+		auditService:   service.NewAuditService(),
 		triggerMapper:  mapper.NewTriggerMapper(),
 		config:         api.GetConfig(),
 		logger:         api.Logger,
@@ -57,6 +60,7 @@ func TriggerHandler(router *graceful.Graceful) {
 
 		// Job linking operations
 		routes.POST("/:id/jobs", h.linkJob)
+		routes.PUT("/:id/jobs/:jobId", h.updateJobLink)
 		routes.DELETE("/:id/jobs/:jobId", h.unlinkJob)
 
 		// Execution history
@@ -81,6 +85,18 @@ func (slf *triggerHandler) checkAccess(c *gin.Context, triggerID, userID uint) b
 	return true
 }
 
+// audit records a change of a trigger
+func (slf *triggerHandler) audit(c *gin.Context, action models.AuditAction, triggerID uint, before, after any, details map[string]any) {
+	slf.auditService.Record(auditActor(c), service.AuditEvent{
+		Action:       action,
+		ResourceType: models.AuditResourceTrigger,
+		ResourceID:   triggerID,
+		Before:       before,
+		After:        after,
+		Details:      details,
+	})
+}
+
 // getAll returns all triggers for the current user
 func (slf *triggerHandler) getAll(c *gin.Context) {
 	userID, ok := pkg.GetUserID(c)
@@ -147,7 +163,9 @@ func (slf *triggerHandler) create(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, slf.triggerMapper.ToTriggerWithDetails(*created))
+	result := slf.triggerMapper.ToTriggerWithDetails(*created)
+	slf.audit(c, models.AuditTriggerCreate, created.ID, nil, result, nil)
+	c.JSON(http.StatusCreated, result)
 }
 
 // update updates an existing trigger
@@ -174,6 +192,12 @@ func (slf *triggerHandler) update(c *gin.Context) {
 		return
 	}
 
+	before, err := slf.triggerService.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIError{Message: "Trigger not found"})
+		return
+	}
+
 	// Handle config update separately if provided
 	if req.Config != nil {
 		updated, err := slf.triggerService.UpdateConfig(uint(id), *req.Config)
@@ -182,7 +206,9 @@ func (slf *triggerHandler) update(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
 			return
 		}
-		c.JSON(http.StatusOK, slf.triggerMapper.ToTriggerWithDetails(*updated))
+		result := slf.triggerMapper.ToTriggerWithDetails(*updated)
+		slf.audit(c, models.AuditTriggerUpdate, uint(id), slf.triggerMapper.ToTriggerWithDetails(*before), result, nil)
+		c.JSON(http.StatusOK, result)
 		return
 	}
 
@@ -194,7 +220,9 @@ func (slf *triggerHandler) update(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, slf.triggerMapper.ToTriggerWithDetails(*updated))
+	result := slf.triggerMapper.ToTriggerWithDetails(*updated)
+	slf.audit(c, models.AuditTriggerUpdate, uint(id), slf.triggerMapper.ToTriggerWithDetails(*before), result, nil)
+	c.JSON(http.StatusOK, result)
 }
 
 // delete removes a trigger
@@ -214,11 +242,18 @@ func (slf *triggerHandler) delete(c *gin.Context) {
 		return
 	}
 
+	before, err := slf.triggerService.FindByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, response.APIError{Message: "Trigger not found"})
+		return
+	}
+
 	if err := slf.triggerService.Delete(uint(id)); err != nil {
 		slf.logger.Error().Err(err).Uint64("id", id).Msg("Failed to delete trigger")
 		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to delete trigger"})
 		return
 	}
+	slf.audit(c, models.AuditTriggerDelete, uint(id), slf.triggerMapper.ToTriggerWithDetails(*before), nil, nil)
 
 	c.JSON(http.StatusOK, gin.H{"id": id, "deleted": true})
 }
@@ -246,6 +281,7 @@ func (slf *triggerHandler) activate(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
 		return
 	}
+	slf.audit(c, models.AuditTriggerActivate, uint(id), nil, nil, nil)
 
 	c.JSON(http.StatusOK, slf.triggerMapper.ToTriggerWithDetails(*trigger))
 }
@@ -273,6 +309,7 @@ func (slf *triggerHandler) pause(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to pause trigger"})
 		return
 	}
+	slf.audit(c, models.AuditTriggerPause, uint(id), nil, nil, nil)
 
 	c.JSON(http.StatusOK, slf.triggerMapper.ToTriggerWithDetails(*trigger))
 }
@@ -314,7 +351,9 @@ func (slf *triggerHandler) addRule(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, slf.triggerMapper.ToTriggerRuleResponse(*created))
+	result := slf.triggerMapper.ToTriggerRuleResponse(*created)
+	slf.audit(c, models.AuditTriggerRuleAdd, uint(id), nil, result, nil)
+	c.JSON(http.StatusCreated, result)
 }
 
 // updateRule updates an existing rule
@@ -362,7 +401,9 @@ func (slf *triggerHandler) updateRule(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, slf.triggerMapper.ToTriggerRuleResponse(*updated))
+	result := slf.triggerMapper.ToTriggerRuleResponse(*updated)
+	slf.audit(c, models.AuditTriggerRuleUpdate, uint(id), nil, result, nil)
+	c.JSON(http.StatusOK, result)
 }
 
 // deleteRule removes a rule from a trigger
@@ -393,6 +434,7 @@ func (slf *triggerHandler) deleteRule(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to delete rule"})
 		return
 	}
+	slf.audit(c, models.AuditTriggerRuleDelete, uint(id), nil, nil, map[string]any{"ruleId": ruleID})
 
 	c.JSON(http.StatusOK, gin.H{"id": ruleID, "deleted": true})
 }
@@ -420,22 +462,97 @@ func (slf *triggerHandler) linkJob(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
 		return
 	}
+	if req.RetryPolicy != nil {
+		if err := req.RetryPolicy.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
+			return
+		}
+	}
 
-	link, err := slf.triggerService.LinkJob(uint(id), req.JobID, req.Priority, req.PassEventData)
+	link, err := slf.triggerService.LinkJob(uint(id), req.JobID, req.Priority, req.PassEventData, req.RetryPolicy)
 	if err != nil {
 		slf.logger.Error().Err(err).Uint64("id", id).Msg("Failed to link job")
 		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
 		return
 	}
+	slf.audit(c, models.AuditTriggerLinkJob, uint(id), nil, nil, map[string]any{
+		"jobId":         link.JobID,
+		"priority":      link.Priority,
+		"passEventData": link.PassEventData,
+		"retryPolicy":   link.RetryPolicy,
+	})
+
+	c.JSON(http.StatusCreated, jobLinkResponse(link))
+}
+
+// updateJobLink updates the priority, state, event data and retry policy of a trigger-job link
+func (slf *triggerHandler) updateJobLink(c *gin.Context) {
+	userID, ok := pkg.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: "Invalid trigger ID"})
+		return
+	}
+
+	jobID, err := strconv.ParseUint(c.Param("jobId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, response.APIError{Message: "Invalid job ID"})
+		return
+	}
+
+	if !slf.checkAccess(c, uint(id), userID) {
+		return
+	}
+
+	var req request.UpdateJobLink
+	if err := pkg.ParseAndValidate(c, &req); err != nil {
+		slf.logger.Error().Err(err).Msg("Failed to parse update job link request")
+		c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
+		return
+	}
+	if req.RetryPolicy != nil {
+		if err := req.RetryPolicy.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, response.APIError{Message: err.Error()})
+			return
+		}
+	}
+
+	link, err := slf.triggerService.UpdateJobLink(uint(id), uint(jobID), req)
+	if err != nil {
+		if errors.Is(err, service.ErrJobLinkNotFound) {
+			c.JSON(http.StatusNotFound, response.APIError{Message: err.Error()})
+			return
+		}
+		slf.logger.Error().Err(err).Uint64("id", id).Uint64("jobId", jobID).Msg("Failed to update job link")
+		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to update job link"})
+		return
+	}
+	slf.audit(c, models.AuditTriggerUpdateJob, uint(id), nil, nil, map[string]any{
+		"jobId":         link.JobID,
+		"priority":      link.Priority,
+		"active":        link.Active,
+		"passEventData": link.PassEventData,
+		"retryPolicy":   link.RetryPolicy,
+	})
 
-	c.JSON(http.StatusCreated, gin.H{
+	c.JSON(http.StatusOK, jobLinkResponse(link))
+}
+
+// jobLinkResponse is the response of the link operations, the link is not loaded with its job
+func jobLinkResponse(link *models.TriggerJob) gin.H {
+	return gin.H{
 		"id":            link.ID,
 		"triggerId":     link.TriggerID,
 		"jobId":         link.JobID,
 		"priority":      link.Priority,
 		"active":        link.Active,
 		"passEventData": link.PassEventData,
-	})
+		"retryPolicy":   link.RetryPolicy,
+	}
 }
 
 // unlinkJob removes a job from a trigger
@@ -466,6 +583,7 @@ func (slf *triggerHandler) unlinkJob(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, response.APIError{Message: "Failed to unlink job"})
 		return
 	}
+	slf.audit(c, models.AuditTriggerUnlinkJob, uint(id), nil, nil, map[string]any{"jobId": jobID})
 
 	c.JSON(http.StatusOK, gin.H{"triggerId": id, "jobId": jobID, "unlinked": true})
 }
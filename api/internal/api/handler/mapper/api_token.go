@@ -0,0 +1,43 @@
+package mapper
+
+import (
+	"api/internal/api/handler/response"
+	"api/internal/api/models"
+)
+
+// ApiTokenMapper maps personal access tokens to DTOs
+type ApiTokenMapper interface {
+	ToApiTokenResponse(t models.ApiToken) response.ApiToken
+	ToApiTokenResponses(tokens []models.ApiToken) []response.ApiToken
+}
+
+// ApiTokenMapperImpl implements ApiTokenMapper
+type ApiTokenMapperImpl struct{}
+
+// NewApiTokenMapper creates a new ApiTokenMapper instance
+func NewApiTokenMapper() ApiTokenMapper {
+	return &ApiTokenMapperImpl{}
+}
+
+// ToApiTokenResponse maps a token to its response, the hash is never returned
+func (m *ApiTokenMapperImpl) ToApiTokenResponse(t models.ApiToken) response.ApiToken {
+	return response.ApiToken{
+		ID:         t.ID,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Scopes:     t.Scopes,
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		RevokedAt:  t.RevokedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+// ToApiTokenResponses maps a list of tokens
+func (m *ApiTokenMapperImpl) ToApiTokenResponses(tokens []models.ApiToken) []response.ApiToken {
+	result := make([]response.ApiToken, len(tokens))
+	for i, t := range tokens {
+		result[i] = m.ToApiTokenResponse(t)
+	}
+	return result
+}
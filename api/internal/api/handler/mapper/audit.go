@@ -0,0 +1,55 @@
+package mapper
+
+import (
+	"api/internal/api/handler/response"
+	"api/internal/api/models"
+	"encoding/json"
+)
+
+// AuditMapper maps audit entries to DTOs
+type AuditMapper interface {
+	ToAuditLogResponse(e models.AuditLog) response.AuditLog
+	ToAuditLogResponses(entries []models.AuditLog) []response.AuditLog
+}
+
+// AuditMapperImpl implements AuditMapper
+type AuditMapperImpl struct{}
+
+// NewAuditMapper creates a new AuditMapper instance
+func NewAuditMapper() AuditMapper {
+	return &AuditMapperImpl{}
+}
+
+// ToAuditLogResponse maps an entry to its response, the JSON columns are passed through
+func (m *AuditMapperImpl) ToAuditLogResponse(e models.AuditLog) response.AuditLog {
+	return response.AuditLog{
+		ID:           e.ID,
+		CreatedAt:    e.CreatedAt,
+		UserID:       e.UserID,
+		UserEmail:    e.UserEmail,
+		ApiTokenID:   e.ApiTokenID,
+		IP:           e.IP,
+		Action:       e.Action,
+		ResourceType: e.ResourceType,
+		ResourceID:   e.ResourceID,
+		Before:       rawJSON(e.Before),
+		After:        rawJSON(e.After),
+		Details:      rawJSON(e.Details),
+	}
+}
+
+// ToAuditLogResponses maps a list of entries
+func (m *AuditMapperImpl) ToAuditLogResponses(entries []models.AuditLog) []response.AuditLog {
+	result := make([]response.AuditLog, len(entries))
+	for i, e := range entries {
+		result[i] = m.ToAuditLogResponse(e)
+	}
+	return result
+}
+
+func rawJSON(s *string) json.RawMessage {
+	if s == nil {
+		return nil
+	}
+	return json.RawMessage(*s)
+}
@@ -20,6 +20,10 @@ type JobMapper interface {
 	// Message mapping (simple, without nodes)
 	ToJobResponses(entities []models.Job) []response.Job
 	ToJobResponse(j models.Job) response.Job
+
+	// Run history mapping
+	ToJobRunResponses(entities []models.JobRun) []response.JobRun
+	ToJobRunResponse(r models.JobRun) response.JobRun
 }
 
 // ToJobResponseWithNodes converts a job model to response including nodes and shared users
@@ -33,6 +37,9 @@ func ToJobResponseWithNodes(j models.Job, accessList []models.JobUserAccess) res
 		Active:      j.Active,
 		Visibility:  j.Visibility,
 		OutputPath:  j.OutputPath,
+		RetryPolicy: j.RetryPolicy,
+		MaxRuntime:  j.MaxRuntime,
+		Parameters:  j.Parameters,
 		CreatedAt:   j.CreatedAt,
 		UpdatedAt:   j.UpdatedAt,
 		Nodes:       nil,
@@ -127,7 +134,7 @@ func toNodeResponse(n models.Node) response.Node {
 		Name: n.Name,
 		Xpos: n.Xpos,
 		Ypos: n.Ypos,
-		Data: n.Data,
+		Data: n.Data.Redacted(),
 	}
 
 	return node
@@ -26,6 +26,9 @@ func (mapper *JobMapperImpl) CreateJob(req request.CreateJob) models.Job {
 	result.Active = req.Active
 	result.Visibility = req.Visibility
 	// TODO: Handle slice field SharedWith manually (element struct not found: uint -> User)
+	result.RetryPolicy = req.RetryPolicy
+	result.MaxRuntime = req.MaxRuntime
+	result.Parameters = req.Parameters
 	return result
 
 }
@@ -52,6 +55,15 @@ func (mapper *JobMapperImpl) PatchJob(req request.UpdateJob) map[string]any {
 		result["visibility"] = *req.Visibility
 	}
 	// TODO: Handle slice field SharedWith manually
+	if req.RetryPolicy != nil {
+		result["retry_policy"] = *req.RetryPolicy
+	}
+	if req.MaxRuntime != nil {
+		result["max_runtime"] = *req.MaxRuntime
+	}
+	if req.Parameters != nil {
+		result["parameters"] = req.Parameters
+	}
 	// TODO: Handle slice field Nodes manually
 	return result
 
@@ -78,6 +90,9 @@ func (mapper *JobMapperImpl) ToJobResponse(j models.Job) response.Job {
 	result.Active = j.Active
 	result.Visibility = j.Visibility
 	result.OutputPath = j.OutputPath
+	result.RetryPolicy = j.RetryPolicy
+	result.MaxRuntime = j.MaxRuntime
+	result.Parameters = j.Parameters
 	result.CreatedAt = j.CreatedAt
 	result.UpdatedAt = j.UpdatedAt
 	//if len(j.Nodes) > 0 {
@@ -91,3 +106,29 @@ func (mapper *JobMapperImpl) ToJobResponse(j models.Job) response.Job {
 	return result
 
 }
+
+// ToJobRunResponses  Run history mapping
+func (mapper *JobMapperImpl) ToJobRunResponses(entities []models.JobRun) []response.JobRun {
+	result := make([]response.JobRun, len(entities))
+	for i, item := range entities {
+		result[i] = mapper.ToJobRunResponse(item)
+	}
+	return result
+
+}
+
+// ToJobRunResponse
+func (mapper *JobMapperImpl) ToJobRunResponse(r models.JobRun) response.JobRun {
+	var result response.JobRun
+	result.ID = r.ID
+	result.TriggerID = r.TriggerID
+	result.Attempt = r.Attempt
+	result.MaxAttempts = r.MaxAttempts
+	result.Status = r.Status
+	result.ErrorClass = r.ErrorClass
+	result.Error = r.Error
+	result.StartedAt = r.StartedAt
+	result.FinishedAt = r.FinishedAt
+	return result
+
+}
@@ -33,11 +33,13 @@ func (mapper *MetadataMapperImpl) ToMetadataResponse(m models.MetadataDatabase)
 	result.Host = m.Host
 	result.Port = m.Port
 	result.User = m.User
-	result.Password = m.Password
+	result.PasswordSet = m.Password != ""
 	result.DatabaseName = m.DatabaseName
 	result.SSLMode = m.SSLMode
 	result.Extra = m.Extra
 	result.DbType = m.DbType
+	result.Pool = m.Pool
+	result.TLS = m.TLS.Redacted()
 	return result
 
 }
@@ -48,10 +50,12 @@ func (mapper *MetadataMapperImpl) CreateDbMetadata(req request.CreateMetadata) m
 	result.Host = req.Host
 	result.Port = req.Port
 	result.User = req.User
-	result.Password = req.Password
+	result.Password = models.Secret(req.Password)
 	result.DatabaseName = req.DatabaseName
 	result.SSLMode = req.SSLMode
 	result.DbType = models.DBType(req.DbType)
+	result.Pool = req.Pool
+	result.TLS = req.TLS
 	return result
 
 }
@@ -68,7 +72,7 @@ func (mapper *MetadataMapperImpl) UpdateDbMetadata(req request.UpdateMetadata, m
 		m.User = *req.User
 	}
 	if req.Password != nil {
-		m.Password = *req.Password
+		m.Password = models.Secret(*req.Password)
 	}
 	if req.DatabaseName != nil {
 		m.DatabaseName = *req.DatabaseName
@@ -79,6 +83,12 @@ func (mapper *MetadataMapperImpl) UpdateDbMetadata(req request.UpdateMetadata, m
 	if req.DbType != nil {
 		m.DbType = models.DBType(*req.DbType)
 	}
+	if req.Pool != nil {
+		m.Pool = req.Pool
+	}
+	if req.TLS != nil {
+		m.TLS = req.TLS
+	}
 
 }
 
@@ -95,7 +105,7 @@ func (mapper *MetadataMapperImpl) PatchDbMetadata(req request.UpdateMetadata) ma
 		result["user"] = *req.User
 	}
 	if req.Password != nil {
-		result["password"] = *req.Password
+		result["password"] = models.Secret(*req.Password)
 	}
 	if req.DatabaseName != nil {
 		result["database_name"] = *req.DatabaseName
@@ -106,6 +116,12 @@ func (mapper *MetadataMapperImpl) PatchDbMetadata(req request.UpdateMetadata) ma
 	if req.DbType != nil {
 		result["db_type"] = *req.DbType
 	}
+	if req.Pool != nil {
+		result["pool"] = *req.Pool
+	}
+	if req.TLS != nil {
+		result["tls"] = *req.TLS
+	}
 	return result
 
 }
@@ -127,8 +143,8 @@ func (mapper *MetadataMapperImpl) ToSftpMetadataResponse(m models.MetadataSftp)
 	result.Host = m.Host
 	result.Port = m.Port
 	result.User = m.User
-	result.Password = m.Password
-	result.PrivateKey = m.PrivateKey
+	result.PasswordSet = m.Password != ""
+	result.PrivateKeySet = m.PrivateKey != ""
 	result.BasePath = m.BasePath
 	result.Extra = m.Extra
 	return result
@@ -141,8 +157,8 @@ func (mapper *MetadataMapperImpl) CreateSftpMetadata(req request.CreateSftpMetad
 	result.Host = req.Host
 	result.Port = req.Port
 	result.User = req.User
-	result.Password = req.Password
-	result.PrivateKey = req.PrivateKey
+	result.Password = models.Secret(req.Password)
+	result.PrivateKey = models.Secret(req.PrivateKey)
 	result.BasePath = req.BasePath
 	result.Extra = req.Extra
 	return result
@@ -162,10 +178,10 @@ func (mapper *MetadataMapperImpl) PatchSftpMetadata(req request.UpdateSftpMetada
 		result["user"] = *req.User
 	}
 	if req.Password != nil {
-		result["password"] = *req.Password
+		result["password"] = models.Secret(*req.Password)
 	}
 	if req.PrivateKey != nil {
-		result["private_key"] = *req.PrivateKey
+		result["private_key"] = models.Secret(*req.PrivateKey)
 	}
 	if req.BasePath != nil {
 		result["base_path"] = *req.BasePath
@@ -197,7 +213,7 @@ func (mapper *MetadataMapperImpl) ToEmailMetadataResponse(m models.MetadataEmail
 	result.SmtpHost = m.SmtpHost
 	result.SmtpPort = m.SmtpPort
 	result.Username = m.Username
-	result.Password = m.Password
+	result.PasswordSet = m.Password != ""
 	result.UseTLS = m.UseTLS
 	result.Extra = m.Extra
 	return result
@@ -213,7 +229,7 @@ func (mapper *MetadataMapperImpl) CreateEmailMetadata(req request.CreateEmailMet
 	result.SmtpHost = req.SmtpHost
 	result.SmtpPort = req.SmtpPort
 	result.Username = req.Username
-	result.Password = req.Password
+	result.Password = models.Secret(req.Password)
 	if req.UseTLS != nil {
 		result.UseTLS = *req.UseTLS
 	} else {
@@ -246,7 +262,7 @@ func (mapper *MetadataMapperImpl) PatchEmailMetadata(req request.UpdateEmailMeta
 		result["username"] = *req.Username
 	}
 	if req.Password != nil {
-		result["password"] = *req.Password
+		result["password"] = models.Secret(*req.Password)
 	}
 	if req.UseTLS != nil {
 		result["use_tls"] = *req.UseTLS
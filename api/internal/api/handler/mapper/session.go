@@ -0,0 +1,42 @@
+package mapper
+
+import (
+	"api/internal/api/handler/response"
+	"api/internal/api/models"
+)
+
+// SessionMapper maps sessions to DTOs
+type SessionMapper interface {
+	ToSessionResponse(s models.Session, currentID uint) response.Session
+	ToSessionResponses(sessions []models.Session, currentID uint) []response.Session
+}
+
+// SessionMapperImpl implements SessionMapper
+type SessionMapperImpl struct{}
+
+// NewSessionMapper creates a new SessionMapper instance
+func NewSessionMapper() SessionMapper {
+	return &SessionMapperImpl{}
+}
+
+// ToSessionResponse maps a session to its response, flagging the session with ID currentID
+func (m *SessionMapperImpl) ToSessionResponse(s models.Session, currentID uint) response.Session {
+	return response.Session{
+		ID:         s.ID,
+		UserAgent:  s.UserAgent,
+		IP:         s.IP,
+		CreatedAt:  s.CreatedAt,
+		LastUsedAt: s.LastUsedAt,
+		ExpiresAt:  s.ExpiresAt,
+		Current:    s.ID == currentID,
+	}
+}
+
+// ToSessionResponses maps a list of sessions
+func (m *SessionMapperImpl) ToSessionResponses(sessions []models.Session, currentID uint) []response.Session {
+	result := make([]response.Session, len(sessions))
+	for i, s := range sessions {
+		result[i] = m.ToSessionResponse(s, currentID)
+	}
+	return result
+}
@@ -101,7 +101,7 @@ func (m *TriggerMapperImpl) ToTriggerWithDetails(t models.Trigger) response.Trig
 		LastPolledAt:    t.LastPolledAt,
 		IntervalUnit:    t.Config.Cron.IntervalUnit,
 		LastError:       t.LastError,
-		Config:          t.Config,
+		Config:          t.Config.Redacted(),
 		CreatedAt:       t.CreatedAt,
 		UpdatedAt:       t.UpdatedAt,
 		Rules:           make([]response.TriggerRule, len(t.Rules)),
@@ -121,6 +121,7 @@ func (m *TriggerMapperImpl) ToTriggerWithDetails(t models.Trigger) response.Trig
 			Priority:      j.Priority,
 			Active:        j.Active,
 			PassEventData: j.PassEventData,
+			RetryPolicy:   j.RetryPolicy,
 		}
 	}
 
@@ -41,6 +41,7 @@ func (mapper *UserMapperImpl) EntityToUserResponse(user models.User) response.Us
 	result.Prenom = user.Prenom
 	result.Nom = user.Nom
 	result.Actif = user.Actif
+	result.ServiceAccount = user.ServiceAccount
 	return result
 
 }
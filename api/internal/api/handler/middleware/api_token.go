@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"api/internal/api/models"
+	"api/internal/api/service"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// tokenRoutes lists the routes personal access tokens can call, keyed by method and route
+// pattern, with the scope each one requires (empty: any token). Every other route only accepts
+// session tokens, so tokens cannot manage tokens, share jobs or reach admin routes.
+var tokenRoutes = map[string]models.TokenScope{
+	"GET /api/v1/me": "",
+
+	"GET /api/v1/jobs":              models.ScopeJobsRead,
+	"GET /api/v1/jobs/:id":          models.ScopeJobsRead,
+	"GET /api/v1/jobs/:id/runs":     models.ScopeJobsRead,
+	"POST /api/v1/jobs":             models.ScopeJobsWrite,
+	"PUT /api/v1/jobs/:id":          models.ScopeJobsWrite,
+	"DELETE /api/v1/jobs/:id":       models.ScopeJobsWrite,
+	"POST /api/v1/jobs/:id/execute": models.ScopeJobsExecute,
+	"POST /api/v1/jobs/:id/resume":  models.ScopeJobsExecute,
+	"POST /api/v1/jobs/:id/stop":    models.ScopeJobsExecute,
+
+	"GET /api/v1/metadata/db":           models.ScopeMetadataRead,
+	"GET /api/v1/metadata/db/:id":       models.ScopeMetadataRead,
+	"GET /api/v1/metadata/sftp":         models.ScopeMetadataRead,
+	"GET /api/v1/metadata/sftp/:id":     models.ScopeMetadataRead,
+	"GET /api/v1/metadata/email":        models.ScopeMetadataRead,
+	"GET /api/v1/metadata/email/:id":    models.ScopeMetadataRead,
+	"POST /api/v1/metadata/db":          models.ScopeMetadataWrite,
+	"PUT /api/v1/metadata/db/:id":       models.ScopeMetadataWrite,
+	"DELETE /api/v1/metadata/db/:id":    models.ScopeMetadataWrite,
+	"POST /api/v1/metadata/sftp":        models.ScopeMetadataWrite,
+	"PUT /api/v1/metadata/sftp/:id":     models.ScopeMetadataWrite,
+	"DELETE /api/v1/metadata/sftp/:id":  models.ScopeMetadataWrite,
+	"POST /api/v1/metadata/email":       models.ScopeMetadataWrite,
+	"PUT /api/v1/metadata/email/:id":    models.ScopeMetadataWrite,
+	"DELETE /api/v1/metadata/email/:id": models.ScopeMetadataWrite,
+
+	"GET /api/v1/triggers":                      models.ScopeTriggersRead,
+	"GET /api/v1/triggers/:id":                  models.ScopeTriggersRead,
+	"GET /api/v1/triggers/:id/executions":       models.ScopeTriggersRead,
+	"POST /api/v1/triggers":                     models.ScopeTriggersWrite,
+	"PUT /api/v1/triggers/:id":                  models.ScopeTriggersWrite,
+	"DELETE /api/v1/triggers/:id":               models.ScopeTriggersWrite,
+	"POST /api/v1/triggers/:id/activate":        models.ScopeTriggersWrite,
+	"POST /api/v1/triggers/:id/pause":           models.ScopeTriggersWrite,
+	"POST /api/v1/triggers/:id/rules":           models.ScopeTriggersWrite,
+	"PUT /api/v1/triggers/:id/rules/:ruleId":    models.ScopeTriggersWrite,
+	"DELETE /api/v1/triggers/:id/rules/:ruleId": models.ScopeTriggersWrite,
+	"POST /api/v1/triggers/:id/jobs":            models.ScopeTriggersWrite,
+	"DELETE /api/v1/triggers/:id/jobs/:jobId":   models.ScopeTriggersWrite,
+}
+
+// apiTokenAuthenticator is the part of service.ApiTokenService used here, replaced in tests
+type apiTokenAuthenticator interface {
+	Authenticate(token string) (*models.ApiToken, error)
+}
+
+// authenticateAPIToken authenticates a personal access token and checks the route accepts it
+func authenticateAPIToken(c *gin.Context, tokens apiTokenAuthenticator, token string) {
+	scope, allowed := tokenRoutes[c.Request.Method+" "+c.FullPath()]
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Personal access tokens cannot access this route"})
+		c.Abort()
+		return
+	}
+
+	apiToken, err := tokens.Authenticate(token)
+	if err != nil {
+		if !errors.Is(err, service.ErrInvalidApiToken) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate token"})
+		} else {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		}
+		c.Abort()
+		return
+	}
+
+	if scope != "" && !apiToken.Scopes.Has(scope) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Token lacks the " + string(scope) + " scope"})
+		c.Abort()
+		return
+	}
+
+	c.Set("userID", apiToken.UserID)
+	c.Set("userEmail", apiToken.User.Email)
+	c.Set("userRole", string(apiToken.User.Role))
+	c.Set("username", apiToken.User.Email)
+	c.Set("apiTokenID", apiToken.ID)
+
+	c.Next()
+}
@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"api/internal/api/models"
+	"api/internal/api/service"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeTokens authenticates the tokens of its map and counts the calls
+type fakeTokens struct {
+	tokens map[string]*models.ApiToken
+	calls  int
+}
+
+func (f *fakeTokens) Authenticate(token string) (*models.ApiToken, error) {
+	f.calls++
+	if t, ok := f.tokens[token]; ok {
+		return t, nil
+	}
+	return nil, service.ErrInvalidApiToken
+}
+
+func TestAuthenticateAPIToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := models.User{ID: 7, Email: "ci@example.com", Role: models.RoleUser}
+	tokens := &fakeTokens{tokens: map[string]*models.ApiToken{
+		"read":    {ID: 1, UserID: 7, User: user, Scopes: models.TokenScopes{models.ScopeJobsRead}},
+		"execute": {ID: 2, UserID: 7, User: user, Scopes: models.TokenScopes{models.ScopeJobsRead, models.ScopeJobsExecute}},
+	}}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		authenticateAPIToken(c, tokens, c.GetHeader("X-Token"))
+	})
+	ok := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"userID": c.GetUint("userID")})
+	}
+	router.GET("/api/v1/me", ok)
+	router.GET("/api/v1/jobs", ok)
+	router.POST("/api/v1/jobs/:id/execute", ok)
+	router.POST("/api/v1/jobs/:id/resume", ok)
+	router.POST("/api/v1/jobs/:id/stop", ok)
+	router.POST("/api/v1/tokens", ok)
+	router.GET("/api/v1/admin/users", ok)
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		token    string
+		want     int
+		wantAuth bool // the token is looked up, refused routes are rejected before
+	}{
+		{"any scope on me", http.MethodGet, "/api/v1/me", "read", http.StatusOK, true},
+		{"read scope on list", http.MethodGet, "/api/v1/jobs", "read", http.StatusOK, true},
+		{"read scope on execute", http.MethodPost, "/api/v1/jobs/3/execute", "read", http.StatusForbidden, true},
+		{"read scope on resume", http.MethodPost, "/api/v1/jobs/3/resume", "read", http.StatusForbidden, true},
+		{"read scope on stop", http.MethodPost, "/api/v1/jobs/3/stop", "read", http.StatusForbidden, true},
+		{"execute scope on execute", http.MethodPost, "/api/v1/jobs/3/execute", "execute", http.StatusOK, true},
+		{"execute scope on resume", http.MethodPost, "/api/v1/jobs/3/resume", "execute", http.StatusOK, true},
+		{"execute scope on stop", http.MethodPost, "/api/v1/jobs/3/stop", "execute", http.StatusOK, true},
+		{"token management refused", http.MethodPost, "/api/v1/tokens", "execute", http.StatusForbidden, false},
+		{"admin route refused", http.MethodGet, "/api/v1/admin/users", "execute", http.StatusForbidden, false},
+		{"unknown token", http.MethodGet, "/api/v1/jobs", "revoked", http.StatusUnauthorized, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens.calls = 0
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-Token", tt.token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("got status %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if authenticated := tokens.calls > 0; authenticated != tt.wantAuth {
+				t.Errorf("token looked up: %v, want %v", authenticated, tt.wantAuth)
+			}
+			if tt.want == http.StatusOK && !strings.Contains(w.Body.String(), `"userID":7`) {
+				t.Errorf("the token user is not in the context: %s", w.Body.String())
+			}
+		})
+	}
+}
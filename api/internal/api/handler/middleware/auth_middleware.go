@@ -3,6 +3,7 @@ package middleware
 import (
 	"api"
 	"api/internal/api/models"
+	"api/internal/api/service"
 	"api/pkg"
 	"net/http"
 	"strings"
@@ -10,7 +11,12 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// AuthMiddleware authenticates a session JWT or a personal access token. Tokens are only
+// accepted on the routes of tokenRoutes and need the scope the route requires. A JWT is rejected
+// as soon as its session is revoked.
 func AuthMiddleware(cfg api.AppConfig) gin.HandlerFunc {
+	tokens := service.NewApiTokenService()
+	sessions := service.NewSessionService()
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -28,6 +34,11 @@ func AuthMiddleware(cfg api.AppConfig) gin.HandlerFunc {
 		}
 
 		token := parts[1]
+		if pkg.IsAPIToken(token) {
+			authenticateAPIToken(c, tokens, token)
+			return
+		}
+
 		claims, err := pkg.ValidateToken(token, cfg.JWTConfig.Secret)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
@@ -35,6 +46,22 @@ func AuthMiddleware(cfg api.AppConfig) gin.HandlerFunc {
 			return
 		}
 
+		// Tokens issued before sessions existed have none, they are accepted until they expire
+		if claims.SessionID != 0 {
+			active, err := sessions.IsActive(claims.SessionID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check the session"})
+				c.Abort()
+				return
+			}
+			if !active {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session revoked or expired"})
+				c.Abort()
+				return
+			}
+			c.Set("sessionID", claims.SessionID)
+		}
+
 		// Set user info in context
 		c.Set("userID", claims.UserID)
 		c.Set("userEmail", claims.Email)
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"api"
+	"api/pkg"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitKey identifies whose requests a rate limit counts
+type RateLimitKey func(c *gin.Context) string
+
+// ByIP counts the requests of each client address
+func ByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ByUser counts the requests of each authenticated user, of each address before AuthMiddleware
+func ByUser(c *gin.Context) string {
+	if userID, ok := c.Get("userID"); ok {
+		return fmt.Sprintf("user:%d", userID.(uint))
+	}
+	return ByIP(c)
+}
+
+// RateLimit answers 429 to the requests beyond limit in a sliding window, counted per key under
+// name in Redis. Requests go through when rate limiting is disabled, limit is 0 or Redis fails.
+func RateLimit(cfg api.AppConfig, name string, limit int, window time.Duration, key RateLimitKey) gin.HandlerFunc {
+	if !cfg.RateLimitConfig.Enabled || limit <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	return func(c *gin.Context) {
+		allowed, retryAfter, err := pkg.RedisSlidingWindow("ratelimit:"+name+":"+key(c), limit, window)
+		if err != nil {
+			api.Logger.Warn().Err(err).Str("limit", name).Msg("Rate limit unavailable, request let through")
+			c.Next()
+			return
+		}
+		if !allowed {
+			SetRetryAfter(c, retryAfter)
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests, retry later"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ExpensiveRateLimit limits the calls of each user to an expensive endpoint (AI queries, job
+// executions), after AuthMiddleware
+func ExpensiveRateLimit(cfg api.AppConfig, name string) gin.HandlerFunc {
+	rl := cfg.RateLimitConfig
+	return RateLimit(cfg, name, rl.ExpensivePerUser, time.Duration(rl.ExpensiveWindow)*time.Second, ByUser)
+}
+
+// SetRetryAfter sets the Retry-After header of a 429, in seconds rounded up
+func SetRetryAfter(c *gin.Context, retryAfter time.Duration) {
+	seconds := int((retryAfter + time.Second - 1) / time.Second)
+	c.Header("Retry-After", strconv.Itoa(max(seconds, 1)))
+}
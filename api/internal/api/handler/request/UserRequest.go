@@ -8,7 +8,8 @@ type RegisterDTO struct {
 }
 
 type LoginDTO struct {
-	Email    string `json:"email" validate:"required,email"`
+	// Email, or the directory login (uid, sAMAccountName) when LDAP is enabled
+	Email    string `json:"email" validate:"required"`
 	Password string `json:"password" validate:"required"`
 }
 
@@ -0,0 +1,17 @@
+package request
+
+import "api/internal/api/models"
+
+// CreateApiToken creates a personal access token
+type CreateApiToken struct {
+	Name   string              `json:"name" validate:"required,max=100"`
+	Scopes []models.TokenScope `json:"scopes" validate:"required,min=1"`
+	// ExpiresInDays defaults to 90
+	ExpiresInDays int `json:"expiresInDays" validate:"omitempty,min=1,max=365"`
+}
+
+// CreateServiceAccount creates a user for another system, authenticating with tokens only
+type CreateServiceAccount struct {
+	Name        string `json:"name" validate:"required,max=64"`
+	Description string `json:"description"`
+}
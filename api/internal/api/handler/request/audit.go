@@ -0,0 +1,19 @@
+package request
+
+import (
+	"api/internal/api/models"
+	"time"
+)
+
+// AuditQuery filters the audit trail, read from the query string. From and To are RFC 3339.
+type AuditQuery struct {
+	UserID       *uint                `form:"userId"`
+	Action       models.AuditAction   `form:"action"`
+	ResourceType models.AuditResource `form:"resourceType"`
+	ResourceID   *uint                `form:"resourceId"`
+	From         *time.Time           `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To           *time.Time           `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	// Limit defaults to 100, ignored by the CSV export
+	Limit  int `form:"limit" validate:"omitempty,min=1,max=1000"`
+	Offset int `form:"offset" validate:"omitempty,min=0"`
+}
@@ -13,6 +13,9 @@ type CreateJob struct {
 	Active      bool                 `json:"active"`
 	Visibility  models.JobVisibility `json:"visibility"`           // public or private (default: private)
 	SharedWith  []uint               `json:"sharedWith,omitempty"` // User IDs to share with
+	RetryPolicy *models.RetryPolicy  `json:"retryPolicy,omitempty"`
+	MaxRuntime  int                  `json:"maxRuntime"` // seconds, 0 = unlimited
+	Parameters  models.JobParameters `json:"parameters,omitempty"`
 }
 type UpdateJob struct {
 	Name        *string               `json:"name,omitempty"`
@@ -22,6 +25,9 @@ type UpdateJob struct {
 	Active      *bool                 `json:"active,omitempty"`
 	Visibility  *models.JobVisibility `json:"visibility,omitempty"`
 	SharedWith  []uint                `json:"sharedWith,omitempty"` // User IDs to share with (replaces existing)
+	RetryPolicy *models.RetryPolicy   `json:"retryPolicy,omitempty"`
+	MaxRuntime  *int                  `json:"maxRuntime,omitempty"`
+	Parameters  models.JobParameters  `json:"parameters,omitempty"` // replaces existing, {} clears
 	Nodes       []models.Node         `json:"nodes,omitempty"`
 	Connexions  []response.Connexion  `json:"connexions"`
 }
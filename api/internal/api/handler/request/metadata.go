@@ -1,5 +1,7 @@
 package request
 
+import "api/internal/api/models"
+
 // DB Metadata DTOs
 
 type CreateMetadata struct {
@@ -10,16 +12,21 @@ type CreateMetadata struct {
 	DatabaseName string `json:"databaseName"`
 	SSLMode      string `json:"sslMode"`
 	DbType       string `json:"databaseType" validate:"required"`
+	// Pool and TLS settings of the connections generated for this database
+	Pool *models.PoolConfig `json:"pool,omitempty"`
+	TLS  *models.TLSConfig  `json:"tls,omitempty"`
 }
 
 type UpdateMetadata struct {
-	Host         *string `json:"host,omitempty"`
-	Port         *int    `json:"port,omitempty"`
-	User         *string `json:"user,omitempty"`
-	Password     *string `json:"password,omitempty"`
-	DatabaseName *string `json:"databaseName,omitempty"`
-	SSLMode      *string `json:"sslMode,omitempty"`
-	DbType       *string `json:"databaseType,omitempty"`
+	Host         *string            `json:"host,omitempty"`
+	Port         *int               `json:"port,omitempty"`
+	User         *string            `json:"user,omitempty"`
+	Password     *string            `json:"password,omitempty"`
+	DatabaseName *string            `json:"databaseName,omitempty"`
+	SSLMode      *string            `json:"sslMode,omitempty"`
+	DbType       *string            `json:"databaseType,omitempty"`
+	Pool         *models.PoolConfig `json:"pool,omitempty"`
+	TLS          *models.TLSConfig  `json:"tls,omitempty"`
 }
 
 // SFTP Metadata DTOs
@@ -77,3 +84,12 @@ type GuessSchemaRequest struct {
 	Query        string `json:"query" validate:"required"`
 	ConnectionID uint   `json:"connectionId" validate:"required"`
 }
+
+// TableDDLPreviewRequest describes the target table of a db_output node and the columns it should hold
+type TableDDLPreviewRequest struct {
+	ConnectionID uint               `json:"connectionId" validate:"required"`
+	Table        string             `json:"table" validate:"required"`
+	DbSchema     string             `json:"dbschema"`
+	DataModels   []models.DataModel `json:"dataModel" validate:"required,min=1"`
+	KeyColumns   []string           `json:"keyColumns"`
+}
@@ -33,16 +33,18 @@ type UpdateTriggerRule struct {
 
 // LinkJob is the request for linking a job to a trigger
 type LinkJob struct {
-	JobID         uint `json:"jobId" validate:"required"`
-	Priority      int  `json:"priority"`
-	PassEventData bool `json:"passEventData"`
+	JobID         uint                `json:"jobId" validate:"required"`
+	Priority      int                 `json:"priority"`
+	PassEventData bool                `json:"passEventData"`
+	RetryPolicy   *models.RetryPolicy `json:"retryPolicy,omitempty"` // overrides the job's policy
 }
 
 // UpdateJobLink is the request for updating a trigger-job link
 type UpdateJobLink struct {
-	Priority      *int  `json:"priority,omitempty"`
-	Active        *bool `json:"active,omitempty"`
-	PassEventData *bool `json:"passEventData,omitempty"`
+	Priority      *int                `json:"priority,omitempty"`
+	Active        *bool               `json:"active,omitempty"`
+	PassEventData *bool               `json:"passEventData,omitempty"`
+	RetryPolicy   *models.RetryPolicy `json:"retryPolicy,omitempty"`
 }
 
 // TestDatabaseConnection is the request for testing a database connection
@@ -6,6 +6,15 @@ type UserResponseDTO struct {
 	Prenom string `json:"prenom"`
 	Nom    string `json:"nom"`
 	Actif  bool   `json:"actif"`
+	// ServiceAccount users authenticate with personal access tokens only
+	ServiceAccount bool `json:"serviceAccount,omitempty"`
+}
+
+// LDAPSync counts the users changed by a directory sync
+type LDAPSync struct {
+	Checked     int `json:"checked"`
+	Updated     int `json:"updated"`
+	Deactivated int `json:"deactivated"`
 }
 
 type AuthResponseDTO struct {
@@ -0,0 +1,24 @@
+package response
+
+import (
+	"api/internal/api/models"
+	"time"
+)
+
+// ApiToken describes a personal access token, without the token itself
+type ApiToken struct {
+	ID         uint                `json:"id"`
+	Name       string              `json:"name"`
+	Prefix     string              `json:"prefix"`
+	Scopes     []models.TokenScope `json:"scopes"`
+	ExpiresAt  *time.Time          `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time          `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time          `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time           `json:"createdAt"`
+}
+
+// ApiTokenCreated is returned once, when a token is created: Token cannot be read again
+type ApiTokenCreated struct {
+	ApiToken
+	Token string `json:"token"`
+}
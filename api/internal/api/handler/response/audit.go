@@ -0,0 +1,29 @@
+package response
+
+import (
+	"api/internal/api/models"
+	"encoding/json"
+	"time"
+)
+
+// AuditLog is an entry of the audit trail
+type AuditLog struct {
+	ID           uint                 `json:"id"`
+	CreatedAt    time.Time            `json:"createdAt"`
+	UserID       *uint                `json:"userId,omitempty"`
+	UserEmail    string               `json:"userEmail,omitempty"`
+	ApiTokenID   *uint                `json:"apiTokenId,omitempty"`
+	IP           string               `json:"ip,omitempty"`
+	Action       models.AuditAction   `json:"action"`
+	ResourceType models.AuditResource `json:"resourceType,omitempty"`
+	ResourceID   *uint                `json:"resourceId,omitempty"`
+	Before       json.RawMessage      `json:"before,omitempty"`
+	After        json.RawMessage      `json:"after,omitempty"`
+	Details      json.RawMessage      `json:"details,omitempty"`
+}
+
+// AuditLogPage is a page of the audit trail, newest first
+type AuditLogPage struct {
+	Items []AuditLog `json:"items"`
+	Total int64      `json:"total"`
+}
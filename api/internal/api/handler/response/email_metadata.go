@@ -1,16 +1,16 @@
 package response
 
 type EmailMetadata struct {
-	ID       uint   `json:"id"`
-	Name     string `json:"name"`
-	ImapHost string `json:"imapHost"`
-	ImapPort int    `json:"imapPort"`
-	SmtpHost string `json:"smtpHost"`
-	SmtpPort int    `json:"smtpPort"`
-	Username string `json:"username"`
-	Password string `json:"password"`
-	UseTLS   bool   `json:"useTls"`
-	Extra    string `json:"extra"`
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	ImapHost    string `json:"imapHost"`
+	ImapPort    int    `json:"imapPort"`
+	SmtpHost    string `json:"smtpHost"`
+	SmtpPort    int    `json:"smtpPort"`
+	Username    string `json:"username"`
+	PasswordSet bool   `json:"passwordSet"`
+	UseTLS      bool   `json:"useTls"`
+	Extra       string `json:"extra"`
 }
 
 type TestEmailConnectionResult struct {
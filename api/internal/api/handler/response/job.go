@@ -24,6 +24,9 @@ type Job struct {
 	Active      bool                 `json:"active"`
 	Visibility  models.JobVisibility `json:"visibility"`
 	OutputPath  string               `json:"outputPath"`
+	RetryPolicy *models.RetryPolicy  `json:"retryPolicy,omitempty"`
+	MaxRuntime  int                  `json:"maxRuntime"`
+	Parameters  models.JobParameters `json:"parameters,omitempty"`
 	CreatedAt   time.Time            `json:"createdAt"`
 	UpdatedAt   time.Time            `json:"updatedAt"`
 }
@@ -46,6 +49,9 @@ type JobWithNodes struct {
 	Active               bool                 `json:"active"`
 	Visibility           models.JobVisibility `json:"visibility"`
 	OutputPath           string               `json:"outputPath"`
+	RetryPolicy          *models.RetryPolicy  `json:"retryPolicy,omitempty"`
+	MaxRuntime           int                  `json:"maxRuntime"`
+	Parameters           models.JobParameters `json:"parameters,omitempty"`
 	CreatedAt            time.Time            `json:"createdAt"`
 	UpdatedAt            time.Time            `json:"updatedAt"`
 	Nodes                []Node               `json:"nodes"`
@@ -54,6 +60,19 @@ type JobWithNodes struct {
 	NotificationContacts []NotificationContact `json:"notificationContacts"`
 }
 
+// JobRun represents a single execution attempt of a job
+type JobRun struct {
+	ID          uint                `json:"id"`
+	TriggerID   *uint               `json:"triggerId,omitempty"`
+	Attempt     int                 `json:"attempt"`
+	MaxAttempts int                 `json:"maxAttempts"`
+	Status      models.JobRunStatus `json:"status"`
+	ErrorClass  models.FailureClass `json:"errorClass,omitempty"`
+	Error       string              `json:"error,omitempty"`
+	StartedAt   time.Time           `json:"startedAt"`
+	FinishedAt  *time.Time          `json:"finishedAt,omitempty"`
+}
+
 type Connexion struct {
 	SourceNodeId   int             `json:"sourceNodeId"`
 	SourcePort     int             `json:"sourcePort"`
@@ -3,13 +3,15 @@ package response
 import "api/internal/api/models"
 
 type Metadata struct {
-	ID           uint          `json:"id"`
-	Host         string        `json:"host"`
-	Port         int           `json:"port"`
-	User         string        `json:"user"`
-	Password     string        `json:"password"`
-	DatabaseName string        `json:"databaseName"`
-	DbType       models.DBType `json:"databaseType"`
-	SSLMode      string        `json:"sslMode"`
-	Extra        string        `json:"extra"`
+	ID           uint               `json:"id"`
+	Host         string             `json:"host"`
+	Port         int                `json:"port"`
+	User         string             `json:"user"`
+	PasswordSet  bool               `json:"passwordSet"`
+	DatabaseName string             `json:"databaseName"`
+	DbType       models.DBType      `json:"databaseType"`
+	SSLMode      string             `json:"sslMode"`
+	Extra        string             `json:"extra"`
+	Pool         *models.PoolConfig `json:"pool,omitempty"`
+	TLS          *models.TLSConfig  `json:"tls,omitempty"`
 }
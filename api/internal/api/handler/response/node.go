@@ -8,6 +8,12 @@ type GuessSchemaResponse struct {
 	DataModels []models.DataModel `json:"dataModels"`
 }
 
+// TableDDLPreviewResponse lists the statements a db_output node would run on its target table
+type TableDDLPreviewResponse struct {
+	TableExists bool     `json:"tableExists"`
+	Statements  []string `json:"statements"`
+}
+
 type Node struct {
 	ID   int             `json:"id"`
 	Type models.NodeType `json:"type"`
@@ -0,0 +1,7 @@
+package response
+
+// SecretRotation reports the credentials re-encrypted with the active master key
+type SecretRotation struct {
+	KeyID   string         `json:"keyId"`
+	Rotated map[string]int `json:"rotated"`
+}
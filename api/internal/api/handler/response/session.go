@@ -0,0 +1,15 @@
+package response
+
+import "time"
+
+// Session describes a login of the current user on a device
+type Session struct {
+	ID         uint      `json:"id"`
+	UserAgent  string    `json:"userAgent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastUsedAt time.Time `json:"lastUsedAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	// Current is the session of the token making the request
+	Current bool `json:"current"`
+}
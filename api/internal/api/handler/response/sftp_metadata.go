@@ -1,12 +1,13 @@
 package response
 
 type SftpMetadata struct {
-	ID         uint   `json:"id"`
-	Host       string `json:"host"`
-	Port       int    `json:"port"`
-	User       string `json:"user"`
-	Password   string `json:"password"`
-	PrivateKey string `json:"privateKey"`
-	BasePath   string `json:"basePath"`
-	Extra      string `json:"extra"`
+	ID   uint   `json:"id"`
+	Host string `json:"host"`
+	Port int    `json:"port"`
+	User string `json:"user"`
+	// Credentials are write-only, the response only tells whether they are set
+	PasswordSet   bool   `json:"passwordSet"`
+	PrivateKeySet bool   `json:"privateKeySet"`
+	BasePath      string `json:"basePath"`
+	Extra         string `json:"extra"`
 }
@@ -59,6 +59,8 @@ type TriggerJobLink struct {
 	Priority      int    `json:"priority"`
 	Active        bool   `json:"active"`
 	PassEventData bool   `json:"passEventData"`
+
+	RetryPolicy *models.RetryPolicy `json:"retryPolicy,omitempty"`
 }
 
 // TriggerExecution is the response for a trigger execution record
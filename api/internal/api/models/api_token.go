@@ -0,0 +1,90 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+)
+
+// TokenScope grants a personal access token access to a group of API routes
+type TokenScope string
+
+const (
+	ScopeJobsRead      TokenScope = "jobs:read"
+	ScopeJobsWrite     TokenScope = "jobs:write"
+	ScopeJobsExecute   TokenScope = "jobs:execute"
+	ScopeMetadataRead  TokenScope = "metadata:read"
+	ScopeMetadataWrite TokenScope = "metadata:write"
+	ScopeTriggersRead  TokenScope = "triggers:read"
+	ScopeTriggersWrite TokenScope = "triggers:write"
+)
+
+// AllTokenScopes lists the scopes a token can be given
+var AllTokenScopes = []TokenScope{
+	ScopeJobsRead, ScopeJobsWrite, ScopeJobsExecute,
+	ScopeMetadataRead, ScopeMetadataWrite,
+	ScopeTriggersRead, ScopeTriggersWrite,
+}
+
+// Valid reports whether s is a known scope
+func (s TokenScope) Valid() bool {
+	return slices.Contains(AllTokenScopes, s)
+}
+
+// TokenScopes is the set of scopes of a token, stored as a JSON array
+type TokenScopes []TokenScope
+
+// Has reports whether the set grants s
+func (s TokenScopes) Has(scope TokenScope) bool {
+	return slices.Contains(s, scope)
+}
+
+// Value implements driver.Valuer for GORM
+func (s TokenScopes) Value() (driver.Value, error) {
+	if s == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(s)
+}
+
+// Scan implements sql.Scanner for GORM
+func (s *TokenScopes) Scan(value interface{}) error {
+	var bytes []byte
+	switch v := value.(type) {
+	case nil:
+		*s = nil
+		return nil
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return errors.New("failed to scan TokenScopes: expected []byte")
+	}
+	return json.Unmarshal(bytes, s)
+}
+
+// ApiToken is a personal access token authenticating API calls as its user with a subset of the
+// routes (see TokenScope). Only the SHA-256 of the token is stored: it is shown once, when created.
+type ApiToken struct {
+	ID     uint   `gorm:"primaryKey" json:"id"`
+	UserID uint   `gorm:"not null;index" json:"userId"`
+	User   User   `json:"-"`
+	Name   string `gorm:"not null" json:"name"`
+	// Prefix is the start of the token, shown to tell tokens apart
+	Prefix    string      `gorm:"type:varchar(16);not null" json:"prefix"`
+	TokenHash string      `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	Scopes    TokenScopes `gorm:"type:jsonb" json:"scopes"`
+
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// Active reports whether the token can authenticate at now
+func (t ApiToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
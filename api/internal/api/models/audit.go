@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+// AuditAction is what an audit entry records, "<resource>.<verb>"
+type AuditAction string
+
+const (
+	AuditLogin       AuditAction = "auth.login"
+	AuditLoginFailed AuditAction = "auth.login_failed"
+	AuditLogout      AuditAction = "auth.logout"
+	// AuditTokenReuse is a rotated refresh token presented again, its session was revoked
+	AuditTokenReuse    AuditAction = "auth.token_reuse"
+	AuditSessionRevoke AuditAction = "auth.session_revoke"
+	AuditForceLogout   AuditAction = "auth.force_logout"
+	// AuditAccountLocked is an account locked after repeated failed logins
+	AuditAccountLocked AuditAction = "auth.account_locked"
+
+	AuditJobCreate  AuditAction = "job.create"
+	AuditJobUpdate  AuditAction = "job.update"
+	AuditJobDelete  AuditAction = "job.delete"
+	AuditJobShare   AuditAction = "job.share"
+	AuditJobUnshare AuditAction = "job.unshare"
+	// AuditJobExecute is a run started by a user, or by a trigger (no user, triggerId in details)
+	AuditJobExecute AuditAction = "job.execute"
+	AuditJobResume  AuditAction = "job.resume"
+	AuditJobStop    AuditAction = "job.stop"
+
+	AuditMetadataCreate AuditAction = "metadata.create"
+	AuditMetadataUpdate AuditAction = "metadata.update"
+	AuditMetadataDelete AuditAction = "metadata.delete"
+
+	AuditTriggerCreate     AuditAction = "trigger.create"
+	AuditTriggerUpdate     AuditAction = "trigger.update"
+	AuditTriggerDelete     AuditAction = "trigger.delete"
+	AuditTriggerActivate   AuditAction = "trigger.activate"
+	AuditTriggerPause      AuditAction = "trigger.pause"
+	AuditTriggerRuleAdd    AuditAction = "trigger.rule_add"
+	AuditTriggerRuleUpdate AuditAction = "trigger.rule_update"
+	AuditTriggerRuleDelete AuditAction = "trigger.rule_delete"
+	AuditTriggerLinkJob    AuditAction = "trigger.link_job"
+	AuditTriggerUnlinkJob  AuditAction = "trigger.unlink_job"
+	AuditTriggerUpdateJob  AuditAction = "trigger.update_job"
+)
+
+// AuditResource is the type of the resource an audit entry is about
+type AuditResource string
+
+const (
+	AuditResourceUser          AuditResource = "user"
+	AuditResourceJob           AuditResource = "job"
+	AuditResourceTrigger       AuditResource = "trigger"
+	AuditResourceMetadataDB    AuditResource = "metadata_db"
+	AuditResourceMetadataSftp  AuditResource = "metadata_sftp"
+	AuditResourceMetadataEmail AuditResource = "metadata_email"
+)
+
+// AuditLog is an entry of the audit trail. Entries are only inserted: the table refuses updates
+// and deletes. Before and After are the API representations of the resource (without
+// credentials) around a change, Details holds the other parameters of the action.
+type AuditLog struct {
+	ID        uint      `gorm:"primaryKey"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index"`
+	// UserID is nil for actions without user (trigger executions, failed logins)
+	UserID *uint `gorm:"index"`
+	// UserEmail is kept when the user is deleted, and is the login given on failed logins
+	UserEmail  string
+	ApiTokenID *uint
+	IP         string `gorm:"type:varchar(64)"`
+
+	Action       AuditAction   `gorm:"type:varchar(64);not null;index"`
+	ResourceType AuditResource `gorm:"type:varchar(32);index:idx_audit_log_resource"`
+	ResourceID   *uint         `gorm:"index:idx_audit_log_resource"`
+	Before       *string       `gorm:"type:jsonb"`
+	After        *string       `gorm:"type:jsonb"`
+	Details      *string       `gorm:"type:jsonb"`
+}
@@ -1,6 +1,13 @@
 package models
 
-import "fmt"
+import (
+	"api/internal/secret"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+)
 
 // DBType represents the type of database
 type DBType string
@@ -9,6 +16,9 @@ const (
 	DBTypePostgres  DBType = "postgres"
 	DBTypeSQLServer DBType = "sqlserver"
 	DBTypeMySQL     DBType = "mysql"
+	// DBTypeSQLite and DBTypeDuckDB are file-based: Database holds the path of the database file
+	DBTypeSQLite DBType = "sqlite"
+	DBTypeDuckDB DBType = "duckdb"
 )
 
 // DBConnectionConfig holds database connection details
@@ -25,6 +35,158 @@ type DBConnectionConfig struct {
 
 	// DSN allows specifying a full Data Source name directly without building it
 	DSN string `json:"dsn"`
+
+	// Pool tunes the connection pool and timeouts of the generated program
+	Pool *PoolConfig `json:"pool,omitempty"`
+	// TLS holds the certificates used to reach the database
+	TLS *TLSConfig `json:"tls,omitempty"`
+	// CertDir is the directory the TLS files are read from, set when they are written for a run
+	CertDir string `json:"-"`
+}
+
+// PoolConfig tunes the connection pool and timeouts of a generated connection
+type PoolConfig struct {
+	MaxOpenConns    int `json:"maxOpenConns,omitempty"`
+	MaxIdleConns    int `json:"maxIdleConns,omitempty"`
+	ConnMaxLifetime int `json:"connMaxLifetime,omitempty"` // seconds
+	ConnMaxIdleTime int `json:"connMaxIdleTime,omitempty"` // seconds
+	ConnectTimeout  int `json:"connectTimeout,omitempty"`  // seconds, also bounds the startup ping
+	QueryTimeout    int `json:"queryTimeout,omitempty"`    // seconds, PostgreSQL and MySQL only
+}
+
+// Value implements driver.Valuer for GORM
+func (p PoolConfig) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+// Scan implements sql.Scanner for GORM
+func (p *PoolConfig) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan PoolConfig: expected []byte")
+	}
+	return json.Unmarshal(bytes, p)
+}
+
+// TLSConfig holds the PEM encoded certificates of a connection
+type TLSConfig struct {
+	// CACert is the bundle the server certificate is verified against
+	CACert string `json:"caCert,omitempty"`
+	// ClientCert and ClientKey authenticate the client (not supported by SQL Server)
+	ClientCert string `json:"clientCert,omitempty"`
+	ClientKey  string `json:"clientKey,omitempty"`
+	// ServerName is the name expected in the server certificate when it differs from Host
+	ServerName string `json:"serverName,omitempty"`
+}
+
+// Value implements driver.Valuer for GORM
+func (t TLSConfig) Value() (driver.Value, error) {
+	raw, err := json.Marshal(t)
+	if err != nil {
+		return nil, err
+	}
+	return secret.SealJSON(raw)
+}
+
+// Scan implements sql.Scanner for GORM
+func (t *TLSConfig) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan TLSConfig: expected []byte")
+	}
+	opened, err := secret.OpenJSON(bytes)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(opened, t)
+}
+
+// Redacted returns a copy without the client key, as returned by the API
+func (t *TLSConfig) Redacted() *TLSConfig {
+	if t == nil {
+		return nil
+	}
+	redacted := *t
+	redacted.ClientKey = ""
+	return &redacted
+}
+
+// Names of the TLS files written in CertDir
+const (
+	CACertFile     = "ca.pem"
+	ClientCertFile = "client.pem"
+	ClientKeyFile  = "client.key"
+)
+
+// CertFiles returns the PEM content of the TLS files of the connection, keyed by file name
+func (c DBConnectionConfig) CertFiles() map[string]string {
+	files := make(map[string]string)
+	if c.TLS == nil {
+		return files
+	}
+	if c.TLS.CACert != "" {
+		files[CACertFile] = c.TLS.CACert
+	}
+	if c.TLS.ClientCert != "" && c.TLS.ClientKey != "" {
+		files[ClientCertFile] = c.TLS.ClientCert
+		files[ClientKeyFile] = c.TLS.ClientKey
+	}
+	return files
+}
+
+// CertPath returns the path of a TLS file of the connection, empty when it has not been written
+func (c DBConnectionConfig) CertPath(name string) string {
+	if c.CertDir == "" {
+		return ""
+	}
+	if _, ok := c.CertFiles()[name]; !ok {
+		return ""
+	}
+	return path.Join(c.CertDir, name)
+}
+
+// UsesTLSFiles reports whether the connection string references TLS files
+func (c DBConnectionConfig) UsesTLSFiles() bool {
+	return c.CertDir != "" && len(c.CertFiles()) > 0
+}
+
+// ValidateTLS checks the TLS settings are complete and supported by the driver
+func (c DBConnectionConfig) ValidateTLS() error {
+	if c.TLS == nil {
+		return nil
+	}
+	if (c.TLS.ClientCert == "") != (c.TLS.ClientKey == "") {
+		return errors.New("client certificate and key must be set together")
+	}
+	if c.TLS.ClientCert != "" && c.Type == DBTypeSQLServer {
+		return errors.New("SQL Server connections do not support client certificates")
+	}
+	if c.IsFileBased() && len(c.CertFiles()) > 0 {
+		return fmt.Errorf("%s databases are local files and take no TLS settings", c.Type)
+	}
+	return nil
+}
+
+// tlsSSLMode upgrades a disabled SSL mode when TLS files are configured
+func (c DBConnectionConfig) tlsSSLMode(sslMode string) string {
+	if !c.UsesTLSFiles() || (sslMode != "" && sslMode != "disable") {
+		return sslMode
+	}
+	if c.CertPath(CACertFile) != "" {
+		return "verify-full"
+	}
+	return "require"
+}
+
+// MySQLTLSName returns the name the TLS configuration of a MySQL connection is registered with
+func (c DBConnectionConfig) MySQLTLSName() string {
+	return "dos_" + c.GetConnectionID()
 }
 
 // BuildConnectionString creates a connection string based on the database type
@@ -40,6 +202,10 @@ func (c DBConnectionConfig) BuildConnectionString() string {
 		return c.buildSQLServerConnectionString()
 	case DBTypeMySQL:
 		return c.buildMySQLConnectionString()
+	case DBTypeSQLite:
+		return c.buildSQLiteConnectionString()
+	case DBTypeDuckDB:
+		return c.buildDuckDBConnectionString()
 	default:
 		panic("Unsupported DB type: " + string(c.Type))
 	}
@@ -47,12 +213,27 @@ func (c DBConnectionConfig) BuildConnectionString() string {
 
 // buildPostgresConnectionString creates a PostgreSQL DSN
 func (c DBConnectionConfig) buildPostgresConnectionString() string {
-	sslMode := c.SSLMode
+	sslMode := c.tlsSSLMode(c.SSLMode)
 	if sslMode == "" {
 		sslMode = "disable"
 	}
-	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		c.Host, c.Port, c.Username, c.Password, c.Database, sslMode)
+
+	if c.Pool != nil && c.Pool.ConnectTimeout > 0 {
+		dsn += fmt.Sprintf(" connect_timeout=%d", c.Pool.ConnectTimeout)
+	}
+	if c.Pool != nil && c.Pool.QueryTimeout > 0 {
+		// Unknown keys are sent as run-time parameters, in milliseconds for statement_timeout
+		dsn += fmt.Sprintf(" statement_timeout=%d", c.Pool.QueryTimeout*1000)
+	}
+	if ca := c.CertPath(CACertFile); ca != "" {
+		dsn += " sslrootcert=" + ca
+	}
+	if cert := c.CertPath(ClientCertFile); cert != "" {
+		dsn += fmt.Sprintf(" sslcert=%s sslkey=%s", cert, c.CertPath(ClientKeyFile))
+	}
+	return dsn
 }
 
 // buildSQLServerConnectionString creates a SQL Server DSN
@@ -64,12 +245,23 @@ func (c DBConnectionConfig) buildSQLServerConnectionString() string {
 	}
 
 	encrypt := "disable"
-	if c.SSLMode != "" && c.SSLMode != "disable" {
+	if sslMode := c.tlsSSLMode(c.SSLMode); sslMode != "" && sslMode != "disable" {
 		encrypt = "true"
 	}
 
-	return fmt.Sprintf("sqlserver://%s:%s@%s:%d?database=%s&encrypt=%s",
+	dsn := fmt.Sprintf("sqlserver://%s:%s@%s:%d?database=%s&encrypt=%s",
 		c.Username, c.Password, c.Host, c.Port, c.Database, encrypt)
+
+	if c.Pool != nil && c.Pool.ConnectTimeout > 0 {
+		dsn += fmt.Sprintf("&dial+timeout=%d", c.Pool.ConnectTimeout)
+	}
+	if ca := c.CertPath(CACertFile); ca != "" {
+		dsn += "&certificate=" + ca
+		if c.TLS.ServerName != "" {
+			dsn += "&hostNameInCertificate=" + c.TLS.ServerName
+		}
+	}
+	return dsn
 }
 
 // buildMySQLConnectionString creates a MySQL DSN
@@ -80,12 +272,40 @@ func (c DBConnectionConfig) buildMySQLConnectionString() string {
 	}
 
 	tls := "false"
-	if c.SSLMode != "" && c.SSLMode != "disable" {
+	if c.UsesTLSFiles() {
+		// Registered by the generated program from the TLS files
+		tls = c.MySQLTLSName()
+	} else if c.SSLMode != "" && c.SSLMode != "disable" {
 		tls = "true"
 	}
 
-	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?tls=%s&parseTime=true",
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?tls=%s&parseTime=true",
 		c.Username, c.Password, c.Host, c.Port, c.Database, tls)
+
+	if c.Pool != nil && c.Pool.ConnectTimeout > 0 {
+		dsn += fmt.Sprintf("&timeout=%ds", c.Pool.ConnectTimeout)
+	}
+	if c.Pool != nil && c.Pool.QueryTimeout > 0 {
+		dsn += fmt.Sprintf("&readTimeout=%ds&writeTimeout=%ds", c.Pool.QueryTimeout, c.Pool.QueryTimeout)
+	}
+	return dsn
+}
+
+// buildSQLiteConnectionString creates a SQLite DSN (modernc.org/sqlite)
+func (c DBConnectionConfig) buildSQLiteConnectionString() string {
+	// Wait for locks held by other connections instead of failing with SQLITE_BUSY,
+	// and store times in a format read back as time.Time
+	return fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_time_format=sqlite", c.Database)
+}
+
+// buildDuckDBConnectionString creates a DuckDB DSN, an empty path opening an in-memory database
+func (c DBConnectionConfig) buildDuckDBConnectionString() string {
+	return c.Database
+}
+
+// IsFileBased reports whether the database is a local file rather than a server
+func (c DBConnectionConfig) IsFileBased() bool {
+	return c.Type == DBTypeSQLite || c.Type == DBTypeDuckDB
 }
 
 // GetDriverName returns the Go SQL driver name for this database type
@@ -97,6 +317,10 @@ func (c DBConnectionConfig) GetDriverName() string {
 		return "sqlserver"
 	case DBTypeMySQL:
 		return "mysql"
+	case DBTypeSQLite:
+		return "sqlite"
+	case DBTypeDuckDB:
+		return "duckdb"
 	default:
 		return "postgres"
 	}
@@ -111,6 +335,10 @@ func (c DBConnectionConfig) GetImportPath() string {
 		return "github.com/denisenkom/go-mssqldb"
 	case DBTypeMySQL:
 		return "github.com/go-sql-driver/mysql"
+	case DBTypeSQLite:
+		return "modernc.org/sqlite"
+	case DBTypeDuckDB:
+		return "github.com/duckdb/duckdb-go/v2"
 	default:
 		return "github.com/lib/pq"
 	}
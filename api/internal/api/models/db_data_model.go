@@ -101,7 +101,9 @@ func (d *DataModel) normalizeGoType() string {
 	case strings.Contains(strings.ToLower(goType), "json"):
 		return "[]byte"
 
-	// 3. Use more specific integer checks
+	// 3. Use more specific integer checks; uint64 does not fit sql.NullInt64
+	case goType == "uint64":
+		return "uint64"
 	case goType == "int64", strings.HasPrefix(goType, "int64"):
 		return "int64"
 	case goType == "int32", strings.HasPrefix(goType, "int32"):
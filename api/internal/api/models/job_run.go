@@ -0,0 +1,170 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// BackoffStrategy defines how the delay between two attempts grows
+type BackoffStrategy string
+
+const (
+	BackoffFixed       BackoffStrategy = "fixed"
+	BackoffExponential BackoffStrategy = "exponential"
+)
+
+// FailureClass categorises why a job run failed
+type FailureClass string
+
+const (
+	FailureClassConnection FailureClass = "connection" // network, timeouts, database unreachable
+	FailureClassData       FailureClass = "data"       // constraint violations, conversion errors, bad rows
+	FailureClassTimeout    FailureClass = "timeout"    // the run exceeded the job's max runtime
+	FailureClassUnknown    FailureClass = "unknown"
+)
+
+const (
+	// MaxRetryAttempts is the largest number of attempts a retry policy may allow
+	MaxRetryAttempts = 20
+	// MaxRetryDelay bounds the delay between two attempts, whatever the policy
+	MaxRetryDelay = 24 * time.Hour
+)
+
+// RetryPolicy describes how a failed job run is retried.
+// It can be set on a job and overridden on a trigger-job link.
+type RetryPolicy struct {
+	// Total number of attempts, including the first one (1 = no retry)
+	MaxAttempts int `json:"maxAttempts"`
+
+	// fixed or exponential (default: fixed)
+	Backoff BackoffStrategy `json:"backoff,omitempty"`
+
+	// Delay before the first retry, in seconds
+	DelaySeconds int `json:"delaySeconds"`
+
+	// Upper bound for exponential backoff, in seconds (0 = MaxRetryDelay)
+	MaxDelaySeconds int `json:"maxDelaySeconds,omitempty"`
+
+	// Only retry on these failure classes (empty = retry on any failure)
+	RetryOn []FailureClass `json:"retryOn,omitempty"`
+}
+
+// Value implements driver.Valuer for GORM
+func (p RetryPolicy) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+// Scan implements sql.Scanner for GORM
+func (p *RetryPolicy) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan RetryPolicy: expected []byte")
+	}
+	return json.Unmarshal(bytes, p)
+}
+
+// Validate checks the policy before it is saved on a job or a trigger-job link
+func (p *RetryPolicy) Validate() error {
+	maxDelaySeconds := int(MaxRetryDelay / time.Second)
+	switch {
+	case p.MaxAttempts < 0 || p.MaxAttempts > MaxRetryAttempts:
+		return fmt.Errorf("retry policy: maxAttempts must be between 0 and %d", MaxRetryAttempts)
+	case p.DelaySeconds < 0 || p.DelaySeconds > maxDelaySeconds:
+		return fmt.Errorf("retry policy: delaySeconds must be between 0 and %d", maxDelaySeconds)
+	case p.MaxDelaySeconds < 0 || p.MaxDelaySeconds > maxDelaySeconds:
+		return fmt.Errorf("retry policy: maxDelaySeconds must be between 0 and %d", maxDelaySeconds)
+	}
+	switch p.Backoff {
+	case "", BackoffFixed, BackoffExponential:
+	default:
+		return fmt.Errorf("retry policy: unknown backoff %q", p.Backoff)
+	}
+	for _, class := range p.RetryOn {
+		switch class {
+		case FailureClassConnection, FailureClassData, FailureClassTimeout, FailureClassUnknown:
+		default:
+			return fmt.Errorf("retry policy: unknown failure class %q", class)
+		}
+	}
+	return nil
+}
+
+// Attempts returns the number of attempts allowed by the policy (at least 1)
+func (p *RetryPolicy) Attempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// ShouldRetry reports whether a run that failed with the given class on the given attempt
+// (1-based) must be retried.
+func (p *RetryPolicy) ShouldRetry(attempt int, class FailureClass) bool {
+	if attempt >= p.Attempts() {
+		return false
+	}
+	if len(p.RetryOn) == 0 {
+		return true
+	}
+	return slices.Contains(p.RetryOn, class)
+}
+
+// Delay returns how long to wait after the given failed attempt (1-based) before the next one,
+// at most MaxDelaySeconds and never more than MaxRetryDelay
+func (p *RetryPolicy) Delay(attempt int) time.Duration {
+	if p == nil || p.DelaySeconds <= 0 {
+		return 0
+	}
+	limit := MaxRetryDelay
+	if p.MaxDelaySeconds > 0 && p.MaxDelaySeconds < int(MaxRetryDelay/time.Second) {
+		limit = time.Duration(p.MaxDelaySeconds) * time.Second
+	}
+	// Policies saved before validation may hold delays that overflow a time.Duration
+	if p.DelaySeconds >= int(limit/time.Second) {
+		return limit
+	}
+
+	delay := time.Duration(p.DelaySeconds) * time.Second
+	if p.Backoff == BackoffExponential {
+		for i := 1; i < attempt && delay < limit; i++ {
+			delay *= 2
+		}
+	}
+	return min(delay, limit)
+}
+
+// JobRunStatus represents the outcome of a single job run attempt
+type JobRunStatus string
+
+const (
+	JobRunStatusRunning   JobRunStatus = "running"
+	JobRunStatusCompleted JobRunStatus = "completed"
+	JobRunStatusFailed    JobRunStatus = "failed"
+	JobRunStatusTimedOut  JobRunStatus = "timed_out"
+	JobRunStatusCancelled JobRunStatus = "cancelled"
+)
+
+// JobRun records each attempt of a job execution
+type JobRun struct {
+	ID        uint  `gorm:"primaryKey" json:"id"`
+	JobID     uint  `gorm:"not null;index" json:"jobId"`
+	TriggerID *uint `json:"triggerId,omitempty"`
+
+	// Attempt number (1-based) and the total allowed by the policy in effect
+	Attempt     int `gorm:"not null" json:"attempt"`
+	MaxAttempts int `gorm:"not null" json:"maxAttempts"`
+
+	Status     JobRunStatus `gorm:"type:varchar(20)" json:"status"`
+	ErrorClass FailureClass `gorm:"type:varchar(20)" json:"errorClass,omitempty"`
+	Error      string       `json:"error,omitempty"`
+
+	StartedAt  time.Time  `gorm:"not null" json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
@@ -1,6 +1,11 @@
 package models
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
 
 type JobVisibility string
 
@@ -26,6 +31,9 @@ type Job struct {
 	Active      bool          `json:"active"`
 	Visibility  JobVisibility `gorm:"default:private"`
 	OutputPath  string        `json:"outputPath"`
+	RetryPolicy *RetryPolicy  `gorm:"type:jsonb" json:"retryPolicy,omitempty"` // nil = no retry
+	MaxRuntime  int           `gorm:"default:0" json:"maxRuntime"`             // max run duration in seconds, 0 = unlimited
+	Parameters  JobParameters `gorm:"type:jsonb" json:"parameters,omitempty"`  // values bound to :name parameters of db_input queries
 	CreatedAt   time.Time     `json:"createdAt"`
 	UpdatedAt   time.Time     `json:"updatedAt"`
 	Nodes       []Node        `gorm:"foreignKey:JobID" json:"nodes,omitempty"`
@@ -37,6 +45,39 @@ type Job struct {
 	NotifyUsers []User `gorm:"many2many:job_notification_contact;" json:"notifyUsers,omitempty"`
 }
 
+// JobParameters maps parameter names to the values bound to them when the job runs
+type JobParameters map[string]string
+
+// Value implements driver.Valuer for GORM
+func (p JobParameters) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return json.Marshal(p)
+}
+
+// Scan implements sql.Scanner for GORM
+func (p *JobParameters) Scan(value interface{}) error {
+	if value == nil {
+		*p = nil
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return errors.New("failed to scan JobParameters: expected []byte")
+	}
+	return json.Unmarshal(bytes, p)
+}
+
+// JobWatermark is the high-water mark an incremental db_input node reached in the last
+// successful run of its job
+type JobWatermark struct {
+	JobID     uint      `gorm:"primaryKey" json:"jobId"`
+	NodeID    int       `gorm:"primaryKey" json:"nodeId"`
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
 // JobUserAccess is the junction table for job-user sharing
 type JobUserAccess struct {
 	JobID     uint      `gorm:"primaryKey"`
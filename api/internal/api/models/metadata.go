@@ -1,15 +1,53 @@
 package models
 
+import (
+	"reflect"
+	"strings"
+)
+
 type MetadataDatabase struct {
 	ID           uint   `json:"id"`
 	Host         string `json:"host"`
 	Port         int    `json:"port"`
 	User         string `json:"user"`
-	Password     string `json:"password"`
+	Password     Secret `json:"password"`
 	DatabaseName string `json:"databaseName"`
 	SSLMode      string `json:"sslMode"`
 	Extra        string `json:"extra"`
 	DbType       DBType `json:"dbName" gorm:"column:db_type;type:varchar(20)"`
+	// Pool and TLS are copied into the connections of the jobs using this database
+	Pool *PoolConfig `json:"pool,omitempty" gorm:"type:jsonb"`
+	TLS  *TLSConfig  `json:"tls,omitempty" gorm:"type:jsonb"`
+}
+
+// ConnectionConfig returns the connection settings of the database
+func (m MetadataDatabase) ConnectionConfig() DBConnectionConfig {
+	return DBConnectionConfig{
+		Type:     m.DbType,
+		Host:     m.Host,
+		Port:     m.Port,
+		Database: m.DatabaseName,
+		Username: m.User,
+		Password: string(m.Password),
+		SSLMode:  m.SSLMode,
+		Pool:     m.Pool,
+		TLS:      m.TLS,
+	}
+}
+
+// Matches reports whether c reaches the same database with the same credentials and settings,
+// so a node can reference m instead of carrying c without changing what it connects to.
+func (m MetadataDatabase) Matches(c DBConnectionConfig) bool {
+	return c.DSN == "" && len(c.Extra) == 0 &&
+		c.Type == m.DbType &&
+		strings.EqualFold(c.Host, m.Host) &&
+		c.Port == m.Port &&
+		c.Database == m.DatabaseName &&
+		c.Username == m.User &&
+		c.Password == string(m.Password) &&
+		c.SSLMode == m.SSLMode &&
+		reflect.DeepEqual(c.Pool, m.Pool) &&
+		reflect.DeepEqual(c.TLS, m.TLS)
 }
 
 type MetadataSftp struct {
@@ -17,8 +55,8 @@ type MetadataSftp struct {
 	Host       string `json:"host"`
 	Port       int    `json:"port"`
 	User       string `json:"user"`
-	Password   string `json:"password"`
-	PrivateKey string `json:"privateKey"`
+	Password   Secret `json:"password"`
+	PrivateKey Secret `json:"privateKey"`
 	BasePath   string `json:"basePath"`
 	Extra      string `json:"extra"`
 }
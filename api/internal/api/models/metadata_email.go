@@ -8,7 +8,7 @@ type MetadataEmail struct {
 	SmtpHost string `json:"smtpHost"`
 	SmtpPort int    `json:"smtpPort" gorm:"default:587"`
 	Username string `json:"username"`
-	Password string `json:"password"`
+	Password Secret `json:"password"`
 	UseTLS   bool   `json:"useTls" gorm:"default:true"`
 	Extra    string `json:"extra"`
 }
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
 )
 
 type DBInputConfig struct {
@@ -17,8 +19,42 @@ type DBInputConfig struct {
 	BatchSize int `json:"batchSize"`
 
 	Connection DBConnectionConfig `json:"connection"`
+	// MetadataDatabaseID references a saved database, resolved into Connection when the job is built
+	MetadataDatabaseID *uint `json:"metadataDatabaseId,omitempty"`
 	// DataModels Give the query result data model with type and col name
 	DataModels []DataModel `json:"dataModels"`
+	// ErrorPolicy applies to rows that fail to scan (default: fail)
+	ErrorPolicy *ErrorPolicy `json:"errorPolicy,omitempty"`
+	// Incremental only reads the rows above the high-water mark of the previous successful run
+	Incremental *IncrementalConfig `json:"incremental,omitempty"`
+	// Parallel splits the read into concurrent queries over partitions of an integer column
+	Parallel *ParallelReadConfig `json:"parallel,omitempty"`
+}
+
+// PartitionStrategy decides which rows each partition of a parallel read returns
+type PartitionStrategy string
+
+const (
+	PartitionRange  PartitionStrategy = "range"  // ranges of equal width between the column min and max
+	PartitionModulo PartitionStrategy = "modulo" // rows whose column value modulo N equals the partition
+)
+
+// ParallelReadConfig describes how a db_input node splits its query into concurrent reads
+type ParallelReadConfig struct {
+	// Column is an integer column, ideally indexed, the partitions are computed on
+	Column string `json:"column"`
+	// Partitions is the number of concurrent queries
+	Partitions int `json:"partitions"`
+	// Strategy defaults to range
+	Strategy PartitionStrategy `json:"strategy,omitempty"`
+}
+
+// IncrementalConfig describes the column an incremental db_input tracks
+type IncrementalConfig struct {
+	// Column increasing with new rows, e.g. an id or an updated_at timestamp
+	Column string `json:"column"`
+	// Type of the column, used to bind the high-water mark
+	Type WatermarkType `json:"type"`
 }
 
 func (slf *DBInputConfig) Validate() error {
@@ -30,6 +66,22 @@ func (slf *DBInputConfig) Validate() error {
 		return errors.New("data model is empty")
 	}
 
+	if slf.Incremental != nil && slf.Incremental.Column == "" {
+		return errors.New("incremental column is empty")
+	}
+
+	if p := slf.Parallel; p != nil {
+		if p.Column == "" {
+			return errors.New("partition column is empty")
+		}
+		if p.Partitions < 1 {
+			return errors.New("partitions must be at least 1")
+		}
+		if p.Strategy != "" && p.Strategy != PartitionRange && p.Strategy != PartitionModulo {
+			return fmt.Errorf("unknown partition strategy %q", p.Strategy)
+		}
+	}
+
 	return nil
 }
 
@@ -38,17 +90,136 @@ func (slf *DBInputConfig) EnforceSchema() {
 		slf.DbSchema = slf.findDefaultSchema()
 	}
 
-	var gotoSchema string
+	slf.QueryWithSchema = fmt.Sprintf(`%s %s`, slf.gotoSchema(), slf.Query)
+}
+
+// gotoSchema returns the statement prefix selecting the schema according to dbtype
+func (slf *DBInputConfig) gotoSchema() string {
 	switch slf.Connection.Type {
 	case DBTypePostgres:
-		gotoSchema = fmt.Sprintf("SET search_path TO %s;", slf.DbSchema)
+		return slf.SchemaStatement() + ";"
 	case DBTypeSQLServer:
-		gotoSchema = fmt.Sprintf("/* %s */", slf.DbSchema)
+		return fmt.Sprintf("/* %s */", slf.DbSchema)
+	case DBTypeMySQL, DBTypeSQLite, DBTypeDuckDB:
+		return fmt.Sprintf("/* %s */", slf.DbSchema)
+	}
+	return ""
+}
+
+// SchemaStatement returns the statement selecting the schema on the connection, empty when the
+// database needs none. Parametrised queries run it on its own, a prepared statement holding a
+// single statement.
+func (slf *DBInputConfig) SchemaStatement() string {
+	if slf.Connection.Type == DBTypePostgres && slf.DbSchema != "" {
+		return fmt.Sprintf("SET search_path TO %s", slf.DbSchema)
+	}
+	return ""
+}
+
+// ResumeQueryParts changes the query so it skips rows already processed by a previous run.
+// The resume value (last key when keyColumn is set, number of rows otherwise) goes between
+// the two returned parts. It is inlined rather than bound because the PostgreSQL schema prefix
+// is a separate statement, which prepared statements do not allow.
+func (slf *DBInputConfig) ResumeQueryParts(keyColumn string) (string, string, error) {
+	before, after, err := slf.WrapResumeQuery(slf.Query, keyColumn)
+	if err != nil {
+		return "", "", err
+	}
+	return slf.gotoSchema() + " " + before, after, nil
+}
+
+// WrapResumeQuery is ResumeQueryParts for a given query, without the schema prefix. Resuming by
+// row offset needs the query to return its rows in the same order on every run, so the query
+// must end with an ORDER BY, on columns telling every row apart, and the offset is added to it.
+func (slf *DBInputConfig) WrapResumeQuery(query, keyColumn string) (string, string, error) {
+	query = strings.TrimSuffix(strings.TrimSpace(query), ";")
+
+	if keyColumn != "" {
+		before := fmt.Sprintf("SELECT * FROM (%s) AS src WHERE %s > ", query, keyColumn)
+		return before, fmt.Sprintf(" ORDER BY %s", keyColumn), nil
+	}
+
+	words := topLevelWords(query)
+	orderBy := -1
+	for i := 0; i+1 < len(words); i++ {
+		if words[i] == "order" && words[i+1] == "by" {
+			orderBy = i
+		}
+	}
+	if orderBy < 0 {
+		return "", "", errors.New("resuming by row offset needs a query ending with an ORDER BY, or a checkpoint key column")
+	}
+	for _, w := range words[orderBy:] {
+		if w == "limit" || w == "offset" || w == "fetch" {
+			return "", "", fmt.Errorf("resuming by row offset cannot add an offset to a query with its own %s", strings.ToUpper(w))
+		}
+	}
+	if slf.Connection.Type == DBTypeSQLServer && slices.Contains(words, "top") {
+		return "", "", errors.New("resuming by row offset cannot add an offset to a query with its own TOP")
+	}
+
+	switch slf.Connection.Type {
+	case DBTypeSQLServer:
+		return query + " OFFSET ", " ROWS", nil
 	case DBTypeMySQL:
-		gotoSchema = fmt.Sprintf("/* %s */", slf.DbSchema)
+		return query + " LIMIT 18446744073709551615 OFFSET ", "", nil
+	case DBTypeSQLite:
+		return query + " LIMIT -1 OFFSET ", "", nil
+	default:
+		return query + " OFFSET ", "", nil
 	}
+}
+
+// topLevelWords returns the lowercased words of a query outside parentheses, quoted strings or
+// identifiers and comments
+func topLevelWords(query string) []string {
+	var words []string
+	depth := 0
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			end := c
+			if c == '[' {
+				end = ']'
+			}
+			j := i + 1
+			for j < len(query) && query[j] != end {
+				j++
+			}
+			i = j
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			j := strings.IndexByte(query[i:], '\n')
+			if j < 0 {
+				return words
+			}
+			i += j
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			j := strings.Index(query[i+2:], "*/")
+			if j < 0 {
+				return words
+			}
+			i += 3 + j
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case isWordByte(c):
+			j := i
+			for j < len(query) && isWordByte(query[j]) {
+				j++
+			}
+			if depth == 0 {
+				words = append(words, strings.ToLower(query[i:j]))
+			}
+			i = j - 1
+		}
+	}
+	return words
+}
 
-	slf.QueryWithSchema = fmt.Sprintf(`%s %s`, gotoSchema, slf.Query)
+func isWordByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
 }
 
 func (slf *DBInputConfig) FillDataModels() error {
@@ -74,6 +245,23 @@ func (slf *DBInputConfig) FillDataModels() error {
 		}
 		defer conn.Close()
 		return slf.findSqlServerDataModels(conn)
+	case DBTypeMySQL:
+		conn, err := sql.Open("mysql", slf.Connection.BuildConnectionString())
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return slf.findLimitZeroDataModels(conn, mysqlGoType)
+	case DBTypeSQLite, DBTypeDuckDB:
+		conn, err := sql.Open(slf.Connection.GetDriverName(), slf.Connection.BuildConnectionString())
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if slf.Connection.Type == DBTypeSQLite {
+			return slf.findLimitZeroDataModels(conn, sqliteGoType)
+		}
+		return slf.findLimitZeroDataModels(conn, duckDBGoType)
 	default:
 		return errors.New("unsupported database type for filling data models")
 	}
@@ -90,6 +278,8 @@ func (slf *DBInputConfig) findDefaultSchema() string {
 		return "dbo"
 	case DBTypeMySQL:
 		return slf.Connection.Database
+	case DBTypeSQLite, DBTypeDuckDB:
+		return "main"
 	default:
 		panic("Unsupported DB type")
 	}
@@ -212,3 +402,156 @@ func (slf *DBInputConfig) findSqlServerDataModels(conn *sql.DB) error {
 
 	return nil
 }
+
+// findLimitZeroDataModels reads the columns of the query with LIMIT 0, mapping the type names
+// reported by the driver with goTypeOf
+func (slf *DBInputConfig) findLimitZeroDataModels(conn *sql.DB, goTypeOf func(dbType string) string) error {
+	query := fmt.Sprintf("SELECT * FROM (%s) AS subquery LIMIT 0", slf.Query)
+
+	rows, err := conn.Query(query)
+	if err != nil {
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return fmt.Errorf("failed to get column types: %w", err)
+	}
+
+	slf.DataModels = make([]DataModel, 0, len(columnTypes))
+
+	for _, col := range columnTypes {
+		dbType := col.DatabaseTypeName()
+		nullable, _ := col.Nullable()
+
+		model := DataModel{
+			Name:     col.Name(),
+			Type:     dbType,
+			GoType:   goTypeOf(dbType),
+			Nullable: nullable,
+		}
+		if length, ok := col.Length(); ok {
+			model.Length = length
+		}
+		if precision, scale, ok := col.DecimalSize(); ok {
+			model.Precision = precision
+			model.Scale = scale
+		}
+		slf.DataModels = append(slf.DataModels, model)
+	}
+
+	return nil
+}
+
+// mysqlGoType maps a MySQL type name, as reported by go-sql-driver/mysql, to a Go type.
+// Unsigned integers are reported as "UNSIGNED INT", "UNSIGNED BIGINT"...
+func mysqlGoType(dbType string) string {
+	switch strings.ToUpper(dbType) {
+	case "TINYINT":
+		return "int8"
+	case "UNSIGNED TINYINT":
+		return "uint8"
+	case "SMALLINT", "YEAR":
+		return "int16"
+	case "UNSIGNED SMALLINT":
+		return "uint16"
+	case "MEDIUMINT", "INT":
+		return "int32"
+	case "UNSIGNED MEDIUMINT", "UNSIGNED INT":
+		return "uint32"
+	case "BIGINT":
+		return "int64"
+	case "UNSIGNED BIGINT":
+		return "uint64"
+	case "FLOAT":
+		return "float32"
+	case "DOUBLE":
+		return "float64"
+	case "DECIMAL", "NUMERIC":
+		// Exact values are read as their decimal text, a float64 would round them
+		return "sql.NullString"
+	case "CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT", "ENUM", "SET", "TIME":
+		return "string"
+	case "DATE", "DATETIME", "TIMESTAMP":
+		return "time.Time"
+	case "JSON":
+		return "json.RawMessage"
+	case "BINARY", "VARBINARY", "TINYBLOB", "BLOB", "MEDIUMBLOB", "LONGBLOB", "BIT", "GEOMETRY":
+		return "[]byte"
+	default:
+		return "string"
+	}
+}
+
+// sqliteGoType maps a declared SQLite column type to a Go type following the SQLite type
+// affinity rules. Expressions have no declared type and are read as strings.
+func sqliteGoType(dbType string) string {
+	t := strings.ToUpper(dbType)
+	switch {
+	case strings.Contains(t, "BOOL"):
+		return "bool"
+	case strings.Contains(t, "INT"):
+		return "int64"
+	case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"):
+		return "string"
+	case strings.Contains(t, "BLOB"):
+		return "[]byte"
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"):
+		return "float64"
+	case strings.Contains(t, "NUMERIC"), strings.Contains(t, "DECIMAL"):
+		// Exact values are read as their decimal text, a float64 would round them
+		return "sql.NullString"
+	case strings.Contains(t, "DATE"), strings.Contains(t, "TIME"):
+		return "time.Time"
+	case strings.Contains(t, "JSON"):
+		return "json.RawMessage"
+	default:
+		return "string"
+	}
+}
+
+// duckDBGoType maps a DuckDB type name, as reported by duckdb-go, to a Go type
+func duckDBGoType(dbType string) string {
+	t := strings.ToUpper(dbType)
+	if i := strings.IndexByte(t, '('); i >= 0 {
+		t = t[:i]
+	}
+	switch t {
+	case "BOOLEAN":
+		return "bool"
+	case "TINYINT":
+		return "int8"
+	case "UTINYINT":
+		return "uint8"
+	case "SMALLINT":
+		return "int16"
+	case "USMALLINT":
+		return "uint16"
+	case "INTEGER":
+		return "int32"
+	case "UINTEGER":
+		return "uint32"
+	case "BIGINT":
+		return "int64"
+	case "UBIGINT":
+		return "uint64"
+	case "FLOAT":
+		return "float32"
+	case "DOUBLE":
+		return "float64"
+	case "DECIMAL", "HUGEINT", "UHUGEINT":
+		// Exact values are read as their decimal text, a float64 would round them
+		return "sql.NullString"
+	case "DATE", "TIMESTAMP", "TIMESTAMP WITH TIME ZONE", "TIMESTAMP_S", "TIMESTAMP_MS", "TIMESTAMP_NS":
+		return "time.Time"
+	case "BLOB":
+		return "[]byte"
+	case "JSON":
+		// The driver decodes JSON values into maps and slices
+		return "interface{}"
+	default:
+		// VARCHAR, UUID, TIME, INTERVAL, ENUM... are read as strings
+		return "string"
+	}
+}
@@ -3,8 +3,11 @@ package models
 import (
 	"database/sql"
 	"errors"
+	"strings"
 
+	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
 type DbOutputMode string
@@ -15,6 +18,20 @@ const (
 	DbOutputModeMerge    DbOutputMode = "merge"
 	DbOutputModeDelete   DbOutputMode = "delete"
 	DbOutputModeTruncate DbOutputMode = "truncate"
+	// DbOutputModeBulk streams rows through the native bulk path of the database
+	// (COPY FROM STDIN, SQL Server bulk copy, LOAD DATA LOCAL INFILE)
+	DbOutputModeBulk DbOutputMode = "bulk"
+	// DbOutputModeSCD2 keeps the history of rows as Slowly Changing Dimension type 2 versions
+	DbOutputModeSCD2 DbOutputMode = "scd2"
+)
+
+// CommitStrategy controls when the batches of a db_output node are committed
+type CommitStrategy string
+
+const (
+	CommitAutocommit CommitStrategy = "autocommit" // each batch is committed on its own (default)
+	CommitSingle     CommitStrategy = "single"     // all batches in one transaction, all-or-nothing
+	CommitBatches    CommitStrategy = "batches"    // a transaction every CommitEvery batches
 )
 
 type DBOutputConfig struct {
@@ -25,6 +42,69 @@ type DBOutputConfig struct {
 	Connection DBConnectionConfig `json:"connection"`
 	DataModels []DataModel        `json:"dataModel"`
 	KeyColumns []string           `json:"keyColumns"`
+	// MetadataDatabaseID references a saved database, resolved into Connection when the job is built
+	MetadataDatabaseID *uint `json:"metadataDatabaseId,omitempty"`
+	// Checkpoint enables per-batch checkpoints (insert and merge modes) so a failed run can be resumed
+	Checkpoint *CheckpointConfig `json:"checkpoint,omitempty"`
+	// ErrorPolicy applies to rows a batch fails to write (default: fail)
+	ErrorPolicy *ErrorPolicy `json:"errorPolicy,omitempty"`
+	// CommitStrategy applies to insert, update, delete and merge modes (default: autocommit)
+	CommitStrategy CommitStrategy `json:"commitStrategy,omitempty"`
+	// CommitEvery is the number of batches per transaction of the batches strategy
+	CommitEvery int `json:"commitEvery,omitempty"`
+	// TruncateBeforeLoad empties the table before an insert or merge, in the same transaction
+	// as the rows unless the strategy is autocommit
+	TruncateBeforeLoad bool `json:"truncateBeforeLoad,omitempty"`
+	// SCD2 configures the scd2 mode, KeyColumns being the business key
+	SCD2 *SCD2Config `json:"scd2,omitempty"`
+	// AutoCreateTable creates the table from the upstream columns when it does not exist
+	AutoCreateTable bool `json:"autoCreateTable,omitempty"`
+	// EvolveSchema adds the upstream columns an existing table is missing
+	EvolveSchema bool `json:"evolveSchema,omitempty"`
+}
+
+// SCD2Config configures the scd2 mode: when a tracked column changes, the current version of the
+// row is closed and a new version is inserted
+type SCD2Config struct {
+	// TrackedColumns are compared with the current version (default: all non-key columns)
+	TrackedColumns  []string `json:"trackedColumns,omitempty"`
+	ValidFromColumn string   `json:"validFromColumn,omitempty"` // default: valid_from
+	ValidToColumn   string   `json:"validToColumn,omitempty"`   // default: valid_to
+	IsCurrentColumn string   `json:"isCurrentColumn,omitempty"` // default: is_current
+}
+
+// WithDefaults returns the configuration with the default column names filled in
+func (slf SCD2Config) WithDefaults() SCD2Config {
+	if slf.ValidFromColumn == "" {
+		slf.ValidFromColumn = "valid_from"
+	}
+	if slf.ValidToColumn == "" {
+		slf.ValidToColumn = "valid_to"
+	}
+	if slf.IsCurrentColumn == "" {
+		slf.IsCurrentColumn = "is_current"
+	}
+	return slf
+}
+
+// ManagesTable reports whether the generated code creates or alters the target table
+func (slf *DBOutputConfig) ManagesTable() bool {
+	if !slf.AutoCreateTable && !slf.EvolveSchema {
+		return false
+	}
+	switch slf.Mode {
+	case DbOutputModeInsert, DbOutputModeMerge, DbOutputModeBulk:
+		return true
+	default:
+		return false
+	}
+}
+
+// CheckpointConfig configures how a db_output node records its progress
+type CheckpointConfig struct {
+	// KeyColumn is a column increasing in source order, used on resume to skip rows already written.
+	// When empty, resume skips as many source rows as were written.
+	KeyColumn string `json:"keyColumn,omitempty"`
 }
 
 func (slf *DBOutputConfig) FillDataModels() error {
@@ -43,6 +123,27 @@ func (slf *DBOutputConfig) FillDataModels() error {
 		}
 		defer conn.Close()
 		return slf.findSqlServerDataModels(conn)
+	case DBTypeMySQL:
+		conn, err := sql.Open("mysql", slf.Connection.BuildConnectionString())
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return slf.findMySQLDataModels(conn)
+	case DBTypeSQLite:
+		conn, err := sql.Open("sqlite", slf.Connection.BuildConnectionString())
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return slf.findSQLiteDataModels(conn)
+	case DBTypeDuckDB:
+		conn, err := sql.Open("duckdb", slf.Connection.BuildConnectionString())
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return slf.findDuckDBDataModels(conn)
 	default:
 		return errors.New("unsupported database type for filling data models")
 	}
@@ -58,11 +159,11 @@ func (slf *DBOutputConfig) findPostgresDataModels(conn *sql.DB) error {
 			COALESCE(numeric_precision, 0),
 			COALESCE(numeric_scale, 0)
 		FROM information_schema.columns
-		WHERE table_name = $1
+		WHERE table_name = $1 AND ($2 = '' OR table_schema = $2)
 		ORDER BY ordinal_position;
 	`
 
-	rows, err := conn.Query(query, slf.Table)
+	rows, err := conn.Query(query, slf.Table, slf.DbSchema)
 	if err != nil {
 		return err
 	}
@@ -118,11 +219,11 @@ func (slf *DBOutputConfig) findSqlServerDataModels(conn *sql.DB) error {
 			COALESCE(NUMERIC_PRECISION, 0),
 			COALESCE(NUMERIC_SCALE, 0)
 		FROM INFORMATION_SCHEMA.COLUMNS
-		WHERE TABLE_NAME = @p1
+		WHERE TABLE_NAME = @p1 AND (@p2 = '' OR TABLE_SCHEMA = @p2)
 		ORDER BY ORDINAL_POSITION;
 	`
 
-	rows, err := conn.Query(query, slf.Table)
+	rows, err := conn.Query(query, slf.Table, slf.DbSchema)
 	if err != nil {
 		return err
 	}
@@ -167,3 +268,168 @@ func (slf *DBOutputConfig) findSqlServerDataModels(conn *sql.DB) error {
 	slf.DataModels = models
 	return rows.Err()
 }
+
+func (slf *DBOutputConfig) findMySQLDataModels(conn *sql.DB) error {
+	// The schema is the database on MySQL and defaults to the one of the connection
+	query := `
+		SELECT
+			COLUMN_NAME,
+			DATA_TYPE,
+			COLUMN_TYPE,
+			IS_NULLABLE,
+			COALESCE(CHARACTER_MAXIMUM_LENGTH, 0),
+			COALESCE(NUMERIC_PRECISION, 0),
+			COALESCE(NUMERIC_SCALE, 0)
+		FROM INFORMATION_SCHEMA.COLUMNS
+		WHERE TABLE_NAME = ? AND TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE())
+		ORDER BY ORDINAL_POSITION;
+	`
+
+	rows, err := conn.Query(query, slf.Table, slf.DbSchema)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var models []DataModel
+
+	for rows.Next() {
+		var (
+			name       string
+			dataType   string
+			columnType string
+			isNullable string
+			length     int64
+			precision  int64
+			scale      int64
+		)
+
+		if err := rows.Scan(
+			&name,
+			&dataType,
+			&columnType,
+			&isNullable,
+			&length,
+			&precision,
+			&scale,
+		); err != nil {
+			return err
+		}
+
+		// DATA_TYPE drops the sign, COLUMN_TYPE keeps it (e.g. "int unsigned")
+		typeName := strings.ToUpper(dataType)
+		if strings.Contains(strings.ToLower(columnType), "unsigned") {
+			typeName = "UNSIGNED " + typeName
+		}
+
+		models = append(models, DataModel{
+			Name:      name,
+			Type:      dataType,
+			GoType:    mysqlGoType(typeName),
+			Nullable:  isNullable == "YES",
+			Length:    length,
+			Precision: precision,
+			Scale:     scale,
+		})
+	}
+
+	slf.DataModels = models
+	return rows.Err()
+}
+
+func (slf *DBOutputConfig) findSQLiteDataModels(conn *sql.DB) error {
+	// SQLite has no information_schema; the schema is the attached database, "main" by default
+	schema := slf.DbSchema
+	if schema == "" {
+		schema = "main"
+	}
+
+	rows, err := conn.Query(`SELECT name, type, "notnull", pk FROM pragma_table_info(?, ?) ORDER BY cid`, slf.Table, schema)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var models []DataModel
+
+	for rows.Next() {
+		var (
+			name     string
+			dataType string
+			notNull  bool
+			pk       int
+		)
+
+		if err := rows.Scan(&name, &dataType, &notNull, &pk); err != nil {
+			return err
+		}
+
+		models = append(models, DataModel{
+			Name:     name,
+			Type:     dataType,
+			GoType:   sqliteGoType(dataType),
+			Nullable: !notNull && pk == 0,
+		})
+	}
+
+	slf.DataModels = models
+	return rows.Err()
+}
+
+func (slf *DBOutputConfig) findDuckDBDataModels(conn *sql.DB) error {
+	query := `
+		SELECT
+			column_name,
+			data_type,
+			is_nullable,
+			COALESCE(character_maximum_length, 0),
+			COALESCE(numeric_precision, 0),
+			COALESCE(numeric_scale, 0)
+		FROM information_schema.columns
+		WHERE table_name = ? AND table_schema = COALESCE(NULLIF(?, ''), current_schema())
+		ORDER BY ordinal_position;
+	`
+
+	rows, err := conn.Query(query, slf.Table, slf.DbSchema)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var models []DataModel
+
+	for rows.Next() {
+		var (
+			name       string
+			dataType   string
+			isNullable string
+			length     int64
+			precision  int64
+			scale      int64
+		)
+
+		if err := rows.Scan(
+			&name,
+			&dataType,
+			&isNullable,
+			&length,
+			&precision,
+			&scale,
+		); err != nil {
+			return err
+		}
+
+		models = append(models, DataModel{
+			Name:      name,
+			Type:      dataType,
+			GoType:    duckDBGoType(dataType),
+			Nullable:  isNullable == "YES",
+			Length:    length,
+			Precision: precision,
+			Scale:     scale,
+		})
+	}
+
+	slf.DataModels = models
+	return rows.Err()
+}
@@ -19,4 +19,7 @@ type EmailOutputConfig struct {
 	Subject string   `json:"subject"`
 	Body    string   `json:"body"`
 	IsHTML  bool     `json:"isHtml"`
+
+	// ErrorPolicy applies to rows whose email cannot be rendered or sent (default: skip)
+	ErrorPolicy *ErrorPolicy `json:"errorPolicy,omitempty"`
 }
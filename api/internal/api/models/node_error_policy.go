@@ -0,0 +1,67 @@
+package models
+
+import "fmt"
+
+// ErrorPolicyMode defines what a node does with a row it fails to process
+type ErrorPolicyMode string
+
+const (
+	ErrorPolicyFail   ErrorPolicyMode = "fail"   // stop the whole pipeline
+	ErrorPolicySkip   ErrorPolicyMode = "skip"   // log the error and drop the row
+	ErrorPolicyReject ErrorPolicyMode = "reject" // log the error and send the row to the node's reject port
+)
+
+// ErrorPolicy configures row-level error handling of db_input, db_output and email_output nodes.
+// Rows sent to the reject port carry the original row plus the error message and the node ID,
+// so they can be written to a quarantine table.
+type ErrorPolicy struct {
+	Mode ErrorPolicyMode `json:"mode"`
+}
+
+// Validate checks the policy mode
+func (slf *ErrorPolicy) Validate() error {
+	switch slf.Mode {
+	case "", ErrorPolicyFail, ErrorPolicySkip, ErrorPolicyReject:
+		return nil
+	default:
+		return fmt.Errorf("unknown error policy mode %q", slf.Mode)
+	}
+}
+
+// GetErrorPolicyMode returns the error policy configured in the node data.
+// Nodes fail on the first error by default, except email_output nodes which skip
+// rows they cannot send.
+func (slf Node) GetErrorPolicyMode() (ErrorPolicyMode, error) {
+	defaultMode := ErrorPolicyFail
+	if slf.Type == NodeTypeEmailOutput {
+		defaultMode = ErrorPolicySkip
+	}
+	if slf.Data == nil {
+		return defaultMode, nil
+	}
+
+	data, err := GetTypedData[struct {
+		ErrorPolicy *ErrorPolicy `json:"errorPolicy"`
+	}](slf)
+	if err != nil {
+		return "", err
+	}
+	if data.ErrorPolicy == nil || data.ErrorPolicy.Mode == "" {
+		return defaultMode, nil
+	}
+	if err := data.ErrorPolicy.Validate(); err != nil {
+		return "", err
+	}
+	return data.ErrorPolicy.Mode, nil
+}
+
+// GetRejectNodeIDs returns IDs of nodes connected to the reject port of this node
+func (slf Node) GetRejectNodeIDs() []int {
+	var ids []int
+	for _, conn := range slf.OutputPort {
+		if conn.Type == PortTypeReject {
+			ids = append(ids, int(conn.ConnectedNodeID))
+		}
+	}
+	return ids
+}
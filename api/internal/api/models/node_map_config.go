@@ -29,6 +29,15 @@ const (
 	JoinTypeUnion JoinType = "union" // Concatenate rows (no key matching)
 )
 
+// ParallelMode defines how rows are spread over the workers of a parallel map
+type ParallelMode string
+
+const (
+	ParallelModeOrdered     ParallelMode = "ordered"     // Results are emitted in input order (default)
+	ParallelModeUnordered   ParallelMode = "unordered"   // Results are emitted as soon as they are transformed
+	ParallelModePartitioned ParallelMode = "partitioned" // Rows with the same key go to the same worker, keeping their relative order
+)
+
 // InputFlow represents an input data stream with its schema
 type InputFlow struct {
 	Name   string      `json:"name"`   // Reference name (e.g., "A", "orders")
@@ -84,6 +93,11 @@ type MapConfig struct {
 	Inputs  []InputFlow  `json:"inputs"`          // Input streams (1 or more)
 	Outputs []OutputFlow `json:"outputs"`         // Output streams (1 or more, each with own port)
 	Join    *JoinConfig  `json:"join,omitempty"`  // How to combine multiple inputs (nil if single input)
+
+	// Parallel execution (single input only)
+	Parallelism   int          `json:"parallelism,omitempty"`   // Number of transform workers (0 or 1 = sequential)
+	ParallelMode  ParallelMode `json:"parallelMode,omitempty"`  // How rows are spread over the workers
+	PartitionKeys []string     `json:"partitionKeys,omitempty"` // Input columns hashed in partitioned mode ("A.customer_id")
 }
 
 // GetInputByName returns an input flow by its reference name
@@ -121,6 +135,11 @@ func (c *MapConfig) HasMultipleInputs() bool {
 	return len(c.Inputs) > 1
 }
 
+// IsParallel returns true if the transform runs on several workers
+func (c *MapConfig) IsParallel() bool {
+	return c.Parallelism > 1
+}
+
 // HasMultipleOutputs returns true if the map node has more than one output
 func (c *MapConfig) HasMultipleOutputs() bool {
 	return len(c.Outputs) > 1
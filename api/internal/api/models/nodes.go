@@ -1,6 +1,7 @@
 package models
 
 import (
+	"api/internal/secret"
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
@@ -9,30 +10,55 @@ import (
 
 type NodeData []byte
 
-// Scan implements sql.Scanner interface
+// Scan implements sql.Scanner interface, credentials are opened (see secret.Fields)
 func (n *NodeData) Scan(value interface{}) error {
-	if value == nil {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
 		*n = nil
 		return nil
-	}
-	switch v := value.(type) {
 	case []byte:
-		*n = v
-		return nil
+		raw = v
 	case string:
-		*n = []byte(v)
-		return nil
+		raw = []byte(v)
 	default:
 		return fmt.Errorf("cannot scan type %T into NodeData", value)
 	}
+	opened, err := secret.OpenJSON(raw)
+	if err != nil {
+		return fmt.Errorf("cannot open node data: %w", err)
+	}
+	*n = opened
+	return nil
 }
 
-// Value implements driver.Valuer interface
+// Value implements driver.Valuer interface, credentials are sealed
 func (n NodeData) Value() (driver.Value, error) {
 	if n == nil {
 		return nil, nil
 	}
-	return []byte(n), nil
+	sealed, err := secret.SealJSON(n)
+	if err != nil {
+		return nil, fmt.Errorf("cannot seal node data: %w", err)
+	}
+	return sealed, nil
+}
+
+// Redacted returns the data with blank credentials, as returned by the API
+func (n NodeData) Redacted() NodeData {
+	if n == nil {
+		return nil
+	}
+	return secret.RedactJSON(n)
+}
+
+// WithStoredSecrets returns the data with the credentials it left blank taken from stored, so
+// data read back from the API can be saved without losing them
+func (n NodeData) WithStoredSecrets(stored NodeData) (NodeData, error) {
+	if n == nil || stored == nil {
+		return n, nil
+	}
+	return secret.MergeJSON(n, stored)
 }
 
 // MarshalJSON implements json.Marshaler - returns raw JSON
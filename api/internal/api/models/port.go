@@ -7,6 +7,8 @@ const (
 	PortTypeOutput     PortType = "output"
 	PortNodeFlowInput  PortType = "node_flow_input"
 	PortNodeFlowOutput PortType = "node_flow_output"
+	// PortTypeReject carries the rows a node failed to process (see ErrorPolicy)
+	PortTypeReject PortType = "reject"
 )
 
 type Port struct {
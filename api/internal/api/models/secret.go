@@ -0,0 +1,37 @@
+package models
+
+import (
+	"api/internal/secret"
+	"database/sql/driver"
+	"fmt"
+)
+
+// Secret is a credential column, sealed when written and opened when read (see internal/secret)
+type Secret string
+
+// Value implements driver.Valuer for GORM
+func (s Secret) Value() (driver.Value, error) {
+	return secret.Seal(string(s))
+}
+
+// Scan implements sql.Scanner for GORM
+func (s *Secret) Scan(value interface{}) error {
+	var raw string
+	switch v := value.(type) {
+	case nil:
+		*s = ""
+		return nil
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	default:
+		return fmt.Errorf("cannot scan type %T into Secret", value)
+	}
+	plain, err := secret.Open(raw)
+	if err != nil {
+		return err
+	}
+	*s = Secret(plain)
+	return nil
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// SessionRevokeReason tells why a session ended before its expiry
+type SessionRevokeReason string
+
+const (
+	SessionLogout      SessionRevokeReason = "logout"
+	SessionRevoked     SessionRevokeReason = "revoked"
+	SessionForceLogout SessionRevokeReason = "force_logout"
+	// SessionTokenReuse is a refresh token presented after it was rotated: it may have been stolen
+	SessionTokenReuse  SessionRevokeReason = "token_reuse"
+	SessionDeactivated SessionRevokeReason = "deactivated"
+)
+
+// Session is a login of a user on a device. Its refresh token is rotated on each refresh: a token
+// carries the generation of the session it was issued for, and only the latest one is accepted.
+// Presenting an older one revokes the session and so the whole chain of tokens.
+type Session struct {
+	ID     uint `gorm:"primaryKey"`
+	UserID uint `gorm:"not null;index"`
+	User   User `gorm:"constraint:OnDelete:CASCADE"`
+	// Generation is the generation of the current refresh token, incremented on each refresh
+	Generation uint   `gorm:"not null;default:1"`
+	UserAgent  string `gorm:"type:varchar(255)"`
+	IP         string `gorm:"type:varchar(64)"`
+
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+	LastUsedAt    time.Time
+	ExpiresAt     time.Time `gorm:"not null"`
+	RevokedAt     *time.Time
+	RevokedReason SessionRevokeReason `gorm:"type:varchar(32)"`
+}
+
+// Active reports whether the session can be used at now
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"api/internal/secret"
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
@@ -75,7 +76,11 @@ type TriggerConfig struct {
 
 // Value implements driver.Valuer for GORM
 func (tc TriggerConfig) Value() (driver.Value, error) {
-	return json.Marshal(tc)
+	raw, err := json.Marshal(tc)
+	if err != nil {
+		return nil, err
+	}
+	return secret.SealJSON(raw)
 }
 
 // Scan implements sql.Scanner for GORM
@@ -87,7 +92,44 @@ func (tc *TriggerConfig) Scan(value interface{}) error {
 	if !ok {
 		return errors.New("failed to scan TriggerConfig: expected []byte")
 	}
-	return json.Unmarshal(bytes, tc)
+	opened, err := secret.OpenJSON(bytes)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(opened, tc)
+}
+
+// Redacted returns a copy of the configuration with blank credentials, as returned by the API
+func (tc TriggerConfig) Redacted() TriggerConfig {
+	raw, err := json.Marshal(tc)
+	if err != nil {
+		return TriggerConfig{}
+	}
+	var redacted TriggerConfig
+	if err := json.Unmarshal(secret.RedactJSON(raw), &redacted); err != nil {
+		return TriggerConfig{}
+	}
+	return redacted
+}
+
+// WithStoredSecrets returns tc with the credentials it left blank taken from stored, so a
+// configuration read back from the API can be saved without losing them
+func (tc TriggerConfig) WithStoredSecrets(stored TriggerConfig) (TriggerConfig, error) {
+	incoming, err := json.Marshal(tc)
+	if err != nil {
+		return tc, err
+	}
+	previous, err := json.Marshal(stored)
+	if err != nil {
+		return tc, err
+	}
+	merged, err := secret.MergeJSON(incoming, previous)
+	if err != nil {
+		return tc, err
+	}
+	var out TriggerConfig
+	err = json.Unmarshal(merged, &out)
+	return out, err
 }
 
 // DatabaseTriggerConfig holds configuration for database polling triggers
@@ -294,6 +336,9 @@ type TriggerJob struct {
 
 	// Optional: pass event data as job input parameters
 	PassEventData bool `json:"passEventData"`
+
+	// Optional: overrides the job's retry policy when run from this trigger
+	RetryPolicy *RetryPolicy `gorm:"type:jsonb" json:"retryPolicy,omitempty"`
 }
 
 // TriggerExecution records each time a trigger fires
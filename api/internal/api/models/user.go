@@ -14,17 +14,24 @@ const (
 )
 
 type User struct {
-	ID           uint           `gorm:"primaryKey"`
-	Email        string         `gorm:"uniqueIndex;not null"`
-	Password     string         `gorm:"not null;column:password"`
-	Prenom       string         `gorm:"not null;column:prenom"`
-	Nom          string         `gorm:"not null;column:nom"`
-	Role         AppRole        `gorm:"type:varchar(50);default:'user';column:role"`
-	Actif        bool           `gorm:"default:true;column:actif"`
-	RefreshToken string         `gorm:"type:text;column:refresh_token"`
-	CreatedAt    time.Time      `gorm:"autoCreateTime;column:created_at"`
-	UpdatedAt    time.Time      `gorm:"autoUpdateTime;column:updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index;column:deleted_at"`
+	ID        uint           `gorm:"primaryKey"`
+	Email     string         `gorm:"uniqueIndex;not null"`
+	Password  string         `gorm:"not null;column:password"`
+	Prenom    string         `gorm:"not null;column:prenom"`
+	Nom       string         `gorm:"not null;column:nom"`
+	Role      AppRole        `gorm:"type:varchar(50);default:'user';column:role"`
+	Actif     bool           `gorm:"default:true;column:actif"`
+	CreatedAt time.Time      `gorm:"autoCreateTime;column:created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime;column:updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index;column:deleted_at"`
+
+	// ServiceAccount users are used by other systems: they cannot log in and authenticate with
+	// personal access tokens only
+	ServiceAccount bool `gorm:"default:false;column:service_account"`
+	// OIDCSubject is the subject of the user at the single sign-on provider, nil for local accounts
+	OIDCSubject *string `gorm:"uniqueIndex;column:oidc_subject"`
+	// LDAPDN is the entry of the user in the LDAP directory, its password is checked there
+	LDAPDN *string `gorm:"uniqueIndex;column:ldap_dn"`
 }
 
 func (User) TableName() string {
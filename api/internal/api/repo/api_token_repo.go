@@ -0,0 +1,55 @@
+package repo
+
+import (
+	"api"
+	"api/internal/api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ApiTokenRepository struct {
+	Db *gorm.DB
+}
+
+func NewApiTokenRepository() *ApiTokenRepository {
+	return &ApiTokenRepository{Db: api.DB}
+}
+
+// FindByHash returns the token with the given hash and its user
+func (slf *ApiTokenRepository) FindByHash(hash string) (models.ApiToken, error) {
+	var token models.ApiToken
+	err := slf.Db.Preload("User").Where("token_hash = ?", hash).First(&token).Error
+	return token, err
+}
+
+// FindByUser returns the tokens of a user, newest first
+func (slf *ApiTokenRepository) FindByUser(userID uint) ([]models.ApiToken, error) {
+	var tokens []models.ApiToken
+	err := slf.Db.Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens).Error
+	return tokens, err
+}
+
+func (slf *ApiTokenRepository) Create(token *models.ApiToken) error {
+	return slf.Db.Omit("User").Create(token).Error
+}
+
+// Revoke revokes a token of a user, reporting whether it was found and still active
+func (slf *ApiTokenRepository) Revoke(userID, id uint) (bool, error) {
+	res := slf.Db.Model(&models.ApiToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Update("revoked_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+// RevokeAllForUser revokes every active token of a user
+func (slf *ApiTokenRepository) RevokeAllForUser(userID uint) error {
+	return slf.Db.Model(&models.ApiToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// TouchLastUsed records when a token was last used
+func (slf *ApiTokenRepository) TouchLastUsed(id uint, at time.Time) error {
+	return slf.Db.Model(&models.ApiToken{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
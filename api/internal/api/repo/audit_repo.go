@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"api"
+	"api/internal/api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditFilter selects audit entries, zero values match everything
+type AuditFilter struct {
+	UserID       *uint
+	Action       models.AuditAction
+	ResourceType models.AuditResource
+	ResourceID   *uint
+	From         *time.Time
+	To           *time.Time
+}
+
+// AuditRepository only inserts and reads: the audit trail is append-only
+type AuditRepository struct {
+	Db *gorm.DB
+}
+
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{Db: api.DB}
+}
+
+func (slf *AuditRepository) Create(entry *models.AuditLog) error {
+	return slf.Db.Create(entry).Error
+}
+
+// Find returns a page of the entries matching filter, newest first, and their total count
+func (slf *AuditRepository) Find(filter AuditFilter, limit, offset int) ([]models.AuditLog, int64, error) {
+	var total int64
+	if err := slf.filtered(filter).Model(&models.AuditLog{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.AuditLog
+	err := slf.filtered(filter).Order("id DESC").Limit(limit).Offset(offset).Find(&entries).Error
+	return entries, total, err
+}
+
+// FindInBatches calls fn with the entries matching filter, oldest first, batchSize at a time
+func (slf *AuditRepository) FindInBatches(filter AuditFilter, batchSize int, fn func([]models.AuditLog) error) error {
+	var batch []models.AuditLog
+	return slf.filtered(filter).FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+func (slf *AuditRepository) filtered(filter AuditFilter) *gorm.DB {
+	query := slf.Db
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != nil {
+		query = query.Where("resource_id = ?", *filter.ResourceID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
@@ -3,8 +3,10 @@ package repo
 import (
 	"api"
 	"api/internal/api/models"
+	"errors"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type JobRepository struct {
@@ -25,3 +27,85 @@ func (slf *JobRepository) FindByID(id uint) (models.Job, error) {
 		First(&job, id).Error
 	return job, err
 }
+
+// FindAccess returns whether a user can access a job and with which role: the owner, everyone
+// for public jobs and the users the job is shared with. Unknown jobs fail with
+// gorm.ErrRecordNotFound.
+func (slf *JobRepository) FindAccess(jobID, userID uint) (bool, models.OwningJob, error) {
+	var job models.Job
+	if err := slf.Db.First(&job, jobID).Error; err != nil {
+		return false, "", err
+	}
+
+	// Owner has full access
+	if job.CreatorID == userID {
+		return true, models.Owner, nil
+	}
+
+	// Public jobs are accessible to all
+	if job.Visibility == models.JobVisibilityPublic {
+		return true, models.Viewer, nil
+	}
+
+	// Check if user has explicit access
+	var access models.JobUserAccess
+	err := slf.Db.Where("job_id = ? AND user_id = ?", jobID, userID).First(&access).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, "", nil
+		}
+		return false, "", err
+	}
+
+	return true, access.Role, nil
+}
+
+// CreateRun records the start of a job run attempt
+func (slf *JobRepository) CreateRun(run *models.JobRun) error {
+	return slf.Db.Create(run).Error
+}
+
+// UpdateRun saves the outcome of a job run attempt
+func (slf *JobRepository) UpdateRun(run *models.JobRun) error {
+	return slf.Db.Save(run).Error
+}
+
+// FindRuns retrieves the most recent run attempts of a job
+func (slf *JobRepository) FindRuns(jobID uint, limit int) ([]models.JobRun, error) {
+	var runs []models.JobRun
+	err := slf.Db.
+		Where("job_id = ?", jobID).
+		Order("started_at DESC, attempt DESC").
+		Limit(limit).
+		Find(&runs).Error
+	return runs, err
+}
+
+// FindWatermarks retrieves the high-water marks of the incremental db_input nodes of a job,
+// keyed by node ID
+func (slf *JobRepository) FindWatermarks(jobID uint) (map[int]string, error) {
+	var rows []models.JobWatermark
+	if err := slf.Db.Where("job_id = ?", jobID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	watermarks := make(map[int]string, len(rows))
+	for _, row := range rows {
+		watermarks[row.NodeID] = row.Value
+	}
+	return watermarks, nil
+}
+
+// SaveWatermarks stores the high-water marks reached by a successful run of a job
+func (slf *JobRepository) SaveWatermarks(jobID uint, watermarks map[int]string) error {
+	if len(watermarks) == 0 {
+		return nil
+	}
+	rows := make([]models.JobWatermark, 0, len(watermarks))
+	for nodeID, value := range watermarks {
+		rows = append(rows, models.JobWatermark{JobID: jobID, NodeID: nodeID, Value: value})
+	}
+	return slf.Db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "job_id"}, {Name: "node_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&rows).Error
+}
@@ -0,0 +1,69 @@
+package repo
+
+import (
+	"api"
+	"database/sql"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SecretRepository struct {
+	Db *gorm.DB
+}
+
+func NewSecretRepository() *SecretRepository {
+	return &SecretRepository{
+		Db: api.DB,
+	}
+}
+
+// RewriteColumn replaces, in a single transaction, the values of table.column for which rewrite
+// reports a change. Values are read as stored, without the sealing done by the models. Missing
+// tables are skipped.
+func (slf *SecretRepository) RewriteColumn(table, column string, rewrite func(string) (string, bool, error)) (int, error) {
+	if !slf.Db.Migrator().HasTable(table) {
+		return 0, nil
+	}
+
+	rewritten := 0
+	err := slf.Db.Transaction(func(tx *gorm.DB) error {
+		rows, err := tx.Table(table).Select("id", column).Clauses(clause.Locking{Strength: "UPDATE"}).Rows()
+		if err != nil {
+			return err
+		}
+		updates := map[int64]string{}
+		for rows.Next() {
+			var id int64
+			var value sql.NullString
+			if err := rows.Scan(&id, &value); err != nil {
+				rows.Close()
+				return err
+			}
+			if !value.Valid {
+				continue
+			}
+			out, changed, err := rewrite(value.String)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			if changed {
+				updates[id] = out
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for id, value := range updates {
+			if err := tx.Table(table).Where("id = ?", id).Update(column, value).Error; err != nil {
+				return err
+			}
+		}
+		rewritten = len(updates)
+		return nil
+	})
+	return rewritten, err
+}
@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"api"
+	"api/internal/api/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct {
+	Db *gorm.DB
+}
+
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{Db: api.DB}
+}
+
+func (slf *SessionRepository) Create(session *models.Session) error {
+	return slf.Db.Omit("User").Create(session).Error
+}
+
+func (slf *SessionRepository) FindByID(id uint) (models.Session, error) {
+	var session models.Session
+	err := slf.Db.First(&session, id).Error
+	return session, err
+}
+
+// FindActiveByUser returns the sessions of a user that are neither revoked nor expired, most
+// recently used first
+func (slf *SessionRepository) FindActiveByUser(userID uint, now time.Time) ([]models.Session, error) {
+	var sessions []models.Session
+	err := slf.Db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_used_at DESC").Find(&sessions).Error
+	return sessions, err
+}
+
+// Rotate moves a session from generation to the next one, reporting false when the session is no
+// longer at that generation (the token was already used) or was revoked
+func (slf *SessionRepository) Rotate(id, generation uint, ip, userAgent string, now, expiresAt time.Time) (bool, error) {
+	res := slf.Db.Model(&models.Session{}).
+		Where("id = ? AND generation = ? AND revoked_at IS NULL", id, generation).
+		Updates(map[string]any{
+			"generation":   generation + 1,
+			"ip":           ip,
+			"user_agent":   userAgent,
+			"last_used_at": now,
+			"expires_at":   expiresAt,
+		})
+	return res.RowsAffected > 0, res.Error
+}
+
+// Revoke revokes a session of a user, reporting whether it was found and still active
+func (slf *SessionRepository) Revoke(userID, id uint, reason models.SessionRevokeReason) (bool, error) {
+	res := slf.Db.Model(&models.Session{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id, userID).
+		Updates(map[string]any{"revoked_at": time.Now(), "revoked_reason": reason})
+	return res.RowsAffected > 0, res.Error
+}
+
+// RevokeAllForUser revokes every active session of a user and returns how many there were
+func (slf *SessionRepository) RevokeAllForUser(userID uint, reason models.SessionRevokeReason) (int64, error) {
+	res := slf.Db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Updates(map[string]any{"revoked_at": time.Now(), "revoked_reason": reason})
+	return res.RowsAffected, res.Error
+}
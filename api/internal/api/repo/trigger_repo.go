@@ -146,6 +146,13 @@ func (slf *TriggerRepository) RemoveJob(triggerID, jobID uint) error {
 		Delete(&models.TriggerJob{}).Error
 }
 
+// FindJobLink retrieves the link between a trigger and a job
+func (slf *TriggerRepository) FindJobLink(triggerID, jobID uint) (models.TriggerJob, error) {
+	var triggerJob models.TriggerJob
+	err := slf.Db.Where("trigger_id = ? AND job_id = ?", triggerID, jobID).First(&triggerJob).Error
+	return triggerJob, err
+}
+
 // UpdateJobLink updates a trigger-job link
 func (slf *TriggerRepository) UpdateJobLink(triggerJob *models.TriggerJob) error {
 	return slf.Db.Save(triggerJob).Error
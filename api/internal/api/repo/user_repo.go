@@ -21,6 +21,27 @@ func (slf *UserRepository) FindByEmail(email string) (models.User, error) {
 	return user, err
 }
 
+// FindByOIDCSubject returns the user linked to a single sign-on subject
+func (slf *UserRepository) FindByOIDCSubject(subject string) (models.User, error) {
+	var user models.User
+	err := slf.Db.Where("oidc_subject = ?", subject).First(&user).Error
+	return user, err
+}
+
+// FindByLDAPDN returns the user linked to a directory entry
+func (slf *UserRepository) FindByLDAPDN(dn string) (models.User, error) {
+	var user models.User
+	err := slf.Db.Where("ldap_dn = ?", dn).First(&user).Error
+	return user, err
+}
+
+// FindLDAPUsers returns the users linked to a directory entry
+func (slf *UserRepository) FindLDAPUsers() ([]models.User, error) {
+	var users []models.User
+	err := slf.Db.Where("ldap_dn IS NOT NULL").Order("id").Find(&users).Error
+	return users, err
+}
+
 func (slf *UserRepository) FindByID(id uint) (models.User, error) {
 	var user models.User
 	err := slf.Db.First(&user, id).Error
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"api"
+	"api/internal/api/handler/mapper"
+	"api/internal/api/handler/request"
+	"api/internal/api/handler/response"
+	"api/internal/api/models"
+	"api/internal/api/repo"
+	"api/pkg"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultTokenExpiryDays applies when a token is created without expiry
+	defaultTokenExpiryDays = 90
+	// lastUsedPrecision bounds how often the last use of a token is written
+	lastUsedPrecision = time.Minute
+)
+
+// ErrInvalidApiToken is returned for unknown, expired and revoked tokens and inactive users
+var ErrInvalidApiToken = errors.New("invalid or expired token")
+
+var serviceAccountName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+type ApiTokenService struct {
+	tokenRepo   *repo.ApiTokenRepository
+	userRepo    *repo.UserRepository
+	logger      zerolog.Logger
+	tokenMapper mapper.ApiTokenMapper
+	userMapper  mapper.UserMapper
+}
+
+func NewApiTokenService() *ApiTokenService {
+	return &ApiTokenService{
+		tokenRepo:   repo.NewApiTokenRepository(),
+		userRepo:    repo.NewUserRepository(),
+		logger:      api.Logger,
+		tokenMapper: mapper.NewApiTokenMapper(),
+		userMapper:  mapper.NewUserMapper(),
+	}
+}
+
+// Create issues a personal access token for a user. The token is only returned here.
+func (slf *ApiTokenService) Create(userID uint, req request.CreateApiToken) (*response.ApiTokenCreated, error) {
+	scopes := make(models.TokenScopes, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !scope.Valid() {
+			return nil, fmt.Errorf("unknown scope %q", scope)
+		}
+		if !scopes.Has(scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultTokenExpiryDays
+	}
+	expiresAt := time.Now().AddDate(0, 0, days)
+
+	token, prefix, hash, err := pkg.GenerateAPIToken()
+	if err != nil {
+		slf.logger.Error().Err(err).Msg("Error generating API token")
+		return nil, err
+	}
+
+	entity := models.ApiToken{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		TokenHash: hash,
+		Scopes:    scopes,
+		ExpiresAt: &expiresAt,
+	}
+	if err := slf.tokenRepo.Create(&entity); err != nil {
+		slf.logger.Error().Err(err).Uint("userId", userID).Msg("Error creating API token")
+		return nil, err
+	}
+
+	slf.logger.Info().Uint("userId", userID).Uint("tokenId", entity.ID).Interface("scopes", scopes).Msg("API token created")
+	return &response.ApiTokenCreated{
+		ApiToken: slf.tokenMapper.ToApiTokenResponse(entity),
+		Token:    token,
+	}, nil
+}
+
+// FindForUser lists the tokens of a user, revoked and expired ones included
+func (slf *ApiTokenService) FindForUser(userID uint) ([]response.ApiToken, error) {
+	tokens, err := slf.tokenRepo.FindByUser(userID)
+	if err != nil {
+		slf.logger.Error().Err(err).Uint("userId", userID).Msg("Error listing API tokens")
+		return nil, err
+	}
+	return slf.tokenMapper.ToApiTokenResponses(tokens), nil
+}
+
+// Revoke revokes a token of a user
+func (slf *ApiTokenService) Revoke(userID, tokenID uint) error {
+	found, err := slf.tokenRepo.Revoke(userID, tokenID)
+	if err != nil {
+		slf.logger.Error().Err(err).Uint("tokenId", tokenID).Msg("Error revoking API token")
+		return err
+	}
+	if !found {
+		return errors.New("token not found")
+	}
+	slf.logger.Info().Uint("userId", userID).Uint("tokenId", tokenID).Msg("API token revoked")
+	return nil
+}
+
+// Authenticate returns the active token matching a bearer token, with its user, and records its use
+func (slf *ApiTokenService) Authenticate(token string) (*models.ApiToken, error) {
+	entity, err := slf.tokenRepo.FindByHash(pkg.HashAPIToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidApiToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !entity.Active(now) || !entity.User.Actif || entity.User.ID == 0 {
+		return nil, ErrInvalidApiToken
+	}
+
+	if entity.LastUsedAt == nil || now.Sub(*entity.LastUsedAt) >= lastUsedPrecision {
+		if err := slf.tokenRepo.TouchLastUsed(entity.ID, now); err != nil {
+			slf.logger.Warn().Err(err).Uint("tokenId", entity.ID).Msg("Error recording API token use")
+		}
+		entity.LastUsedAt = &now
+	}
+	return &entity, nil
+}
+
+// CreateServiceAccount creates a user for another system. It cannot log in: an admin issues its
+// tokens and jobs are shared with it like with any user.
+func (slf *ApiTokenService) CreateServiceAccount(req request.CreateServiceAccount) (response.UserResponseDTO, error) {
+	if !serviceAccountName.MatchString(req.Name) {
+		return response.UserResponseDTO{}, errors.New("service account names use lowercase letters, digits, '-' and '_'")
+	}
+	exists, err := slf.userRepo.ExistsByEmail(req.Name)
+	if err != nil {
+		return response.UserResponseDTO{}, err
+	}
+	if exists {
+		return response.UserResponseDTO{}, errors.New("service account already exists")
+	}
+
+	description := req.Description
+	if description == "" {
+		description = "Service account"
+	}
+	user := models.User{
+		Email:          req.Name,
+		Prenom:         description,
+		Nom:            req.Name,
+		Role:           models.RoleUser,
+		Actif:          true,
+		ServiceAccount: true,
+	}
+	if err := slf.userRepo.Create(&user); err != nil {
+		slf.logger.Error().Err(err).Msg("Error creating service account")
+		return response.UserResponseDTO{}, err
+	}
+
+	slf.logger.Info().Uint("userId", user.ID).Str("name", req.Name).Msg("Service account created")
+	return slf.userMapper.EntityToUserResponse(user), nil
+}
+
+// FindServiceAccounts lists the service accounts
+func (slf *ApiTokenService) FindServiceAccounts() ([]response.UserResponseDTO, error) {
+	var users []models.User
+	if err := slf.userRepo.Db.Where("service_account = ?", true).Order("email").Find(&users).Error; err != nil {
+		return nil, err
+	}
+	result := make([]response.UserResponseDTO, len(users))
+	for i, u := range users {
+		result[i] = slf.userMapper.EntityToUserResponse(u)
+	}
+	return result, nil
+}
+
+// FindServiceAccount returns a service account, failing for regular users
+func (slf *ApiTokenService) FindServiceAccount(id uint) (models.User, error) {
+	user, err := slf.userRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, errors.New("service account not found")
+		}
+		return models.User{}, err
+	}
+	if !user.ServiceAccount {
+		return models.User{}, errors.New("service account not found")
+	}
+	return user, nil
+}
+
+// DisableServiceAccount deactivates a service account and revokes its tokens
+func (slf *ApiTokenService) DisableServiceAccount(id uint) error {
+	user, err := slf.FindServiceAccount(id)
+	if err != nil {
+		return err
+	}
+	return slf.userRepo.Db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).Update("actif", false).Error; err != nil {
+			return err
+		}
+		tokens := repo.ApiTokenRepository{Db: tx}
+		if err := tokens.RevokeAllForUser(user.ID); err != nil {
+			return err
+		}
+		slf.logger.Info().Uint("userId", user.ID).Msg("Service account disabled")
+		return nil
+	})
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"api"
+	"api/internal/api/handler/request"
+	"api/internal/api/models"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupApiTokenTestDB(t *testing.T) {
+	api.InitConfig("../../../.env.test")
+
+	err := api.DB.AutoMigrate(&models.User{}, &models.ApiToken{})
+	require.NoError(t, err, "Failed to migrate token tables")
+}
+
+func TestApiToken_CreateAndAuthenticate(t *testing.T) {
+	setupApiTokenTestDB(t)
+
+	user := createTestUser(t, uniqueEmail())
+	defer cleanupTestUser(t, user.ID)
+
+	service := NewApiTokenService()
+	created, err := service.Create(user.ID, request.CreateApiToken{
+		Name:   "ci",
+		Scopes: []models.TokenScope{models.ScopeJobsExecute, models.ScopeJobsExecute},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, created.Token, created.Prefix)
+	assert.Equal(t, []models.TokenScope{models.ScopeJobsExecute}, created.Scopes)
+	require.NotNil(t, created.ExpiresAt)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 90), *created.ExpiresAt, time.Minute)
+
+	token, err := service.Authenticate(created.Token)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, token.UserID)
+	assert.Equal(t, user.Email, token.User.Email)
+	assert.True(t, token.Scopes.Has(models.ScopeJobsExecute))
+	assert.False(t, token.Scopes.Has(models.ScopeJobsWrite))
+	assert.NotNil(t, token.LastUsedAt)
+
+	// Only the hash is stored
+	var stored models.ApiToken
+	require.NoError(t, api.DB.First(&stored, created.ID).Error)
+	assert.NotEqual(t, created.Token, stored.TokenHash)
+
+	_, err = service.Authenticate(created.Token + "x")
+	assert.ErrorIs(t, err, ErrInvalidApiToken)
+}
+
+func TestApiToken_Create_UnknownScope(t *testing.T) {
+	setupApiTokenTestDB(t)
+
+	service := NewApiTokenService()
+	_, err := service.Create(1, request.CreateApiToken{Name: "ci", Scopes: []models.TokenScope{"jobs:admin"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown scope")
+}
+
+func TestApiToken_Revoke(t *testing.T) {
+	setupApiTokenTestDB(t)
+
+	user := createTestUser(t, uniqueEmail())
+	defer cleanupTestUser(t, user.ID)
+	other := createTestUser(t, uniqueEmail())
+	defer cleanupTestUser(t, other.ID)
+
+	service := NewApiTokenService()
+	created, err := service.Create(user.ID, request.CreateApiToken{Name: "ci", Scopes: []models.TokenScope{models.ScopeJobsRead}})
+	require.NoError(t, err)
+
+	// Users only revoke their own tokens
+	assert.Error(t, service.Revoke(other.ID, created.ID))
+	require.NoError(t, service.Revoke(user.ID, created.ID))
+	assert.Error(t, service.Revoke(user.ID, created.ID), "a token is revoked once")
+
+	_, err = service.Authenticate(created.Token)
+	assert.ErrorIs(t, err, ErrInvalidApiToken)
+
+	tokens, err := service.FindForUser(user.ID)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.NotNil(t, tokens[0].RevokedAt)
+}
+
+func TestApiToken_Expired(t *testing.T) {
+	setupApiTokenTestDB(t)
+
+	user := createTestUser(t, uniqueEmail())
+	defer cleanupTestUser(t, user.ID)
+
+	service := NewApiTokenService()
+	created, err := service.Create(user.ID, request.CreateApiToken{Name: "ci", Scopes: []models.TokenScope{models.ScopeJobsRead}, ExpiresInDays: 1})
+	require.NoError(t, err)
+
+	require.NoError(t, api.DB.Model(&models.ApiToken{}).Where("id = ?", created.ID).Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	_, err = service.Authenticate(created.Token)
+	assert.ErrorIs(t, err, ErrInvalidApiToken)
+}
+
+func TestApiToken_ServiceAccount(t *testing.T) {
+	setupApiTokenTestDB(t)
+
+	service := NewApiTokenService()
+	name := fmt.Sprintf("ci-%d", time.Now().UnixNano())
+	account, err := service.CreateServiceAccount(request.CreateServiceAccount{Name: name})
+	require.NoError(t, err)
+	defer cleanupTestUser(t, account.ID)
+
+	assert.True(t, account.ServiceAccount)
+
+	_, err = service.CreateServiceAccount(request.CreateServiceAccount{Name: name})
+	assert.Error(t, err, "names are unique")
+	_, err = service.CreateServiceAccount(request.CreateServiceAccount{Name: "Not Valid"})
+	assert.Error(t, err)
+
+	// Service accounts cannot log in
+	_, err = NewUserService().Login(request.LoginDTO{Email: name, Password: ""}, SessionClient{})
+	assert.Error(t, err)
+
+	created, err := service.Create(account.ID, request.CreateApiToken{Name: "deploy", Scopes: []models.TokenScope{models.ScopeJobsExecute}})
+	require.NoError(t, err)
+	_, err = service.Authenticate(created.Token)
+	require.NoError(t, err)
+
+	// Disabling the account revokes its tokens
+	require.NoError(t, service.DisableServiceAccount(account.ID))
+	_, err = service.Authenticate(created.Token)
+	assert.ErrorIs(t, err, ErrInvalidApiToken)
+
+	user := createTestUser(t, uniqueEmail())
+	defer cleanupTestUser(t, user.ID)
+	assert.Error(t, service.DisableServiceAccount(user.ID), "regular users are not service accounts")
+}
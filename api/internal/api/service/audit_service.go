@@ -0,0 +1,179 @@
+package service
+
+import (
+	"api"
+	"api/internal/api/handler/request"
+	"api/internal/api/models"
+	"api/internal/api/repo"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// AuditActor is who performed an audited action
+type AuditActor struct {
+	UserID     *uint
+	Email      string
+	ApiTokenID *uint
+	IP         string
+}
+
+// AuditEvent is an audited action. Before and After are marshalled to JSON, they must not hold
+// credentials: pass the API representation of the resource.
+type AuditEvent struct {
+	Action       models.AuditAction
+	ResourceType models.AuditResource
+	ResourceID   uint
+	Before       any
+	After        any
+	Details      map[string]any
+}
+
+// AuditService keeps the audit trail of the changes, executions and logins
+type AuditService struct {
+	auditRepo *repo.AuditRepository
+	logger    zerolog.Logger
+}
+
+func NewAuditService() *AuditService {
+	return &AuditService{
+		auditRepo: repo.NewAuditRepository(),
+		logger:    api.Logger,
+	}
+}
+
+// Record appends an entry to the audit trail. A failure is logged, the audited action stands.
+func (slf *AuditService) Record(actor AuditActor, event AuditEvent) {
+	entry := models.AuditLog{
+		UserID:       actor.UserID,
+		UserEmail:    actor.Email,
+		ApiTokenID:   actor.ApiTokenID,
+		IP:           actor.IP,
+		Action:       event.Action,
+		ResourceType: event.ResourceType,
+		Before:       slf.marshal(event.Before),
+		After:        slf.marshal(event.After),
+	}
+	if event.ResourceID != 0 {
+		entry.ResourceID = &event.ResourceID
+	}
+	if len(event.Details) > 0 {
+		entry.Details = slf.marshal(event.Details)
+	}
+
+	if err := slf.auditRepo.Create(&entry); err != nil {
+		slf.logger.Error().Err(err).Str("action", string(event.Action)).Uint("resourceId", event.ResourceID).Msg("Error recording audit entry")
+	}
+}
+
+func (slf *AuditService) marshal(v any) *string {
+	if v == nil {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		slf.logger.Error().Err(err).Msg("Error marshalling audit state")
+		return nil
+	}
+	// A nil pointer or map
+	if string(raw) == "null" {
+		return nil
+	}
+	s := string(raw)
+	return &s
+}
+
+// Find returns a page of the entries matching query, newest first, and their total count
+func (slf *AuditService) Find(query request.AuditQuery) ([]models.AuditLog, int64, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+	entries, total, err := slf.auditRepo.Find(auditFilter(query), limit, query.Offset)
+	if err != nil {
+		slf.logger.Error().Err(err).Msg("Error finding audit entries")
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// auditCSVHeader are the columns of the CSV export
+var auditCSVHeader = []string{"id", "created_at", "user_id", "user_email", "api_token_id", "ip", "action", "resource_type", "resource_id", "before", "after", "details"}
+
+// ExportCSV writes all the entries matching query to w as CSV, oldest first
+func (slf *AuditService) ExportCSV(query request.AuditQuery, w io.Writer) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(auditCSVHeader); err != nil {
+		return err
+	}
+
+	err := slf.auditRepo.FindInBatches(auditFilter(query), 500, func(entries []models.AuditLog) error {
+		for _, e := range entries {
+			record := []string{
+				strconv.FormatUint(uint64(e.ID), 10),
+				e.CreatedAt.UTC().Format(time.RFC3339),
+				formatOptionalID(e.UserID),
+				csvText(e.UserEmail),
+				formatOptionalID(e.ApiTokenID),
+				csvText(e.IP),
+				string(e.Action),
+				string(e.ResourceType),
+				formatOptionalID(e.ResourceID),
+				stringOrEmpty(e.Before),
+				stringOrEmpty(e.After),
+				stringOrEmpty(e.Details),
+			}
+			if err := out.Write(record); err != nil {
+				return err
+			}
+		}
+		out.Flush()
+		return out.Error()
+	})
+	if err != nil {
+		slf.logger.Error().Err(err).Msg("Error exporting audit entries")
+		return err
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+func auditFilter(query request.AuditQuery) repo.AuditFilter {
+	return repo.AuditFilter{
+		UserID:       query.UserID,
+		Action:       query.Action,
+		ResourceType: query.ResourceType,
+		ResourceID:   query.ResourceID,
+		From:         query.From,
+		To:           query.To,
+	}
+}
+
+// csvText keeps spreadsheets from evaluating a value as a formula (logins of failed attempts are
+// whatever was typed)
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func formatOptionalID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*id), 10)
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"api"
+	"api/internal/api/handler/request"
+	"api/internal/api/models"
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupAuditTestDB(t *testing.T) {
+	api.InitConfig("../../../.env.test")
+
+	err := api.DB.AutoMigrate(&models.AuditLog{})
+	require.NoError(t, err, "Failed to migrate audit table")
+}
+
+func TestAudit_RecordAndFind(t *testing.T) {
+	setupAuditTestDB(t)
+
+	service := NewAuditService()
+	resourceID := uint(time.Now().UnixNano() % 1_000_000_000)
+	userID := uint(42)
+	actor := AuditActor{UserID: &userID, Email: "jane@example.com", IP: "10.0.0.1"}
+
+	service.Record(actor, AuditEvent{
+		Action:       models.AuditJobUpdate,
+		ResourceType: models.AuditResourceJob,
+		ResourceID:   resourceID,
+		Before:       map[string]any{"name": "old"},
+		After:        map[string]any{"name": "new"},
+	})
+	service.Record(actor, AuditEvent{Action: models.AuditJobExecute, ResourceType: models.AuditResourceJob, ResourceID: resourceID})
+
+	entries, total, err := service.Find(request.AuditQuery{ResourceType: models.AuditResourceJob, ResourceID: &resourceID})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+	require.Len(t, entries, 2)
+
+	// Newest first
+	assert.Equal(t, models.AuditJobExecute, entries[0].Action)
+	assert.Nil(t, entries[0].Before)
+	update := entries[1]
+	assert.Equal(t, "10.0.0.1", update.IP)
+	require.NotNil(t, update.UserID)
+	assert.Equal(t, userID, *update.UserID)
+	require.NotNil(t, update.Before)
+	assert.JSONEq(t, `{"name":"old"}`, *update.Before)
+	assert.JSONEq(t, `{"name":"new"}`, *update.After)
+
+	entries, total, err = service.Find(request.AuditQuery{Action: models.AuditJobUpdate, ResourceID: &resourceID, Limit: 1})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	assert.Len(t, entries, 1)
+
+	future := time.Now().Add(time.Hour)
+	_, total, err = service.Find(request.AuditQuery{ResourceID: &resourceID, From: &future})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+}
+
+func TestAudit_ExportCSV(t *testing.T) {
+	setupAuditTestDB(t)
+
+	service := NewAuditService()
+	resourceID := uint(time.Now().UnixNano() % 1_000_000_000)
+	service.Record(AuditActor{Email: "=HYPERLINK(\"http://evil\")", IP: "10.0.0.2"}, AuditEvent{
+		Action:       models.AuditLoginFailed,
+		ResourceType: models.AuditResourceUser,
+		ResourceID:   resourceID,
+		Details:      map[string]any{"reason": "invalid email or password"},
+	})
+
+	var out bytes.Buffer
+	require.NoError(t, service.ExportCSV(request.AuditQuery{ResourceType: models.AuditResourceUser, ResourceID: &resourceID}, &out))
+
+	records, err := csv.NewReader(&out).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, auditCSVHeader, records[0])
+
+	row := records[1]
+	assert.Equal(t, "", row[2], "no user")
+	assert.Equal(t, `'=HYPERLINK("http://evil")`, row[3], "formulas are not evaluated")
+	assert.Equal(t, string(models.AuditLoginFailed), row[6])
+	assert.JSONEq(t, `{"reason":"invalid email or password"}`, row[11])
+}
@@ -4,6 +4,7 @@ import (
 	"api"
 	"api/internal/api/handler/response"
 	"api/internal/api/models"
+	"api/internal/gen"
 	"api/pkg"
 	"database/sql"
 	"fmt"
@@ -12,6 +13,7 @@ import (
 	_ "github.com/denisenkom/go-mssqldb"
 	_ "github.com/go-sql-driver/mysql"
 	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
 )
 
 // TestDatabaseConnection tests if a database connection can be established
@@ -137,6 +139,26 @@ func IntrospectColumns(metadataID *uint, connection *models.DBConnectionConfig,
 	return columns, nil
 }
 
+// PreviewTableDDL returns the statements a db_output node would run to create its target table,
+// or to add the columns the existing table is missing
+func PreviewTableDDL(config models.DBOutputConfig) (response.TableDDLPreviewResponse, error) {
+	existing := config
+	if err := existing.FillDataModels(); err != nil {
+		return response.TableDDLPreviewResponse{}, fmt.Errorf("failed to read table columns: %w", err)
+	}
+
+	names := make([]string, len(existing.DataModels))
+	for i, col := range existing.DataModels {
+		names[i] = col.Name
+	}
+
+	statements := gen.TableDDL(config.Connection.Type, config.DbSchema, config.Table, config.DataModels, config.KeyColumns, names)
+	return response.TableDDLPreviewResponse{
+		TableExists: len(names) > 0,
+		Statements:  statements,
+	}, nil
+}
+
 // getConnectionConfig resolves the connection configuration
 func getConnectionConfig(metadataID *uint, connection *models.DBConnectionConfig) (models.DBConnectionConfig, error) {
 	if connection != nil {
@@ -153,15 +175,7 @@ func getConnectionConfig(metadataID *uint, connection *models.DBConnectionConfig
 		return models.DBConnectionConfig{}, fmt.Errorf("failed to load database metadata: %w", err)
 	}
 
-	return models.DBConnectionConfig{
-		Type:     meta.DbType,
-		Host:     meta.Host,
-		Port:     meta.Port,
-		Database: meta.DatabaseName,
-		Username: meta.User,
-		Password: meta.Password,
-		SSLMode:  meta.SSLMode,
-	}, nil
+	return meta.ConnectionConfig(), nil
 }
 
 // getVersionQuery returns the version query for a database type
@@ -173,6 +187,10 @@ func getVersionQuery(dbType models.DBType) string {
 		return "SELECT version()"
 	case models.DBTypeSQLServer:
 		return "SELECT @@VERSION"
+	case models.DBTypeSQLite:
+		return "SELECT 'SQLite ' || sqlite_version()"
+	case models.DBTypeDuckDB:
+		return "SELECT 'DuckDB ' || version()"
 	default:
 		return ""
 	}
@@ -201,6 +219,19 @@ func getTablesQuery(dbType models.DBType) string {
 			FROM sys.tables
 			WHERE is_ms_shipped = 0
 			ORDER BY table_schema, table_name`
+	case models.DBTypeSQLite:
+		return `
+			SELECT 'main' AS table_schema, name AS table_name
+			FROM sqlite_master
+			WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+			ORDER BY name`
+	case models.DBTypeDuckDB:
+		return `
+			SELECT table_schema, table_name
+			FROM information_schema.tables
+			WHERE table_type = 'BASE TABLE'
+			  AND table_schema NOT IN ('information_schema', 'pg_catalog')
+			ORDER BY table_schema, table_name`
 	default:
 		return ""
 	}
@@ -253,6 +284,29 @@ func getColumnsQuery(dbType models.DBType, tableName string) string {
 			) pk ON c.object_id = pk.object_id AND c.column_id = pk.column_id
 			WHERE tbl.name = '%s'
 			ORDER BY c.column_id`, tableName)
+	case models.DBTypeSQLite:
+		return fmt.Sprintf(`
+			SELECT
+				name AS column_name,
+				type AS data_type,
+				"notnull" = 0 AND pk = 0 AS is_nullable,
+				pk > 0 AS is_primary
+			FROM pragma_table_info('%s')
+			ORDER BY cid`, tableName)
+	case models.DBTypeDuckDB:
+		return fmt.Sprintf(`
+			SELECT
+				c.column_name,
+				c.data_type,
+				c.is_nullable = 'YES' AS is_nullable,
+				COALESCE(list_contains(pk.constraint_column_names, c.column_name), false) AS is_primary
+			FROM information_schema.columns c
+			LEFT JOIN duckdb_constraints() pk
+				ON pk.schema_name = c.table_schema
+				AND pk.table_name = c.table_name
+				AND pk.constraint_type = 'PRIMARY KEY'
+			WHERE c.table_name = '%s'
+			ORDER BY c.ordinal_position`, tableName)
 	default:
 		return ""
 	}
@@ -0,0 +1,7 @@
+//go:build cgo
+
+package service
+
+// The DuckDB driver links the DuckDB C library, builds without cgo get duckdb_driver_nocgo.go.
+// It is registered here rather than in models, which the realtime service imports.
+import _ "github.com/duckdb/duckdb-go/v2"
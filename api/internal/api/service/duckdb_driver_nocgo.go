@@ -0,0 +1,24 @@
+//go:build !cgo
+
+package service
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+)
+
+// errDuckDBNoCgo is returned when opening a DuckDB database from a build without cgo
+var errDuckDBNoCgo = errors.New("duckdb: the API was built without cgo, rebuild it with CGO_ENABLED=1 to use DuckDB")
+
+// duckDBStub stands in for the DuckDB driver, which needs cgo, so DuckDB connections fail with
+// errDuckDBNoCgo instead of an unknown driver
+type duckDBStub struct{}
+
+func (duckDBStub) Open(string) (driver.Conn, error) {
+	return nil, errDuckDBNoCgo
+}
+
+func init() {
+	sql.Register("duckdb", duckDBStub{})
+}
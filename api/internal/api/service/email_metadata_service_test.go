@@ -51,7 +51,7 @@ func TestEmailMetadata_Create(t *testing.T) {
 	assert.Equal(t, "smtp.example.com", created.SmtpHost)
 	assert.Equal(t, 587, created.SmtpPort)
 	assert.Equal(t, "testuser@example.com", created.Username)
-	assert.Equal(t, "testpass", created.Password)
+	assert.Equal(t, models.Secret("testpass"), created.Password)
 	assert.True(t, created.UseTLS)
 }
 
@@ -161,7 +161,7 @@ func TestEmailMetadata_Update(t *testing.T) {
 	assert.Equal(t, "new@example.com", updated.Username)
 	// Unchanged fields
 	assert.Equal(t, "old-smtp.example.com", updated.SmtpHost)
-	assert.Equal(t, "oldpass", updated.Password)
+	assert.Equal(t, models.Secret("oldpass"), updated.Password)
 }
 
 func TestEmailMetadata_Delete(t *testing.T) {
@@ -7,8 +7,13 @@ import (
 	"api/internal/gen"
 	"api/internal/gen/lib"
 	"api/pkg"
+	"context"
 	"errors"
 	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -20,6 +25,50 @@ type JobService struct {
 	logger  zerolog.Logger
 }
 
+// runningJob holds the cancel function of an in-flight execution
+type runningJob struct {
+	cancel context.CancelFunc
+}
+
+// runningJobs holds the in-flight executions of each job, runs of the same job can overlap (a
+// trigger and a manual run). It is shared by every JobService instance so that Stop reaches runs
+// started from the API as well as from triggers.
+var runningJobs = struct {
+	sync.Mutex
+	byJob map[uint]map[*runningJob]bool
+}{byJob: make(map[uint]map[*runningJob]bool)}
+
+// trackRun records an in-flight execution of a job
+func trackRun(jobID uint, run *runningJob) {
+	runningJobs.Lock()
+	defer runningJobs.Unlock()
+	if runningJobs.byJob[jobID] == nil {
+		runningJobs.byJob[jobID] = make(map[*runningJob]bool)
+	}
+	runningJobs.byJob[jobID][run] = true
+}
+
+// untrackRun forgets an execution of a job once it ended
+func untrackRun(jobID uint, run *runningJob) {
+	runningJobs.Lock()
+	defer runningJobs.Unlock()
+	delete(runningJobs.byJob[jobID], run)
+	if len(runningJobs.byJob[jobID]) == 0 {
+		delete(runningJobs.byJob, jobID)
+	}
+}
+
+// runsOf returns the in-flight executions of a job
+func runsOf(jobID uint) []*runningJob {
+	runningJobs.Lock()
+	defer runningJobs.Unlock()
+	runs := make([]*runningJob, 0, len(runningJobs.byJob[jobID]))
+	for run := range runningJobs.byJob[jobID] {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
 func NewJobService() *JobService {
 	return &JobService{
 		jobRepo: repo.NewJobRepository(),
@@ -114,15 +163,17 @@ func (slf *JobService) UpdateWithNodes(id uint, patch map[string]any, nodes []mo
 		}
 	}
 
-	// Get existing node IDs for this job
-	var existingIDs []int
-	if err := tx.Model(&models.Node{}).Where("job_id = ?", id).Pluck("id", &existingIDs).Error; err != nil {
+	// Get existing nodes of this job, their data holds the credentials the API does not return
+	var existing []models.Node
+	if err := tx.Select("id", "data").Where("job_id = ?", id).Find(&existing).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
-	existingSet := make(map[int]bool, len(existingIDs))
-	for _, nid := range existingIDs {
-		existingSet[nid] = true
+	existingIDs := make([]int, len(existing))
+	existingData := make(map[int]models.NodeData, len(existing))
+	for i, n := range existing {
+		existingIDs[i] = n.ID
+		existingData[n.ID] = n.Data
 	}
 
 	// Save original state per node (ports + original ID)
@@ -136,7 +187,7 @@ func (slf *JobService) UpdateWithNodes(id uint, patch map[string]any, nodes []mo
 	keepIDs := make(map[int]bool)
 
 	for i := range nodes {
-		isExisting := nodes[i].ID > 0 && existingSet[nodes[i].ID]
+		stored, isExisting := existingData[nodes[i].ID]
 		states[i] = nodeState{
 			originalID: nodes[i].ID,
 			input:      nodes[i].InputPort,
@@ -148,6 +199,12 @@ func (slf *JobService) UpdateWithNodes(id uint, patch map[string]any, nodes []mo
 		nodes[i].JobID = id
 		if isExisting {
 			keepIDs[nodes[i].ID] = true
+			data, err := nodes[i].Data.WithStoredSecrets(stored)
+			if err != nil {
+				tx.Rollback()
+				return nil, fmt.Errorf("invalid data for node %d: %w", nodes[i].ID, err)
+			}
+			nodes[i].Data = data
 		}
 	}
 
@@ -270,35 +327,11 @@ func (slf *JobService) Delete(id uint) error {
 
 // CanUserAccess checks if a user can access a job
 func (slf *JobService) CanUserAccess(jobID, userID uint) (bool, models.OwningJob, error) {
-	var job models.Job
-	if err := slf.jobRepo.Db.First(&job, jobID).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return false, "", errors.New("job not found")
-		}
-		return false, "", err
+	canAccess, role, err := slf.jobRepo.FindAccess(jobID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, "", errors.New("job not found")
 	}
-
-	// Owner has full access
-	if job.CreatorID == userID {
-		return true, models.Owner, nil
-	}
-
-	// Public jobs are accessible to all
-	if job.Visibility == models.JobVisibilityPublic {
-		return true, models.Viewer, nil
-	}
-
-	// Check if user has explicit access
-	var access models.JobUserAccess
-	err := slf.jobRepo.Db.Where("job_id = ? AND user_id = ?", jobID, userID).First(&access).Error
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return false, "", nil
-		}
-		return false, "", err
-	}
-
-	return true, access.Role, nil
+	return canAccess, role, err
 }
 
 // ShareJob shares a job with users
@@ -429,17 +462,104 @@ func (slf *JobService) FindByIDWithAccess(id uint) (*models.Job, []models.JobUse
 	return &job, accessList, nil
 }
 
+// Execute runs a job using its own retry policy
 func (slf *JobService) Execute(id uint) error {
+	return slf.ExecuteWithPolicy(id, nil, nil)
+}
+
+// Resume re-runs a job from the checkpoints left by its last failed run: checkpointed
+// db_output nodes skip the rows they already committed.
+func (slf *JobService) Resume(id uint) error {
+	return slf.execute(id, nil, nil, true)
+}
+
+// ExecuteWithPolicy runs a job and retries failed attempts according to policy
+// (the job's own policy when nil). Each attempt is recorded as a JobRun; the frontend
+// and notification contacts are only told about the outcome of the final attempt.
+func (slf *JobService) ExecuteWithPolicy(id uint, policy *models.RetryPolicy, triggerID *uint) error {
+	return slf.execute(id, policy, triggerID, false)
+}
+
+// execute runs the attempts of a job. Retries always resume from the checkpoints
+// written by the previous attempt.
+func (slf *JobService) execute(id uint, policy *models.RetryPolicy, triggerID *uint, resume bool) error {
 	job, err := slf.jobRepo.FindByID(id)
 	if err != nil {
 		return err
 	}
-	executer := gen.NewJobExecution(&job)
-	err = executer.Run()
+	if policy == nil {
+		policy = job.RetryPolicy
+	}
+	maxAttempts := policy.Attempts()
+
+	// Incremental db_input nodes read above the marks of the last successful run
+	watermarks, err := slf.jobRepo.FindWatermarks(id)
+	if err != nil {
+		return fmt.Errorf("failed to load watermarks: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	current := &runningJob{cancel: cancel}
+	trackRun(id, current)
+	defer func() {
+		untrackRun(id, current)
+		cancel()
+	}()
+
+	var executer *gen.JobExecution
+	attempt := 1
+	for ; ; attempt++ {
+		run := models.JobRun{
+			JobID:       id,
+			TriggerID:   triggerID,
+			Attempt:     attempt,
+			MaxAttempts: maxAttempts,
+			Status:      models.JobRunStatusRunning,
+			StartedAt:   time.Now(),
+		}
+		if createErr := slf.jobRepo.CreateRun(&run); createErr != nil {
+			slf.logger.Error().Err(createErr).Uint("jobId", id).Msg("Error recording job run")
+		}
+
+		executer = gen.NewJobExecution(&job)
+		executer.FindDatabase = slf.findDatabase
+		executer.Resume = resume || attempt > 1
+		executer.Watermarks = watermarks
+		err = executer.RunContext(ctx)
+		slf.finishRun(&run, err, executer.Logs)
+
+		if err == nil || errors.Is(err, gen.ErrCancelled) || !policy.ShouldRetry(attempt, run.ErrorClass) {
+			break
+		}
 
+		delay := policy.Delay(attempt)
+		slf.logger.Warn().Err(err).
+			Uint("jobId", id).
+			Int("attempt", attempt).
+			Int("maxAttempts", maxAttempts).
+			Str("errorClass", string(run.ErrorClass)).
+			Dur("delay", delay).
+			Msg("Job run failed, retrying")
+
+		select {
+		case <-ctx.Done():
+			err = fmt.Errorf("%w while waiting to retry: %v", gen.ErrCancelled, err)
+		case <-time.After(delay):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	if err != nil && attempt > 1 {
+		err = fmt.Errorf("failed after %d attempts: %w", attempt, err)
+	}
+	if err == nil {
+		if saveErr := slf.jobRepo.SaveWatermarks(id, lib.ParseWatermarkReports(executer.Logs)); saveErr != nil {
+			slf.logger.Error().Err(saveErr).Uint("jobId", id).Msg("Error saving watermarks")
+		}
+	}
 	slf.logger.Info().Msgf("%v", err)
-	//slf.logger.Info().Msgf("steps: %v", executer.Steps)
-	//slf.logger.Info().Msgf("context: %v", executer.Context)
 
 	// Notify frontend via NATS that the job is done
 	slf.notifyJobDone(id, err, executer.Logs, executer.Stats)
@@ -447,6 +567,123 @@ func (slf *JobService) Execute(id uint) error {
 	return err
 }
 
+// finishRun stores the outcome of a job run attempt
+func (slf *JobService) finishRun(run *models.JobRun, runErr error, logs string) {
+	now := time.Now()
+	run.FinishedAt = &now
+	switch {
+	case errors.Is(runErr, gen.ErrTimedOut):
+		run.Status = models.JobRunStatusTimedOut
+		run.Error = runErr.Error()
+		run.ErrorClass = models.FailureClassTimeout
+	case errors.Is(runErr, gen.ErrCancelled):
+		run.Status = models.JobRunStatusCancelled
+		run.Error = runErr.Error()
+	case runErr != nil:
+		run.Status = models.JobRunStatusFailed
+		run.Error = runErr.Error()
+		run.ErrorClass = classifyFailure(runErr, logs)
+	default:
+		run.Status = models.JobRunStatusCompleted
+	}
+	if run.ID == 0 {
+		return
+	}
+	if err := slf.jobRepo.UpdateRun(run); err != nil {
+		slf.logger.Error().Err(err).Uint("jobId", run.JobID).Msg("Error updating job run")
+	}
+}
+
+// FindRuns retrieves the most recent run attempts of a job
+func (slf *JobService) FindRuns(jobID uint, limit int) ([]models.JobRun, error) {
+	runs, err := slf.jobRepo.FindRuns(jobID, limit)
+	if err != nil {
+		slf.logger.Error().Err(err).Uint("jobId", jobID).Msg("Error getting job runs")
+		return nil, err
+	}
+	return runs, nil
+}
+
+// runExitPattern matches the line the generated program exits with, holding the error that ended it
+var runExitPattern = regexp.MustCompile(`execution (failed|timed out after \d+s): (.*)`)
+
+// sqlStatePattern matches the SQLSTATE of a MySQL error: Error 1062 (23000): Duplicate entry
+var sqlStatePattern = regexp.MustCompile(`\berror \d+ \(([0-9a-z]{5})\)`)
+
+// connectionFailurePatterns match the final error of a run that could not reach its databases.
+// Network errors end the error chain, so most are anchored at its end.
+var connectionFailurePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bdial tcp \S+: `),
+	regexp.MustCompile(`(connection refused|connection reset by peer|broken pipe|i/o timeout|no such host|network is unreachable|context deadline exceeded)$`),
+	regexp.MustCompile(`\bdriver: bad connection$`),
+	regexp.MustCompile(`\bcannot reach database \S+: no answer after \d+s: `),
+	regexp.MustCompile(`\bpq: sorry, too many clients already$`),
+	regexp.MustCompile(`\bpq: the database system is (starting up|shutting down)$`),
+}
+
+// dataFailurePatterns match the final error of a run that failed on the data itself, with the
+// wording of the drivers
+var dataFailurePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\bpq: duplicate key value violates unique constraint\b`),
+	regexp.MustCompile(`\bpq: (insert or update on table|update or delete on table) .* violates foreign key constraint\b`),
+	regexp.MustCompile(`\bpq: null value in column .* violates not-null constraint\b`),
+	regexp.MustCompile(`\bpq: new row for relation .* violates check constraint\b`),
+	regexp.MustCompile(`\bpq: (invalid input syntax for|value too long for type|numeric field overflow|\w+ out of range)\b`),
+	regexp.MustCompile(`\bpq: syntax error at or near\b`),
+	regexp.MustCompile(`\bmssql: (violation of|cannot insert the value null|conversion failed when converting|arithmetic overflow|string or binary data would be truncated|incorrect syntax near)\b`),
+	regexp.MustCompile(`\bconstraint failed: \w+ constraint failed\b`),
+	regexp.MustCompile(`\bsql: scan error on column\b`),
+	regexp.MustCompile(`\bconverting driver\.value type \S+ \(.*\) to a \S+: `),
+}
+
+// classifyFailure finds the failure class of a job run from its error, or from the error the
+// generated program exited with (the last one in its logs). Timeouts are recognised by their
+// error, the other failures by the SQLSTATE or the driver message of the final error.
+func classifyFailure(runErr error, logs string) models.FailureClass {
+	if runErr == nil {
+		return ""
+	}
+	if errors.Is(runErr, gen.ErrTimedOut) {
+		return models.FailureClassTimeout
+	}
+	var netErr net.Error
+	if errors.As(runErr, &netErr) {
+		return models.FailureClassConnection
+	}
+
+	final := runErr.Error()
+	if exits := runExitPattern.FindAllStringSubmatch(logs, -1); len(exits) > 0 {
+		exit := exits[len(exits)-1]
+		if exit[1] != "failed" {
+			return models.FailureClassTimeout
+		}
+		final = exit[2]
+	}
+	final = strings.ToLower(strings.TrimSpace(final))
+
+	if m := sqlStatePattern.FindStringSubmatch(final); m != nil {
+		switch m[1][:2] {
+		case "08", "53", "57":
+			// Connection exception, insufficient resources, operator intervention
+			return models.FailureClassConnection
+		case "22", "23", "42":
+			// Data exception, integrity constraint violation, syntax error
+			return models.FailureClassData
+		}
+	}
+	for _, p := range connectionFailurePatterns {
+		if p.MatchString(final) {
+			return models.FailureClassConnection
+		}
+	}
+	for _, p := range dataFailurePatterns {
+		if p.MatchString(final) {
+			return models.FailureClassData
+		}
+	}
+	return models.FailureClassUnknown
+}
+
 // notifyJobDone publishes a final progress message (nodeId=0) so the frontend knows the job ended.
 // On failure, sends email notifications to configured contacts.
 func (slf *JobService) notifyJobDone(jobID uint, jobErr error, logs string, stats gen.DockerStats) {
@@ -459,7 +696,10 @@ func (slf *JobService) notifyJobDone(jobID uint, jobErr error, logs string, stat
 	progress := reporter.ReportFunc()
 	if jobErr != nil {
 		progress(lib.NewProgress(0, "Pipeline", lib.StatusFailed, 0, jobErr.Error()))
-		slf.sendFailureEmails(jobID, jobErr, logs, stats)
+		// A run stopped on purpose is not a failure worth an email
+		if !errors.Is(jobErr, gen.ErrCancelled) {
+			slf.sendFailureEmails(jobID, jobErr, logs, stats)
+		}
 	} else {
 		progress(lib.NewProgress(0, "Pipeline", lib.StatusCompleted, 0, "Pipeline completed successfully"))
 	}
@@ -534,7 +774,16 @@ func (slf *JobService) sendFailureEmails(jobID uint, jobErr error, logs string,
 	}
 }
 
+// Stop cancels every in-flight execution of a job, including pending retries.
+// When no run is tracked by this process, the job containers are stopped directly.
 func (slf *JobService) Stop(id uint) error {
+	if runs := runsOf(id); len(runs) > 0 {
+		slf.logger.Info().Uint("jobId", id).Int("runs", len(runs)).Msg("Cancelling job runs")
+		for _, run := range runs {
+			run.cancel()
+		}
+		return nil
+	}
 	return gen.DockerStop(id, slf.logger)
 }
 
@@ -544,5 +793,15 @@ func (slf *JobService) PrintCode(id uint) (string, any, error) {
 		return "", nil, err
 	}
 	executer := gen.NewJobExecution(&job)
+	executer.FindDatabase = slf.findDatabase
 	return executer.LogDebug()
 }
+
+// findDatabase loads a saved database referenced by the db nodes of a job
+func (slf *JobService) findDatabase(id uint) (*models.MetadataDatabase, error) {
+	var meta models.MetadataDatabase
+	if err := slf.jobRepo.Db.First(&meta, id).Error; err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
@@ -3,7 +3,11 @@ package service
 import (
 	"api"
 	"api/internal/api/models"
+	"api/internal/gen"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -528,3 +532,83 @@ func TestJob_GetJobAccess(t *testing.T) {
 	assert.Equal(t, u1.ID, accessList[0].UserID)
 	assert.Equal(t, models.Editor, accessList[0].Role)
 }
+
+// ============ Retry Policy Tests ============
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		logs string
+		want models.FailureClass
+	}{
+		{"nil error", nil, "", ""},
+		{"connection refused", errors.New("dial tcp 127.0.0.1:5432: connect: connection refused"), "", models.FailureClassConnection},
+		{"timeout in logs", errors.New("exit status 1"), "2026/01/02 10:00:00 execution failed: read tcp 10.0.0.1:5432: i/o timeout", models.FailureClassConnection},
+		{"duplicate key", errors.New("exit status 1"), `2026/01/02 10:00:00 execution failed: pq: duplicate key value violates unique constraint "pk"`, models.FailureClassData},
+		{"invalid input", errors.New("pq: invalid input syntax for type integer"), "", models.FailureClassData},
+		{"mysql sqlstate", errors.New("exit status 1"), "execution failed: Error 1062 (23000): Duplicate entry '1' for key 'PRIMARY'", models.FailureClassData},
+		{"program timed out", errors.New("exit status 1"), "execution timed out after 30s: context deadline exceeded", models.FailureClassTimeout},
+		{"max runtime", fmt.Errorf("%w after 30s", gen.ErrTimedOut), "", models.FailureClassTimeout},
+		{"syntax error mentioning timeout", errors.New("exit status 1"), `execution failed: pq: syntax error at or near "timeout"`, models.FailureClassData},
+		{"timeout earlier in logs", errors.New("exit status 1"), "row 3: connection timeout column is empty\nexecution failed: node 2: unsupported type", models.FailureClassUnknown},
+		{"unknown", errors.New("job has no nodes"), "", models.FailureClassUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, classifyFailure(tt.err, tt.logs))
+		})
+	}
+}
+
+func TestRetryPolicy_ShouldRetry(t *testing.T) {
+	var noPolicy *models.RetryPolicy
+	assert.Equal(t, 1, noPolicy.Attempts())
+	assert.False(t, noPolicy.ShouldRetry(1, models.FailureClassConnection))
+
+	policy := &models.RetryPolicy{
+		MaxAttempts: 3,
+		RetryOn:     []models.FailureClass{models.FailureClassConnection},
+	}
+	assert.True(t, policy.ShouldRetry(1, models.FailureClassConnection))
+	assert.True(t, policy.ShouldRetry(2, models.FailureClassConnection))
+	assert.False(t, policy.ShouldRetry(3, models.FailureClassConnection), "last attempt must not retry")
+	assert.False(t, policy.ShouldRetry(1, models.FailureClassData), "data errors are not retried")
+
+	anyClass := &models.RetryPolicy{MaxAttempts: 2}
+	assert.True(t, anyClass.ShouldRetry(1, models.FailureClassUnknown))
+}
+
+func TestRetryPolicy_Delay(t *testing.T) {
+	fixed := &models.RetryPolicy{MaxAttempts: 5, Backoff: models.BackoffFixed, DelaySeconds: 10}
+	assert.Equal(t, 10*time.Second, fixed.Delay(1))
+	assert.Equal(t, 10*time.Second, fixed.Delay(4))
+
+	exp := &models.RetryPolicy{MaxAttempts: 5, Backoff: models.BackoffExponential, DelaySeconds: 10, MaxDelaySeconds: 60}
+	assert.Equal(t, 10*time.Second, exp.Delay(1))
+	assert.Equal(t, 20*time.Second, exp.Delay(2))
+	assert.Equal(t, 40*time.Second, exp.Delay(3))
+	assert.Equal(t, 60*time.Second, exp.Delay(4), "capped by MaxDelaySeconds")
+
+	unbounded := &models.RetryPolicy{MaxAttempts: 5, Backoff: models.BackoffExponential, DelaySeconds: 10}
+	assert.Equal(t, models.MaxRetryDelay, unbounded.Delay(40), "doubling must not overflow")
+	huge := &models.RetryPolicy{MaxAttempts: 2, DelaySeconds: 1 << 40}
+	assert.Equal(t, models.MaxRetryDelay, huge.Delay(1))
+}
+
+func TestRetryPolicy_Validate(t *testing.T) {
+	assert.NoError(t, (&models.RetryPolicy{MaxAttempts: 3, Backoff: models.BackoffExponential, DelaySeconds: 10, MaxDelaySeconds: 600}).Validate())
+
+	invalid := []models.RetryPolicy{
+		{MaxAttempts: -1},
+		{MaxAttempts: models.MaxRetryAttempts + 1},
+		{MaxAttempts: 3, DelaySeconds: -5},
+		{MaxAttempts: 3, DelaySeconds: 10, MaxDelaySeconds: 1 << 30},
+		{MaxAttempts: 3, Backoff: "linear"},
+		{MaxAttempts: 3, RetryOn: []models.FailureClass{"disk"}},
+	}
+	for _, policy := range invalid {
+		assert.Error(t, policy.Validate(), "%+v", policy)
+	}
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"api"
+	"api/internal/api/handler/response"
+	"api/internal/api/models"
+	"api/internal/api/repo"
+	"api/internal/directory"
+	"errors"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// ldapDirectory is the part of directory.Directory used here, replaced in tests
+type ldapDirectory interface {
+	Authenticate(username, password string) (*directory.Entry, error)
+	Lookup(dn string) (*directory.Entry, error)
+}
+
+// LDAPService checks passwords against an LDAP / Active Directory server, as an alternative to the
+// local password in UserService.Login. Users are linked to their directory entry on first login.
+type LDAPService struct {
+	config      api.AppConfig
+	logger      zerolog.Logger
+	userRepo    *repo.UserRepository
+	sessionRepo *repo.SessionRepository
+	directory   ldapDirectory
+}
+
+func NewLDAPService() *LDAPService {
+	config := api.GetConfig()
+	cfg := config.LDAPConfig
+	return &LDAPService{
+		config:      config,
+		logger:      api.Logger,
+		userRepo:    repo.NewUserRepository(),
+		sessionRepo: repo.NewSessionRepository(),
+		directory: directory.New(directory.Config{
+			URL:                cfg.URL,
+			StartTLS:           cfg.StartTLS,
+			InsecureSkipVerify: cfg.InsecureSkipVerify,
+			BindDN:             cfg.BindDN,
+			BindPassword:       cfg.BindPassword,
+			UserBaseDN:         cfg.UserBaseDN,
+			UserFilter:         cfg.UserFilter,
+			EmailAttribute:     cfg.EmailAttribute,
+			FirstNameAttribute: cfg.FirstNameAttribute,
+			LastNameAttribute:  cfg.LastNameAttribute,
+			GroupAttribute:     cfg.GroupAttribute,
+		}),
+	}
+}
+
+// Enabled reports whether the LDAP password check is configured
+func (slf *LDAPService) Enabled() bool {
+	return slf.config.LDAPConfig.URL != ""
+}
+
+// Authenticate checks the password of login (uid, sAMAccountName, mail... depending on the user
+// filter) in the directory and returns the linked user
+func (slf *LDAPService) Authenticate(login, password string) (models.User, error) {
+	entry, err := slf.directory.Authenticate(login, password)
+	if errors.Is(err, directory.ErrInvalidCredentials) {
+		return models.User{}, errors.New("invalid email or password")
+	}
+	if err != nil {
+		slf.logger.Error().Err(err).Msg("Error authenticating against the LDAP directory")
+		return models.User{}, errors.New("the directory is unavailable")
+	}
+
+	user, err := slf.linkedUser(entry)
+	if err != nil {
+		return models.User{}, err
+	}
+	if user.ServiceAccount {
+		return models.User{}, errors.New("invalid email or password")
+	}
+	if !user.Actif {
+		return models.User{}, errors.New("account is inactive")
+	}
+
+	if slf.applyEntry(&user, entry) {
+		if err := slf.userRepo.Update(&user); err != nil {
+			slf.logger.Error().Err(err).Uint("userId", user.ID).Msg("Error syncing LDAP user")
+			return models.User{}, err
+		}
+	}
+	return user, nil
+}
+
+// linkedUser returns the user of a directory entry. On first login the entry is linked to the
+// user with the same email when LDAP_LINK_EXISTING is on and that user is not an admin, or a
+// user is created when LDAP_SYNC_USERS is on.
+func (slf *LDAPService) linkedUser(entry *directory.Entry) (models.User, error) {
+	user, err := slf.userRepo.FindByLDAPDN(entry.DN)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		slf.logger.Error().Err(err).Msg("Error finding LDAP user")
+		return models.User{}, err
+	}
+
+	if entry.Email == "" {
+		slf.logger.Warn().Str("dn", entry.DN).Msg("LDAP entry has no email")
+		return models.User{}, errors.New("the directory entry has no email")
+	}
+
+	user, err = slf.userRepo.FindByEmail(entry.Email)
+	switch {
+	case err == nil:
+		if user.ServiceAccount {
+			return models.User{}, errors.New("invalid email or password")
+		}
+		// Whoever controls the directory entry would take over the account
+		if !slf.config.LDAPConfig.LinkExisting || user.Role == models.RoleAdmin {
+			slf.logger.Warn().Uint("userId", user.ID).Str("dn", entry.DN).Msg("LDAP entry matches a local account that cannot be linked")
+			return models.User{}, errors.New("an account with this email exists and is not linked to the directory")
+		}
+		user.LDAPDN = &entry.DN
+		if err := slf.userRepo.Update(&user); err != nil {
+			slf.logger.Error().Err(err).Uint("userId", user.ID).Msg("Error linking LDAP user")
+			return models.User{}, err
+		}
+		slf.logger.Info().Uint("userId", user.ID).Str("dn", entry.DN).Msg("User linked to the LDAP directory")
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if !slf.config.LDAPConfig.SyncUsers {
+			return models.User{}, errors.New("no account exists for this directory user")
+		}
+		user = models.User{Email: entry.Email, Role: models.RoleUser, Actif: true, LDAPDN: &entry.DN}
+		slf.applyEntry(&user, entry)
+		if err := slf.userRepo.Create(&user); err != nil {
+			slf.logger.Error().Err(err).Msg("Error provisioning LDAP user")
+			return models.User{}, err
+		}
+		slf.logger.Info().Uint("userId", user.ID).Str("dn", entry.DN).Msg("User provisioned from the LDAP directory")
+	default:
+		slf.logger.Error().Err(err).Msg("Error finding user by email")
+		return models.User{}, err
+	}
+	return user, nil
+}
+
+// applyEntry copies the names and email of the entry (LDAP_SYNC_USERS) and its role
+// (LDAP_ADMIN_GROUPS) to user, and reports whether something changed
+func (slf *LDAPService) applyEntry(user *models.User, entry *directory.Entry) bool {
+	cfg := slf.config.LDAPConfig
+	before := *user
+
+	if cfg.SyncUsers {
+		if entry.Email != "" {
+			user.Email = entry.Email
+		}
+		if entry.FirstName != "" {
+			user.Prenom = entry.FirstName
+		}
+		if entry.LastName != "" {
+			user.Nom = entry.LastName
+		}
+		if user.Nom == "" {
+			user.Nom = user.Email
+		}
+	}
+	if len(cfg.AdminGroups) > 0 {
+		user.Role = models.RoleUser
+		if entry.MemberOf(cfg.AdminGroups) {
+			user.Role = models.RoleAdmin
+		}
+	}
+
+	return user.Email != before.Email || user.Prenom != before.Prenom || user.Nom != before.Nom || user.Role != before.Role
+}
+
+// SyncUsers reads the entries of the linked users from the directory: users whose entry was
+// removed are deactivated, the others are updated like on login
+func (slf *LDAPService) SyncUsers() (response.LDAPSync, error) {
+	if !slf.Enabled() {
+		return response.LDAPSync{}, errors.New("LDAP is not configured")
+	}
+
+	users, err := slf.userRepo.FindLDAPUsers()
+	if err != nil {
+		slf.logger.Error().Err(err).Msg("Error finding LDAP users")
+		return response.LDAPSync{}, err
+	}
+
+	result := response.LDAPSync{Checked: len(users)}
+	for i := range users {
+		user := &users[i]
+		entry, err := slf.directory.Lookup(*user.LDAPDN)
+		switch {
+		case errors.Is(err, directory.ErrInvalidCredentials):
+			if !user.Actif {
+				continue
+			}
+			user.Actif = false
+			result.Deactivated++
+			slf.logger.Info().Uint("userId", user.ID).Str("dn", *user.LDAPDN).Msg("LDAP user removed from the directory, deactivated")
+		case err != nil:
+			// A directory outage must not deactivate everyone
+			slf.logger.Error().Err(err).Msg("Error reading the LDAP directory")
+			return result, errors.New("the directory is unavailable")
+		case slf.applyEntry(user, entry):
+			result.Updated++
+		default:
+			continue
+		}
+
+		if err := slf.userRepo.Update(user); err != nil {
+			slf.logger.Error().Err(err).Uint("userId", user.ID).Msg("Error syncing LDAP user")
+			return result, err
+		}
+		if !user.Actif {
+			if _, err := slf.sessionRepo.RevokeAllForUser(user.ID, models.SessionDeactivated); err != nil {
+				slf.logger.Error().Err(err).Uint("userId", user.ID).Msg("Error revoking the sessions of a removed LDAP user")
+				return result, err
+			}
+		}
+	}
+
+	slf.logger.Info().Int("checked", result.Checked).Int("updated", result.Updated).Int("deactivated", result.Deactivated).Msg("LDAP users synced")
+	return result, nil
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"api"
+	"api/internal/api/handler/request"
+	"api/internal/api/models"
+	"api/internal/directory"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDirectory holds entries by login, all with the password "secret"
+type fakeDirectory struct {
+	entries map[string]*directory.Entry
+}
+
+func (f *fakeDirectory) Authenticate(username, password string) (*directory.Entry, error) {
+	entry, ok := f.entries[username]
+	if !ok || password != "secret" {
+		return nil, directory.ErrInvalidCredentials
+	}
+	return entry, nil
+}
+
+func (f *fakeDirectory) Lookup(dn string) (*directory.Entry, error) {
+	for _, entry := range f.entries {
+		if entry.DN == dn {
+			return entry, nil
+		}
+	}
+	return nil, directory.ErrInvalidCredentials
+}
+
+func newTestLDAPService(entries map[string]*directory.Entry) *LDAPService {
+	service := NewLDAPService()
+	service.config.LDAPConfig.URL = "ldap://directory.test"
+	service.config.LDAPConfig.AdminGroups = []string{"dos-admins"}
+	service.directory = &fakeDirectory{entries: entries}
+	return service
+}
+
+func TestLDAP_Authenticate_Sync(t *testing.T) {
+	setupUserTestDB(t)
+
+	login := fmt.Sprintf("jdoe%d", time.Now().UnixNano())
+	entry := &directory.Entry{
+		DN:        "uid=" + login + ",ou=people,dc=example,dc=com",
+		Email:     uniqueEmail(),
+		FirstName: "Jane",
+		LastName:  "Doe",
+		Groups:    []string{"cn=dos-admins,ou=groups,dc=example,dc=com"},
+	}
+	service := newTestLDAPService(map[string]*directory.Entry{login: entry})
+
+	// Without sync, directory users need an account
+	_, err := service.Authenticate(login, "secret")
+	require.Error(t, err)
+
+	service.config.LDAPConfig.SyncUsers = true
+	user, err := service.Authenticate(login, "secret")
+	require.NoError(t, err)
+	defer cleanupUser(t, user.ID)
+	assert.Equal(t, entry.Email, user.Email)
+	assert.Equal(t, "Jane", user.Prenom)
+	assert.Equal(t, models.RoleAdmin, user.Role)
+	require.NotNil(t, user.LDAPDN)
+	assert.Equal(t, entry.DN, *user.LDAPDN)
+
+	_, err = service.Authenticate(login, "wrong")
+	assert.Error(t, err)
+
+	// Changes in the directory are applied on the next login
+	entry.LastName = "Smith"
+	entry.Groups = nil
+	again, err := service.Authenticate(login, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, again.ID)
+	assert.Equal(t, "Smith", again.Nom)
+	assert.Equal(t, models.RoleUser, again.Role)
+}
+
+func TestLDAP_Authenticate_LinksExistingUser(t *testing.T) {
+	setupUserTestDB(t)
+
+	local := createTestUser(t, uniqueEmail())
+	defer cleanupTestUser(t, local.ID)
+
+	entry := &directory.Entry{DN: "cn=" + local.Email + ",ou=people,dc=example,dc=com", Email: local.Email}
+	service := newTestLDAPService(map[string]*directory.Entry{"jdoe": entry})
+
+	// Existing accounts are only linked when the admin opts in
+	_, err := service.Authenticate("jdoe", "secret")
+	require.Error(t, err)
+	var stored models.User
+	require.NoError(t, api.DB.First(&stored, local.ID).Error)
+	assert.Nil(t, stored.LDAPDN)
+
+	service.config.LDAPConfig.LinkExisting = true
+	user, err := service.Authenticate("jdoe", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, local.ID, user.ID)
+
+	require.NoError(t, api.DB.First(&stored, local.ID).Error)
+	require.NotNil(t, stored.LDAPDN)
+	assert.Equal(t, entry.DN, *stored.LDAPDN)
+}
+
+func TestLDAP_Authenticate_NeverLinksAdmin(t *testing.T) {
+	setupUserTestDB(t)
+
+	admin := createTestUser(t, uniqueEmail())
+	defer cleanupTestUser(t, admin.ID)
+	api.DB.Model(&models.User{}).Where("id = ?", admin.ID).Update("role", models.RoleAdmin)
+
+	entry := &directory.Entry{DN: "cn=" + admin.Email + ",ou=people,dc=example,dc=com", Email: admin.Email}
+	service := newTestLDAPService(map[string]*directory.Entry{"jdoe": entry})
+	service.config.LDAPConfig.LinkExisting = true
+
+	_, err := service.Authenticate("jdoe", "secret")
+	require.Error(t, err)
+
+	var stored models.User
+	require.NoError(t, api.DB.First(&stored, admin.ID).Error)
+	assert.Nil(t, stored.LDAPDN)
+	assert.Equal(t, models.RoleAdmin, stored.Role)
+}
+
+func TestLDAP_Login(t *testing.T) {
+	setupUserTestDB(t)
+
+	email := uniqueEmail()
+	userService := NewUserService()
+	registered, err := userService.Register(request.RegisterDTO{Email: email, Password: "local-password", Prenom: "Jane", Nom: "Doe"}, SessionClient{})
+	require.NoError(t, err)
+	defer cleanupUser(t, registered.User.ID)
+
+	entry := &directory.Entry{DN: "uid=jdoe-login,ou=people,dc=example,dc=com", Email: email}
+	userService.ldapService = newTestLDAPService(map[string]*directory.Entry{"jdoe-login": entry, email: entry})
+	userService.ldapService.config.LDAPConfig.LinkExisting = true
+
+	// The local password works until the user is linked to the directory
+	_, err = userService.Login(request.LoginDTO{Email: email, Password: "local-password"}, SessionClient{})
+	require.NoError(t, err)
+
+	// The directory login and password are accepted and link the user
+	auth, err := userService.Login(request.LoginDTO{Email: "jdoe-login", Password: "secret"}, SessionClient{})
+	require.NoError(t, err)
+	assert.Equal(t, registered.User.ID, auth.User.ID)
+
+	_, err = userService.Login(request.LoginDTO{Email: email, Password: "local-password"}, SessionClient{})
+	assert.Error(t, err, "linked users only use the directory password")
+	_, err = userService.Login(request.LoginDTO{Email: email, Password: "secret"}, SessionClient{})
+	assert.NoError(t, err)
+}
+
+func TestLDAP_SyncUsers(t *testing.T) {
+	setupUserTestDB(t)
+
+	kept := createTestUser(t, uniqueEmail())
+	defer cleanupTestUser(t, kept.ID)
+	removed := createTestUser(t, uniqueEmail())
+	defer cleanupTestUser(t, removed.ID)
+
+	suffix := time.Now().UnixNano()
+	keptDN := fmt.Sprintf("uid=kept%d,ou=people,dc=example,dc=com", suffix)
+	removedDN := fmt.Sprintf("uid=removed%d,ou=people,dc=example,dc=com", suffix)
+	api.DB.Model(&models.User{}).Where("id = ?", kept.ID).Update("ldap_dn", keptDN)
+	api.DB.Model(&models.User{}).Where("id = ?", removed.ID).Update("ldap_dn", removedDN)
+
+	service := newTestLDAPService(map[string]*directory.Entry{
+		"kept": {DN: keptDN, Email: kept.Email, Groups: []string{"cn=dos-admins,ou=groups,dc=example,dc=com"}},
+	})
+	result, err := service.SyncUsers()
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, result.Deactivated, 1)
+	assert.GreaterOrEqual(t, result.Updated, 1)
+
+	var stored models.User
+	require.NoError(t, api.DB.First(&stored, removed.ID).Error)
+	assert.False(t, stored.Actif)
+	require.NoError(t, api.DB.First(&stored, kept.ID).Error)
+	assert.True(t, stored.Actif)
+	assert.Equal(t, models.RoleAdmin, stored.Role)
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"api"
+	"api/pkg"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// LoginThrottledError refuses a login attempt before the password is checked
+type LoginThrottledError struct {
+	// Locked is set when the account is locked after repeated failures, otherwise the account
+	// made too many attempts
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	wait := (e.RetryAfter + time.Second - 1).Truncate(time.Second)
+	if e.Locked {
+		return fmt.Sprintf("account temporarily locked after too many failed logins, retry in %s", wait)
+	}
+	return fmt.Sprintf("too many login attempts, retry in %s", wait)
+}
+
+// LoginGuardService protects the accounts from password guessing: the attempts on an account
+// are rate limited and the account is locked for a while after repeated failures. The counters
+// are kept in Redis under the login as typed (lowercased), so unknown accounts behave like the
+// others. A Redis failure lets the attempt through.
+type LoginGuardService struct {
+	config api.AppConfig
+	logger zerolog.Logger
+}
+
+func NewLoginGuardService() *LoginGuardService {
+	return &LoginGuardService{
+		config: api.GetConfig(),
+		logger: api.Logger,
+	}
+}
+
+// Check records an attempt on an account, refusing it with a *LoginThrottledError when the
+// account is locked or over its limit
+func (slf *LoginGuardService) Check(login string) error {
+	cfg := slf.config.RateLimitConfig
+	if !cfg.Enabled {
+		return nil
+	}
+	account := loginAccount(login)
+
+	locked, err := pkg.RedisTTL("login:locked:" + account)
+	if err != nil {
+		slf.logger.Warn().Err(err).Msg("Error reading the account lockout")
+	} else if locked > 0 {
+		return &LoginThrottledError{Locked: true, RetryAfter: locked}
+	}
+
+	if cfg.LoginPerAccount <= 0 {
+		return nil
+	}
+	window := time.Duration(cfg.LoginWindow) * time.Second
+	allowed, retryAfter, err := pkg.RedisSlidingWindow("login:attempts:"+account, cfg.LoginPerAccount, window)
+	if err != nil {
+		slf.logger.Warn().Err(err).Msg("Error rate limiting a login")
+		return nil
+	}
+	if !allowed {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// Failed counts a failed login of an account and locks it when the failures reach the threshold,
+// reporting whether it did
+func (slf *LoginGuardService) Failed(login string) bool {
+	cfg := slf.config.RateLimitConfig
+	if !cfg.Enabled || cfg.LockoutThreshold <= 0 {
+		return false
+	}
+	account := loginAccount(login)
+	duration := time.Duration(cfg.LockoutDuration) * time.Minute
+
+	failures, err := pkg.RedisIncr("login:failures:"+account, duration)
+	if err != nil {
+		slf.logger.Warn().Err(err).Msg("Error counting a failed login")
+		return false
+	}
+	if failures < int64(cfg.LockoutThreshold) {
+		return false
+	}
+
+	if err := pkg.RedisSet("login:locked:"+account, failures, duration); err != nil {
+		slf.logger.Error().Err(err).Msg("Error locking an account")
+		return false
+	}
+	if err := pkg.RedisDelete("login:failures:" + account); err != nil {
+		slf.logger.Warn().Err(err).Msg("Error resetting failed logins")
+	}
+	slf.logger.Warn().Str("login", account).Int64("failures", failures).Dur("duration", duration).Msg("Account locked after failed logins")
+	return true
+}
+
+// Succeeded clears the failed logins of an account
+func (slf *LoginGuardService) Succeeded(login string) {
+	if !slf.config.RateLimitConfig.Enabled {
+		return
+	}
+	if err := pkg.RedisDelete("login:failures:" + loginAccount(login)); err != nil {
+		slf.logger.Warn().Err(err).Msg("Error resetting failed logins")
+	}
+}
+
+func loginAccount(login string) string {
+	return strings.ToLower(strings.TrimSpace(login))
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"api"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLoginGuard() *LoginGuardService {
+	api.InitConfig("../../../.env.test")
+
+	guard := NewLoginGuardService()
+	guard.config.RateLimitConfig.Enabled = true
+	guard.config.RateLimitConfig.LoginPerAccount = 5
+	guard.config.RateLimitConfig.LoginWindow = 60
+	guard.config.RateLimitConfig.LockoutThreshold = 3
+	guard.config.RateLimitConfig.LockoutDuration = 1
+	return guard
+}
+
+func TestLoginGuard_Lockout(t *testing.T) {
+	guard := newTestLoginGuard()
+	login := uniqueEmail()
+
+	require.NoError(t, guard.Check(login))
+	assert.False(t, guard.Failed(login))
+	assert.False(t, guard.Failed(login))
+	// A success forgets the failures
+	guard.Succeeded(login)
+	assert.False(t, guard.Failed(login))
+	assert.False(t, guard.Failed(login))
+	assert.True(t, guard.Failed(login), "Third failure in a row locks the account")
+
+	err := guard.Check(" " + login)
+	var throttled *LoginThrottledError
+	require.True(t, errors.As(err, &throttled), "Logins are normalized")
+	assert.True(t, throttled.Locked)
+	assert.InDelta(t, time.Minute, throttled.RetryAfter, float64(2*time.Second))
+}
+
+func TestLoginGuard_AttemptsPerAccount(t *testing.T) {
+	guard := newTestLoginGuard()
+	login := uniqueEmail()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, guard.Check(login))
+	}
+	err := guard.Check(login)
+	var throttled *LoginThrottledError
+	require.True(t, errors.As(err, &throttled))
+	assert.False(t, throttled.Locked)
+	assert.Greater(t, throttled.RetryAfter, time.Duration(0))
+
+	require.NoError(t, guard.Check(uniqueEmail()), "Other accounts are not affected")
+}
@@ -4,8 +4,10 @@ import (
 	"api"
 	"api/internal/api/models"
 	"api/internal/api/repo"
+	"fmt"
 
 	"github.com/rs/zerolog"
+	"gorm.io/gorm"
 )
 
 type MetadataService struct {
@@ -39,6 +41,18 @@ func (s *MetadataService) FindByID(id uint) (*models.MetadataDatabase, error) {
 }
 
 func (s *MetadataService) Update(id uint, patch map[string]any) (*models.MetadataDatabase, error) {
+	// The client key is not returned by the API, a blank one keeps the stored key
+	if tls, ok := patch["tls"].(models.TLSConfig); ok && tls.ClientKey == "" {
+		existing, err := s.FindByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if existing.TLS != nil {
+			tls.ClientKey = existing.TLS.ClientKey
+			patch["tls"] = tls
+		}
+	}
+
 	if err := s.metadataRepo.Db.Model(&models.MetadataDatabase{}).Where("id = ?", id).Updates(patch).Error; err != nil {
 		return nil, err
 	}
@@ -55,3 +69,113 @@ func (s *MetadataService) Create(metadata models.MetadataDatabase) (*models.Meta
 func (s *MetadataService) Delete(id uint) error {
 	return s.metadataRepo.Db.Delete(&models.MetadataDatabase{}, id).Error
 }
+
+// ConnectionMigration is a db node or database trigger whose inline connection matches a saved database
+type ConnectionMigration struct {
+	JobID              uint
+	NodeID             int
+	TriggerID          uint
+	MetadataDatabaseID uint
+}
+
+// MigrateInlineConnections makes the db nodes and database triggers whose inline connection matches
+// a saved database reference it instead (see MetadataDatabase.Matches), clearing the inline copy so
+// the credentials live in one place. With dryRun nothing is written. The first matching database
+// wins when several are identical.
+func (s *MetadataService) MigrateInlineConnections(dryRun bool) ([]ConnectionMigration, error) {
+	databases, err := s.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	match := func(c models.DBConnectionConfig) (uint, bool) {
+		for _, db := range databases {
+			if db.Matches(c) {
+				return db.ID, true
+			}
+		}
+		return 0, false
+	}
+
+	migrated := make([]ConnectionMigration, 0)
+	err = s.metadataRepo.Db.Transaction(func(tx *gorm.DB) error {
+		var nodes []models.Node
+		if err := tx.Where("type IN ?", []models.NodeType{models.NodeTypeDBInput, models.NodeTypeDBOutput}).Find(&nodes).Error; err != nil {
+			return err
+		}
+		for _, node := range nodes {
+			id, ok, err := migrateNodeConnection(&node, match)
+			if err != nil {
+				return fmt.Errorf("node %d: %w", node.ID, err)
+			}
+			if !ok {
+				continue
+			}
+			migrated = append(migrated, ConnectionMigration{JobID: node.JobID, NodeID: node.ID, MetadataDatabaseID: id})
+			if !dryRun {
+				if err := tx.Model(&models.Node{}).Where("id = ?", node.ID).Update("data", node.Data).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		var triggers []models.Trigger
+		if err := tx.Where("type = ?", models.TriggerTypeDatabase).Find(&triggers).Error; err != nil {
+			return err
+		}
+		for _, trigger := range triggers {
+			cfg := trigger.Config.Database
+			if cfg == nil || cfg.MetadataDatabaseID != nil || cfg.Connection == nil {
+				continue
+			}
+			id, ok := match(*cfg.Connection)
+			if !ok {
+				continue
+			}
+			cfg.MetadataDatabaseID = &id
+			cfg.Connection = nil
+			migrated = append(migrated, ConnectionMigration{TriggerID: trigger.ID, MetadataDatabaseID: id})
+			if !dryRun {
+				if err := tx.Model(&models.Trigger{}).Where("id = ?", trigger.ID).Update("config", trigger.Config).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Error migrating inline connections")
+		return nil, err
+	}
+
+	s.logger.Info().Int("migrated", len(migrated)).Bool("dryRun", dryRun).Msg("Inline connections migrated")
+	return migrated, nil
+}
+
+// migrateNodeConnection points a db node without reference to the saved database its connection matches
+func migrateNodeConnection(node *models.Node, match func(models.DBConnectionConfig) (uint, bool)) (uint, bool, error) {
+	switch node.Type {
+	case models.NodeTypeDBInput:
+		cfg, err := node.GetDBInputConfig()
+		if err != nil || cfg.MetadataDatabaseID != nil {
+			return 0, false, err
+		}
+		id, ok := match(cfg.Connection)
+		if !ok {
+			return 0, false, nil
+		}
+		cfg.MetadataDatabaseID, cfg.Connection = &id, models.DBConnectionConfig{}
+		return id, true, node.SetData(cfg)
+	case models.NodeTypeDBOutput:
+		cfg, err := node.GetDBOutputConfig()
+		if err != nil || cfg.MetadataDatabaseID != nil {
+			return 0, false, err
+		}
+		id, ok := match(cfg.Connection)
+		if !ok {
+			return 0, false, nil
+		}
+		cfg.MetadataDatabaseID, cfg.Connection = &id, models.DBConnectionConfig{}
+		return id, true, node.SetData(cfg)
+	}
+	return 0, false, nil
+}
@@ -58,7 +58,7 @@ func TestDbMetadata_Create(t *testing.T) {
 	assert.Equal(t, "localhost", created.Host)
 	assert.Equal(t, 5433, created.Port)
 	assert.Equal(t, "testuser", created.User)
-	assert.Equal(t, "testpass", created.Password)
+	assert.Equal(t, models.Secret("testpass"), created.Password)
 	assert.Equal(t, "testdb", created.DatabaseName)
 	assert.Equal(t, "disable", created.SSLMode)
 }
@@ -175,7 +175,7 @@ func TestDbMetadata_Update(t *testing.T) {
 	assert.Equal(t, "newdb", updated.DatabaseName)
 	// Unchanged fields
 	assert.Equal(t, 5432, updated.Port)
-	assert.Equal(t, "oldpass", updated.Password)
+	assert.Equal(t, models.Secret("oldpass"), updated.Password)
 }
 
 func TestDbMetadata_Delete(t *testing.T) {
@@ -232,7 +232,7 @@ func TestSftpMetadata_Create(t *testing.T) {
 	assert.Equal(t, "sftp.example.com", created.Host)
 	assert.Equal(t, 22, created.Port)
 	assert.Equal(t, "sftpuser", created.User)
-	assert.Equal(t, "sftppass", created.Password)
+	assert.Equal(t, models.Secret("sftppass"), created.Password)
 	assert.Equal(t, "/data/uploads", created.BasePath)
 }
 
@@ -251,7 +251,7 @@ QyNTUxOQAAACBbeWvHh1hVy4k9P/l0JsY3TEstVZDQ7CfNMgAAAA==
 		Port:       22,
 		User:       "keyuser",
 		Password:   "",
-		PrivateKey: privateKey,
+		PrivateKey: models.Secret(privateKey),
 		BasePath:   "/secure/data",
 	}
 
@@ -366,7 +366,7 @@ func TestSftpMetadata_Update(t *testing.T) {
 	assert.Equal(t, "/new/path", updated.BasePath)
 	// Unchanged fields
 	assert.Equal(t, 22, updated.Port)
-	assert.Equal(t, "oldpass", updated.Password)
+	assert.Equal(t, models.Secret("oldpass"), updated.Password)
 }
 
 func TestSftpMetadata_Delete(t *testing.T) {
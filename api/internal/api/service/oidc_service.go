@@ -0,0 +1,199 @@
+package service
+
+import (
+	"api"
+	"api/internal/api/handler/response"
+	"api/internal/api/models"
+	"api/internal/oidc"
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// ErrOIDCDisabled is returned when single sign-on is not configured
+var ErrOIDCDisabled = errors.New("single sign-on is not configured")
+
+// OIDCService logs users in with an OpenID Connect provider, alongside UserService.Login. Users
+// are provisioned from the ID token claims on their first login.
+type OIDCService struct {
+	config      api.AppConfig
+	logger      zerolog.Logger
+	userService *UserService
+
+	mu       sync.Mutex
+	provider *oidc.Provider
+}
+
+func NewOIDCService() *OIDCService {
+	return &OIDCService{
+		config:      api.GetConfig(),
+		logger:      api.Logger,
+		userService: NewUserService(),
+	}
+}
+
+// Enabled reports whether single sign-on is configured
+func (slf *OIDCService) Enabled() bool {
+	return slf.config.OIDCConfig.Issuer != ""
+}
+
+// getProvider runs the discovery on first use, a failed discovery is retried on the next login
+func (slf *OIDCService) getProvider(ctx context.Context) (*oidc.Provider, error) {
+	if !slf.Enabled() {
+		return nil, ErrOIDCDisabled
+	}
+
+	slf.mu.Lock()
+	defer slf.mu.Unlock()
+	if slf.provider != nil {
+		return slf.provider, nil
+	}
+
+	cfg := slf.config.OIDCConfig
+	provider, err := oidc.NewProvider(ctx, oidc.Config{
+		Issuer:       cfg.Issuer,
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		RedirectURL:  cfg.RedirectURL,
+		Scopes:       cfg.Scopes,
+	})
+	if err != nil {
+		slf.logger.Error().Err(err).Str("issuer", cfg.Issuer).Msg("Error discovering OIDC provider")
+		return nil, err
+	}
+	slf.provider = provider
+	return provider, nil
+}
+
+// AuthCodeURL returns the provider login URL of a new login
+func (slf *OIDCService) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	provider, err := slf.getProvider(ctx)
+	if err != nil {
+		return "", err
+	}
+	return provider.AuthCodeURL(state, nonce, verifier), nil
+}
+
+// Login exchanges the authorization code returned to the callback, provisions the user and opens
+// a session with its tokens
+func (slf *OIDCService) Login(ctx context.Context, code, verifier, nonce string, client SessionClient) (*response.AuthResponseDTO, error) {
+	provider, err := slf.getProvider(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := provider.Exchange(ctx, code, verifier, nonce)
+	if err != nil {
+		slf.logger.Warn().Err(err).Msg("OIDC login rejected")
+		return nil, errors.New("single sign-on failed")
+	}
+
+	user, err := slf.provision(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	authResponse, err := slf.userService.issueTokens(&user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	slf.logger.Info().Uint("userId", user.ID).Str("role", string(user.Role)).Msg("User logged in with single sign-on")
+	return authResponse, nil
+}
+
+// provision returns the user of the ID token subject. A user logging in for the first time is
+// linked to the local account with the same email when the provider verified it, created otherwise.
+func (slf *OIDCService) provision(claims oidc.Claims) (models.User, error) {
+	subject := claims.String("sub")
+	userRepo := slf.userService.userRepo
+
+	user, err := userRepo.FindByOIDCSubject(subject)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		email := claims.String("email")
+		if email == "" {
+			return models.User{}, errors.New("the identity provider did not return an email")
+		}
+
+		user, err = userRepo.FindByEmail(email)
+		switch {
+		case err == nil:
+			if !claims.Bool("email_verified") {
+				return models.User{}, errors.New("an account with this email already exists")
+			}
+			if user.ServiceAccount {
+				return models.User{}, errors.New("service accounts cannot log in")
+			}
+			user.OIDCSubject = &subject
+			if err := userRepo.Update(&user); err != nil {
+				slf.logger.Error().Err(err).Uint("userId", user.ID).Msg("Error linking OIDC user")
+				return models.User{}, err
+			}
+			slf.logger.Info().Uint("userId", user.ID).Msg("User linked to single sign-on")
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			user = newOIDCUser(claims)
+			user.OIDCSubject = &subject
+			if err := userRepo.Create(&user); err != nil {
+				slf.logger.Error().Err(err).Msg("Error provisioning OIDC user")
+				return models.User{}, err
+			}
+			slf.logger.Info().Uint("userId", user.ID).Msg("User provisioned from single sign-on")
+		default:
+			return models.User{}, err
+		}
+	} else if err != nil {
+		slf.logger.Error().Err(err).Msg("Error finding OIDC user")
+		return models.User{}, err
+	}
+
+	if user.ServiceAccount {
+		return models.User{}, errors.New("service accounts cannot log in")
+	}
+	if !user.Actif {
+		return models.User{}, errors.New("account is inactive")
+	}
+
+	if cfg := slf.config.OIDCConfig; len(cfg.AdminGroups) > 0 {
+		if role := roleFromGroups(claims.Strings(cfg.GroupsClaim), cfg.AdminGroups); role != user.Role {
+			user.Role = role
+			if err := userRepo.Update(&user); err != nil {
+				slf.logger.Error().Err(err).Uint("userId", user.ID).Msg("Error updating OIDC user role")
+				return models.User{}, err
+			}
+		}
+	}
+	return user, nil
+}
+
+// newOIDCUser builds a user from the ID token claims. It has no password: it can only log in
+// through the provider.
+func newOIDCUser(claims oidc.Claims) models.User {
+	prenom, nom := claims.String("given_name"), claims.String("family_name")
+	if nom == "" {
+		nom = claims.String("name")
+	}
+	if nom == "" {
+		nom = claims.String("email")
+	}
+	return models.User{
+		Email:  claims.String("email"),
+		Prenom: prenom,
+		Nom:    nom,
+		Role:   models.RoleUser,
+		Actif:  true,
+	}
+}
+
+// roleFromGroups maps the groups of a user to a role
+func roleFromGroups(groups, adminGroups []string) models.AppRole {
+	for _, group := range groups {
+		if slices.Contains(adminGroups, group) {
+			return models.RoleAdmin
+		}
+	}
+	return models.RoleUser
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"api"
+	"api/internal/api/models"
+	"api/internal/oidc"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleFromGroups(t *testing.T) {
+	admins := []string{"dos-admins"}
+	assert.Equal(t, models.RoleAdmin, roleFromGroups([]string{"staff", "dos-admins"}, admins))
+	assert.Equal(t, models.RoleUser, roleFromGroups([]string{"staff"}, admins))
+	assert.Equal(t, models.RoleUser, roleFromGroups(nil, admins))
+}
+
+func TestOIDC_Provision(t *testing.T) {
+	setupUserTestDB(t)
+
+	service := NewOIDCService()
+	service.config.OIDCConfig.GroupsClaim = "groups"
+	service.config.OIDCConfig.AdminGroups = []string{"dos-admins"}
+
+	subject := fmt.Sprintf("sub-%d", time.Now().UnixNano())
+	email := uniqueEmail()
+	claims := oidc.Claims{
+		"sub":         subject,
+		"email":       email,
+		"given_name":  "Jane",
+		"family_name": "Doe",
+		"groups":      []any{"dos-admins"},
+	}
+
+	// First login creates the user
+	user, err := service.provision(claims)
+	require.NoError(t, err)
+	defer cleanupUser(t, user.ID)
+	assert.Equal(t, email, user.Email)
+	assert.Equal(t, "Jane", user.Prenom)
+	assert.Equal(t, "Doe", user.Nom)
+	assert.Equal(t, models.RoleAdmin, user.Role)
+	require.NotNil(t, user.OIDCSubject)
+	assert.Equal(t, subject, *user.OIDCSubject)
+
+	// Next logins find it by subject and sync its role
+	claims["groups"] = []any{"staff"}
+	again, err := service.provision(claims)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, again.ID)
+	assert.Equal(t, models.RoleUser, again.Role)
+	var stored models.User
+	require.NoError(t, api.DB.First(&stored, user.ID).Error)
+	assert.Equal(t, models.RoleUser, stored.Role)
+}
+
+func TestOIDC_Provision_ExistingEmail(t *testing.T) {
+	setupUserTestDB(t)
+
+	service := NewOIDCService()
+	local := createTestUser(t, uniqueEmail())
+	defer cleanupTestUser(t, local.ID)
+
+	claims := oidc.Claims{"sub": fmt.Sprintf("sub-%d", time.Now().UnixNano()), "email": local.Email}
+
+	// An unverified email does not take over a local account
+	_, err := service.provision(claims)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+
+	claims["email_verified"] = true
+	linked, err := service.provision(claims)
+	require.NoError(t, err)
+	assert.Equal(t, local.ID, linked.ID)
+	require.NotNil(t, linked.OIDCSubject)
+	again, err := service.provision(claims)
+	require.NoError(t, err)
+	assert.Equal(t, local.ID, again.ID, "found by subject once linked")
+
+	_, err = service.provision(oidc.Claims{"sub": "no-email"})
+	assert.Error(t, err)
+
+	api.DB.Model(&models.User{}).Where("id = ?", local.ID).Update("actif", false)
+	_, err = service.provision(claims)
+	assert.Error(t, err, "inactive users cannot log in")
+}
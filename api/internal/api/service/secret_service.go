@@ -0,0 +1,71 @@
+package service
+
+import (
+	"api"
+	"api/internal/api/repo"
+	"api/internal/secret"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog"
+)
+
+// secretColumn is a column holding credentials, a whole value or the fields of a JSON document
+type secretColumn struct {
+	table  string
+	column string
+	json   bool
+}
+
+var secretColumns = []secretColumn{
+	{table: "metadata_database", column: "password"},
+	{table: "metadata_database", column: "tls", json: true},
+	{table: "metadata_sftp", column: "password"},
+	{table: "metadata_sftp", column: "private_key"},
+	{table: "metadata_email", column: "password"},
+	{table: "node", column: "data", json: true},
+	{table: "trigger", column: "config", json: true},
+}
+
+type SecretService struct {
+	logger     zerolog.Logger
+	secretRepo repo.SecretRepository
+}
+
+func NewSecretService() *SecretService {
+	return &SecretService{
+		logger:     api.Logger,
+		secretRepo: *repo.NewSecretRepository(),
+	}
+}
+
+// RotateKeys re-encrypts the stored credentials with the active master key: data keys wrapped by
+// an older key are re-wrapped and values stored before encryption was enabled are sealed. Returns
+// the number of rewritten values per table.column; old keys can be removed once it succeeded.
+func (slf *SecretService) RotateKeys() (map[string]int, error) {
+	keyring := secret.Current()
+	if keyring == nil {
+		return nil, errors.New("no master key is configured")
+	}
+
+	rotated := make(map[string]int, len(secretColumns))
+	for _, col := range secretColumns {
+		rewrite := keyring.Rewrap
+		if col.json {
+			rewrite = func(value string) (string, bool, error) {
+				out, changed, err := secret.RewrapJSON(keyring, []byte(value))
+				return string(out), changed, err
+			}
+		}
+
+		n, err := slf.secretRepo.RewriteColumn(col.table, col.column, rewrite)
+		if err != nil {
+			slf.logger.Error().Err(err).Str("table", col.table).Str("column", col.column).Msg("Error rotating credentials")
+			return rotated, fmt.Errorf("rotating %s.%s: %w", col.table, col.column, err)
+		}
+		rotated[col.table+"."+col.column] = n
+	}
+
+	slf.logger.Info().Str("keyId", keyring.ActiveKeyID()).Interface("rotated", rotated).Msg("Credentials rotated")
+	return rotated, nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"api"
+	"api/internal/api/handler/mapper"
+	"api/internal/api/handler/response"
+	"api/internal/api/models"
+	"api/internal/api/repo"
+	"api/pkg"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gorm.io/gorm"
+)
+
+// maxUserAgentLength is the size of the user_agent column
+const maxUserAgentLength = 255
+
+var (
+	// ErrInvalidRefreshToken is returned for malformed and expired tokens and for the tokens of
+	// unknown, expired and revoked sessions
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReuse is returned when a refresh token is presented after it was rotated. Its
+	// session is revoked: either the client or an attacker holds a stolen token.
+	ErrRefreshTokenReuse = errors.New("refresh token already used, the session was revoked")
+)
+
+// SessionClient describes the device a session is used from
+type SessionClient struct {
+	IP        string
+	UserAgent string
+}
+
+// SessionService keeps the sessions of the users: one per login, holding the generation of its
+// current refresh token
+type SessionService struct {
+	sessionRepo   *repo.SessionRepository
+	auditService  *AuditService
+	config        api.AppConfig
+	logger        zerolog.Logger
+	sessionMapper mapper.SessionMapper
+}
+
+func NewSessionService() *SessionService {
+	return &SessionService{
+		sessionRepo:   repo.NewSessionRepository(),
+		auditService:  NewAuditService(),
+		config:        api.GetConfig(),
+		logger:        api.Logger,
+		sessionMapper: mapper.NewSessionMapper(),
+	}
+}
+
+// Start opens a session for a user at generation 1
+func (slf *SessionService) Start(userID uint, client SessionClient) (models.Session, error) {
+	now := time.Now()
+	session := models.Session{
+		UserID:     userID,
+		Generation: 1,
+		IP:         client.IP,
+		UserAgent:  truncateUserAgent(client.UserAgent),
+		LastUsedAt: now,
+		ExpiresAt:  slf.expiresAt(now),
+	}
+	if err := slf.sessionRepo.Create(&session); err != nil {
+		slf.logger.Error().Err(err).Uint("userId", userID).Msg("Error creating session")
+		return models.Session{}, err
+	}
+	return session, nil
+}
+
+// Rotate moves the session of a refresh token to its next generation, the caller issues the token
+// of that generation. A token of an older generation revokes the session (ErrRefreshTokenReuse),
+// which is recorded in the audit trail.
+func (slf *SessionService) Rotate(claims *pkg.RefreshTokenClaims, client SessionClient) (models.Session, error) {
+	session, err := slf.sessionRepo.FindByID(claims.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.Session{}, ErrInvalidRefreshToken
+		}
+		slf.logger.Error().Err(err).Uint("sessionId", claims.SessionID).Msg("Error finding session")
+		return models.Session{}, err
+	}
+
+	now := time.Now()
+	if session.UserID != claims.UserID || !session.Active(now) {
+		return models.Session{}, ErrInvalidRefreshToken
+	}
+
+	if claims.Generation == session.Generation {
+		expiresAt := slf.expiresAt(now)
+		userAgent := truncateUserAgent(client.UserAgent)
+		rotated, err := slf.sessionRepo.Rotate(session.ID, session.Generation, client.IP, userAgent, now, expiresAt)
+		if err != nil {
+			slf.logger.Error().Err(err).Uint("sessionId", session.ID).Msg("Error rotating session")
+			return models.Session{}, err
+		}
+		if rotated {
+			session.Generation++
+			session.IP, session.UserAgent = client.IP, userAgent
+			session.LastUsedAt, session.ExpiresAt = now, expiresAt
+			return session, nil
+		}
+		// Another refresh with the same token got there first: the token was used twice
+	}
+
+	if _, err := slf.sessionRepo.Revoke(session.UserID, session.ID, models.SessionTokenReuse); err != nil {
+		slf.logger.Error().Err(err).Uint("sessionId", session.ID).Msg("Error revoking session")
+		return models.Session{}, err
+	}
+	slf.logger.Warn().Uint("userId", session.UserID).Uint("sessionId", session.ID).
+		Uint("generation", claims.Generation).Str("ip", client.IP).Msg("Refresh token reused, session revoked")
+	slf.auditService.Record(AuditActor{UserID: &session.UserID, IP: client.IP}, AuditEvent{
+		Action:       models.AuditTokenReuse,
+		ResourceType: models.AuditResourceUser,
+		ResourceID:   session.UserID,
+		Details:      map[string]any{"sessionId": session.ID, "generation": claims.Generation, "userAgent": client.UserAgent},
+	})
+	return models.Session{}, ErrRefreshTokenReuse
+}
+
+// Logout revokes the session of a refresh token and returns it. Rotated tokens are accepted too:
+// whoever holds one could end the session anyway by presenting it to Rotate.
+func (slf *SessionService) Logout(refreshToken string) (models.Session, error) {
+	claims, err := pkg.ValidateRefreshToken(refreshToken, slf.config.JWTConfig.Secret)
+	if err != nil || claims.SessionID == 0 {
+		return models.Session{}, ErrInvalidRefreshToken
+	}
+
+	// Logging out of an ended session succeeds
+	if _, err := slf.sessionRepo.Revoke(claims.UserID, claims.SessionID, models.SessionLogout); err != nil {
+		slf.logger.Error().Err(err).Uint("sessionId", claims.SessionID).Msg("Error revoking session")
+		return models.Session{}, err
+	}
+	slf.logger.Info().Uint("userId", claims.UserID).Uint("sessionId", claims.SessionID).Msg("User logged out")
+	return models.Session{ID: claims.SessionID, UserID: claims.UserID}, nil
+}
+
+// IsActive reports whether a session is neither revoked nor expired, access tokens are only
+// accepted while their session is
+func (slf *SessionService) IsActive(id uint) (bool, error) {
+	session, err := slf.sessionRepo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return session.Active(time.Now()), nil
+}
+
+// FindForUser lists the active sessions of a user, flagging the one with ID currentID
+func (slf *SessionService) FindForUser(userID, currentID uint) ([]response.Session, error) {
+	sessions, err := slf.sessionRepo.FindActiveByUser(userID, time.Now())
+	if err != nil {
+		slf.logger.Error().Err(err).Uint("userId", userID).Msg("Error listing sessions")
+		return nil, err
+	}
+	return slf.sessionMapper.ToSessionResponses(sessions, currentID), nil
+}
+
+// Revoke revokes a session of a user
+func (slf *SessionService) Revoke(userID, sessionID uint, reason models.SessionRevokeReason) error {
+	found, err := slf.sessionRepo.Revoke(userID, sessionID, reason)
+	if err != nil {
+		slf.logger.Error().Err(err).Uint("sessionId", sessionID).Msg("Error revoking session")
+		return err
+	}
+	if !found {
+		return errors.New("session not found")
+	}
+	slf.logger.Info().Uint("userId", userID).Uint("sessionId", sessionID).Str("reason", string(reason)).Msg("Session revoked")
+	return nil
+}
+
+// RevokeAll revokes every active session of a user, logging them out of all their devices, and
+// returns how many there were
+func (slf *SessionService) RevokeAll(userID uint, reason models.SessionRevokeReason) (int64, error) {
+	count, err := slf.sessionRepo.RevokeAllForUser(userID, reason)
+	if err != nil {
+		slf.logger.Error().Err(err).Uint("userId", userID).Msg("Error revoking sessions")
+		return 0, err
+	}
+	slf.logger.Info().Uint("userId", userID).Int64("sessions", count).Str("reason", string(reason)).Msg("Sessions revoked")
+	return count, nil
+}
+
+// expiresAt is the expiry of a session used at now: each refresh extends it
+func (slf *SessionService) expiresAt(now time.Time) time.Time {
+	return now.AddDate(0, 0, slf.config.JWTConfig.RefreshExpiration)
+}
+
+func truncateUserAgent(userAgent string) string {
+	if len(userAgent) <= maxUserAgentLength {
+		return userAgent
+	}
+	return strings.ToValidUTF8(userAgent[:maxUserAgentLength], "")
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"api/internal/api/handler/request"
+	"api/internal/api/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_ListRevokeAndLogout(t *testing.T) {
+	setupUserTestDB(t)
+
+	userService := NewUserService()
+	email := uniqueEmail()
+	first, err := userService.Register(request.RegisterDTO{Email: email, Password: "testpassword", Prenom: "Session", Nom: "Test"}, SessionClient{IP: "10.0.0.1", UserAgent: "laptop"})
+	require.NoError(t, err)
+	defer cleanupUser(t, first.User.ID)
+	second, err := userService.Login(request.LoginDTO{Email: email, Password: "testpassword"}, SessionClient{IP: "10.0.0.2", UserAgent: "phone"})
+	require.NoError(t, err)
+
+	service := NewSessionService()
+	logoutSession, err := service.Logout(second.RefreshToken)
+	require.NoError(t, err)
+	_, err = service.Logout(second.RefreshToken)
+	require.NoError(t, err, "Logging out twice succeeds")
+
+	active, err := service.IsActive(logoutSession.ID)
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	sessions, err := service.FindForUser(first.User.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "laptop", sessions[0].UserAgent)
+	assert.Equal(t, "10.0.0.1", sessions[0].IP)
+
+	// Another user cannot revoke the session
+	assert.Error(t, service.Revoke(first.User.ID+1, sessions[0].ID, models.SessionRevoked))
+	require.NoError(t, service.Revoke(first.User.ID, sessions[0].ID, models.SessionRevoked))
+	_, err = userService.RefreshToken(first.RefreshToken, SessionClient{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestSession_RevokeAll(t *testing.T) {
+	setupUserTestDB(t)
+
+	userService := NewUserService()
+	email := uniqueEmail()
+	registered, err := userService.Register(request.RegisterDTO{Email: email, Password: "testpassword", Prenom: "Force", Nom: "Logout"}, SessionClient{})
+	require.NoError(t, err)
+	defer cleanupUser(t, registered.User.ID)
+	_, err = userService.Login(request.LoginDTO{Email: email, Password: "testpassword"}, SessionClient{})
+	require.NoError(t, err)
+
+	service := NewSessionService()
+	count, err := service.RevokeAll(registered.User.ID, models.SessionForceLogout)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+
+	sessions, err := service.FindForUser(registered.User.ID, 0)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+	_, err = userService.RefreshToken(registered.RefreshToken, SessionClient{})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
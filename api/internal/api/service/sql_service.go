@@ -150,15 +150,7 @@ func (slf *SqlService) fetchSchema(metadata models.MetadataDatabase) ([]pkg.Tabl
 		return pkg.FindPostgresSchemaDatabaseSchema(ctx, pool)
 
 	case models.DBTypeSQLServer:
-		connCfg := models.DBConnectionConfig{
-			Type:     models.DBTypeSQLServer,
-			Host:     metadata.Host,
-			Port:     metadata.Port,
-			Username: metadata.User,
-			Password: metadata.Password,
-			Database: metadata.DatabaseName,
-			SSLMode:  metadata.SSLMode,
-		}
+		connCfg := metadata.ConnectionConfig()
 		db, err := sql.Open("sqlserver", connCfg.BuildConnectionString())
 		if err != nil {
 			return nil, err
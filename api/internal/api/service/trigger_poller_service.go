@@ -29,9 +29,10 @@ import (
 
 // TriggerPollerService manages polling for all active triggers
 type TriggerPollerService struct {
-	triggerRepo *repo.TriggerRepository
-	jobService  *JobService
-	logger      zerolog.Logger
+	triggerRepo  *repo.TriggerRepository
+	jobService   *JobService
+	auditService *AuditService
+	logger       zerolog.Logger
 
 	ctx        context.Context
 	cancel     context.CancelFunc
@@ -49,6 +50,7 @@ func NewTriggerPollerService(maxWorkers int) *TriggerPollerService {
 	return &TriggerPollerService{
 		triggerRepo:    repo.NewTriggerRepository(),
 		jobService:     NewJobService(),
+		auditService:   NewAuditService(),
 		logger:         api.Logger,
 		ctx:            ctx,
 		cancel:         cancel,
@@ -314,15 +316,7 @@ func (slf *TriggerPollerService) pollDatabase(trigger models.Trigger) ([]map[str
 		if err := slf.triggerRepo.Db.First(&meta, *cfg.MetadataDatabaseID).Error; err != nil {
 			return nil, fmt.Errorf("failed to load database metadata: %w", err)
 		}
-		connCfg = models.DBConnectionConfig{
-			Type:     meta.DbType,
-			Host:     meta.Host,
-			Port:     meta.Port,
-			Database: meta.DatabaseName,
-			Username: meta.User,
-			Password: meta.Password,
-			SSLMode:  meta.SSLMode,
-		}
+		connCfg = meta.ConnectionConfig()
 	} else if cfg.Connection != nil {
 		connCfg = *cfg.Connection
 	} else {
@@ -639,7 +633,7 @@ func (slf *TriggerPollerService) resolveEmailCredentials(cfg *models.EmailTrigge
 		if err := slf.triggerRepo.Db.First(&meta, *cfg.MetadataEmailID).Error; err != nil {
 			return "", 0, "", "", false, fmt.Errorf("failed to load email metadata: %w", err)
 		}
-		return meta.ImapHost, meta.ImapPort, meta.Username, meta.Password, meta.UseTLS, nil
+		return meta.ImapHost, meta.ImapPort, meta.Username, string(meta.Password), meta.UseTLS, nil
 	}
 	return cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.UseTLS, nil
 }
@@ -865,13 +859,20 @@ func (slf *TriggerPollerService) triggerJobs(trigger models.Trigger, events []ma
 			continue
 		}
 
-		// Execute job asynchronously
-		go func(jobID uint, passEventData bool, eventData []map[string]interface{}) {
-			err := slf.jobService.Execute(jobID)
+		slf.auditService.Record(AuditActor{}, AuditEvent{
+			Action:       models.AuditJobExecute,
+			ResourceType: models.AuditResourceJob,
+			ResourceID:   tj.JobID,
+			Details:      map[string]any{"triggerId": trigger.ID, "events": len(events)},
+		})
+
+		// Execute job asynchronously, the link's retry policy overrides the job's one
+		go func(jobID uint, policy *models.RetryPolicy, passEventData bool, eventData []map[string]interface{}) {
+			err := slf.jobService.ExecuteWithPolicy(jobID, policy, &trigger.ID)
 			if err != nil {
 				slf.logger.Error().Err(err).Uint("jobId", jobID).Msg("Failed to execute triggered job")
 			}
-		}(tj.JobID, tj.PassEventData, events)
+		}(tj.JobID, tj.RetryPolicy, tj.PassEventData, events)
 
 		triggered++
 	}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"api"
+	"api/internal/api/handler/request"
 	"api/internal/api/models"
 	"api/internal/api/repo"
 	"crypto/tls"
@@ -17,6 +18,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// ErrJobLinkNotFound is returned when a job is not linked to the trigger
+var ErrJobLinkNotFound = errors.New("job link not found")
+
 type TriggerService struct {
 	triggerRepo *repo.TriggerRepository
 	jobService  *JobService
@@ -80,7 +84,7 @@ func (slf *TriggerService) Create(trigger models.Trigger) (*models.Trigger, erro
 // Update updates a trigger's fields
 func (slf *TriggerService) Update(id uint, patch map[string]interface{}) (*models.Trigger, error) {
 	// Get existing trigger
-	_, err := slf.triggerRepo.FindByIDSimple(id)
+	existing, err := slf.triggerRepo.FindByIDSimple(id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("trigger not found")
@@ -88,6 +92,12 @@ func (slf *TriggerService) Update(id uint, patch map[string]interface{}) (*model
 		return nil, err
 	}
 
+	if config, ok := patch["config"].(models.TriggerConfig); ok {
+		if patch["config"], err = config.WithStoredSecrets(existing.Config); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := slf.triggerRepo.Db.Model(&models.Trigger{}).Where("id = ?", id).Updates(patch).Error; err != nil {
 		slf.logger.Error().Err(err).Uint("triggerId", id).Msg("Error updating trigger")
 		return nil, err
@@ -106,7 +116,10 @@ func (slf *TriggerService) UpdateConfig(id uint, config models.TriggerConfig) (*
 		return nil, err
 	}
 
-	trigger.Config = config
+	// Credentials are not returned by the API, blank ones are kept
+	if trigger.Config, err = config.WithStoredSecrets(trigger.Config); err != nil {
+		return nil, err
+	}
 	if err := slf.validateTriggerConfig(&trigger); err != nil {
 		return nil, err
 	}
@@ -236,15 +249,7 @@ func (slf *TriggerService) resolveConnection(cfg *models.DatabaseTriggerConfig)
 		if err := slf.triggerRepo.Db.First(&meta, *cfg.MetadataDatabaseID).Error; err != nil {
 			return models.DBConnectionConfig{}, fmt.Errorf("failed to load database metadata: %w", err)
 		}
-		return models.DBConnectionConfig{
-			Type:     meta.DbType,
-			Host:     meta.Host,
-			Port:     meta.Port,
-			Database: meta.DatabaseName,
-			Username: meta.User,
-			Password: meta.Password,
-			SSLMode:  meta.SSLMode,
-		}, nil
+		return meta.ConnectionConfig(), nil
 	}
 	if cfg.Connection != nil {
 		return *cfg.Connection, nil
@@ -306,7 +311,7 @@ func (slf *TriggerService) resolveEmailCredentials(cfg *models.EmailTriggerConfi
 		if err := slf.triggerRepo.Db.First(&meta, *cfg.MetadataEmailID).Error; err != nil {
 			return "", 0, "", "", false, fmt.Errorf("failed to load email metadata: %w", err)
 		}
-		return meta.ImapHost, meta.ImapPort, meta.Username, meta.Password, meta.UseTLS, nil
+		return meta.ImapHost, meta.ImapPort, meta.Username, string(meta.Password), meta.UseTLS, nil
 	}
 	return cfg.Host, cfg.Port, cfg.Username, cfg.Password, cfg.UseTLS, nil
 }
@@ -352,8 +357,8 @@ func (slf *TriggerService) DeleteRule(ruleID uint) error {
 	return slf.triggerRepo.DeleteRule(ruleID)
 }
 
-// LinkJob links a job to a trigger
-func (slf *TriggerService) LinkJob(triggerID, jobID uint, priority int, passEventData bool) (*models.TriggerJob, error) {
+// LinkJob links a job to a trigger, policy overrides the retry policy of the job when set
+func (slf *TriggerService) LinkJob(triggerID, jobID uint, priority int, passEventData bool, policy *models.RetryPolicy) (*models.TriggerJob, error) {
 	// Verify trigger exists
 	_, err := slf.triggerRepo.FindByIDSimple(triggerID)
 	if err != nil {
@@ -375,6 +380,7 @@ func (slf *TriggerService) LinkJob(triggerID, jobID uint, priority int, passEven
 		Priority:      priority,
 		Active:        true,
 		PassEventData: passEventData,
+		RetryPolicy:   policy,
 	}
 
 	if err := slf.triggerRepo.AddJob(&triggerJob); err != nil {
@@ -384,6 +390,36 @@ func (slf *TriggerService) LinkJob(triggerID, jobID uint, priority int, passEven
 	return &triggerJob, nil
 }
 
+// UpdateJobLink applies the set fields of the request to the link between a trigger and a job
+func (slf *TriggerService) UpdateJobLink(triggerID, jobID uint, req request.UpdateJobLink) (*models.TriggerJob, error) {
+	triggerJob, err := slf.triggerRepo.FindJobLink(triggerID, jobID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrJobLinkNotFound
+		}
+		return nil, err
+	}
+
+	if req.Priority != nil {
+		triggerJob.Priority = *req.Priority
+	}
+	if req.Active != nil {
+		triggerJob.Active = *req.Active
+	}
+	if req.PassEventData != nil {
+		triggerJob.PassEventData = *req.PassEventData
+	}
+	if req.RetryPolicy != nil {
+		triggerJob.RetryPolicy = req.RetryPolicy
+	}
+
+	if err := slf.triggerRepo.UpdateJobLink(&triggerJob); err != nil {
+		slf.logger.Error().Err(err).Uint("triggerId", triggerID).Uint("jobId", jobID).Msg("Error updating job link")
+		return nil, err
+	}
+	return &triggerJob, nil
+}
+
 // UnlinkJob removes a job from a trigger
 func (slf *TriggerService) UnlinkJob(triggerID, jobID uint) error {
 	return slf.triggerRepo.RemoveJob(triggerID, jobID)
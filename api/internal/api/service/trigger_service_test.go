@@ -2,6 +2,7 @@ package service
 
 import (
 	"api"
+	"api/internal/api/handler/request"
 	"api/internal/api/models"
 	"testing"
 
@@ -758,7 +759,8 @@ func TestTrigger_LinkJob(t *testing.T) {
 	require.NoError(t, err)
 	defer cleanupJob(t, createdJob.ID)
 
-	link, err := service.LinkJob(createdTrigger.ID, createdJob.ID, 1, true)
+	policy := &models.RetryPolicy{MaxAttempts: 3, DelaySeconds: 30}
+	link, err := service.LinkJob(createdTrigger.ID, createdJob.ID, 1, true, policy)
 	require.NoError(t, err, "Failed to link job to trigger")
 	require.NotNil(t, link)
 
@@ -767,6 +769,30 @@ func TestTrigger_LinkJob(t *testing.T) {
 	assert.Equal(t, 1, link.Priority)
 	assert.True(t, link.Active)
 	assert.True(t, link.PassEventData)
+
+	// The retry policy of the link is stored and can be updated
+	stored, err := service.triggerRepo.FindJobLink(createdTrigger.ID, createdJob.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.RetryPolicy)
+	assert.Equal(t, 3, stored.RetryPolicy.MaxAttempts)
+
+	active := false
+	updated, err := service.UpdateJobLink(createdTrigger.ID, createdJob.ID, request.UpdateJobLink{
+		Active:      &active,
+		RetryPolicy: &models.RetryPolicy{MaxAttempts: 5, Backoff: models.BackoffExponential, DelaySeconds: 10},
+	})
+	require.NoError(t, err)
+	assert.False(t, updated.Active)
+	assert.Equal(t, 1, updated.Priority, "unset fields are kept")
+
+	stored, err = service.triggerRepo.FindJobLink(createdTrigger.ID, createdJob.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.RetryPolicy)
+	assert.Equal(t, 5, stored.RetryPolicy.MaxAttempts)
+	assert.Equal(t, models.BackoffExponential, stored.RetryPolicy.Backoff)
+
+	_, err = service.UpdateJobLink(createdTrigger.ID, 99999, request.UpdateJobLink{})
+	assert.ErrorIs(t, err, ErrJobLinkNotFound)
 }
 
 func TestTrigger_UnlinkJob(t *testing.T) {
@@ -797,7 +823,7 @@ func TestTrigger_UnlinkJob(t *testing.T) {
 	require.NoError(t, err)
 	defer cleanupJob(t, createdJob.ID)
 
-	_, err = service.LinkJob(createdTrigger.ID, createdJob.ID, 0, false)
+	_, err = service.LinkJob(createdTrigger.ID, createdJob.ID, 0, false, nil)
 	require.NoError(t, err)
 
 	err = service.UnlinkJob(createdTrigger.ID, createdJob.ID)
@@ -809,7 +835,7 @@ func TestTrigger_LinkJob_TriggerNotFound(t *testing.T) {
 
 	service := NewTriggerService()
 
-	_, err := service.LinkJob(99999, 1, 0, false)
+	_, err := service.LinkJob(99999, 1, 0, false, nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "trigger not found")
 }
@@ -832,7 +858,7 @@ func TestTrigger_LinkJob_JobNotFound(t *testing.T) {
 	require.NoError(t, err)
 	defer cleanupTrigger(t, createdTrigger.ID)
 
-	_, err = service.LinkJob(createdTrigger.ID, 99999, 0, false)
+	_, err = service.LinkJob(createdTrigger.ID, 99999, 0, false, nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "job not found")
 }
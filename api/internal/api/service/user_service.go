@@ -16,22 +16,26 @@ import (
 )
 
 type UserService struct {
-	userRepo   *repo.UserRepository
-	config     api.AppConfig
-	logger     zerolog.Logger
-	userMapper mapper.UserMapper
+	userRepo       *repo.UserRepository
+	config         api.AppConfig
+	logger         zerolog.Logger
+	userMapper     mapper.UserMapper
+	ldapService    *LDAPService
+	sessionService *SessionService
 }
 
 func NewUserService() *UserService {
 	return &UserService{
-		userRepo:   repo.NewUserRepository(),
-		config:     api.GetConfig(),
-		logger:     api.Logger,
-		userMapper: mapper.NewUserMapper(),
+		userRepo:       repo.NewUserRepository(),
+		config:         api.GetConfig(),
+		logger:         api.Logger,
+		userMapper:     mapper.NewUserMapper(),
+		ldapService:    NewLDAPService(),
+		sessionService: NewSessionService(),
 	}
 }
 
-func (slf *UserService) Register(registerDTO request.RegisterDTO) (*response.AuthResponseDTO, error) {
+func (slf *UserService) Register(registerDTO request.RegisterDTO, client SessionClient) (*response.AuthResponseDTO, error) {
 	exists, err := slf.userRepo.ExistsByEmail(registerDTO.Email)
 	if err != nil {
 		slf.logger.Error().Err(err).Msg("Error checking if user exists")
@@ -61,74 +65,83 @@ func (slf *UserService) Register(registerDTO request.RegisterDTO) (*response.Aut
 		return nil, err
 	}
 
-	token, err := pkg.GenerateToken(user.ID, user.Email, user.Nom, user.Prenom, string(user.Role), slf.config.JWTConfig.Secret, slf.config.JWTConfig.Expiration)
+	authResponse, err := slf.issueTokens(&user, client)
 	if err != nil {
-		slf.logger.Error().Err(err).Msg("Error generating token")
-		return nil, err
-	}
-
-	refreshToken, err := pkg.GenerateRefreshToken(user.ID, slf.config.JWTConfig.Secret, 30) // 30 days
-	if err != nil {
-		slf.logger.Error().Err(err).Msg("Error generating refresh token")
-		return nil, err
-	}
-
-	user.RefreshToken = refreshToken
-	if err = slf.userRepo.Update(&user); err != nil {
-		slf.logger.Error().Err(err).Msg("Error updating user with refresh token")
 		return nil, err
 	}
 
 	slf.logger.Info().Uint("userId", user.ID).Msg("User registered successfully")
-	return &response.AuthResponseDTO{
-		Token:        token,
-		RefreshToken: refreshToken,
-		User:         slf.userMapper.EntityToUserResponse(user),
-	}, nil
+	return authResponse, nil
 }
 
-func (slf *UserService) Login(loginDTO request.LoginDTO) (*response.AuthResponseDTO, error) {
+func (slf *UserService) Login(loginDTO request.LoginDTO, client SessionClient) (*response.AuthResponseDTO, error) {
 	user, err := slf.userRepo.FindByEmail(loginDTO.Email)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("invalid email or password")
-		}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		slf.logger.Error().Err(err).Msg("Error finding user by email")
 		return nil, err
 	}
+	found := err == nil
 
-	if !user.Actif {
-		return nil, errors.New("account is inactive")
+	if found {
+		// Service accounts have no password, they authenticate with personal access tokens
+		if user.ServiceAccount {
+			return nil, errors.New("invalid email or password")
+		}
+		if !user.Actif {
+			return nil, errors.New("account is inactive")
+		}
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginDTO.Password)); err != nil {
-		return nil, errors.New("invalid email or password")
+	// Users linked to the directory only use their directory password, the others fall back to it
+	localPassword := found && user.LDAPDN == nil &&
+		bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(loginDTO.Password)) == nil
+	if !localPassword {
+		if !slf.ldapService.Enabled() {
+			return nil, errors.New("invalid email or password")
+		}
+		user, err = slf.ldapService.Authenticate(loginDTO.Email, loginDTO.Password)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	token, err := pkg.GenerateToken(user.ID, user.Email, user.Nom, user.Prenom, string(user.Role), slf.config.JWTConfig.Secret, slf.config.JWTConfig.Expiration)
+	authResponse, err := slf.issueTokens(&user, client)
 	if err != nil {
-		slf.logger.Error().Err(err).Msg("Error generating token")
 		return nil, err
 	}
 
-	refreshToken, err := pkg.GenerateRefreshToken(user.ID, slf.config.JWTConfig.Secret, slf.config.JWTConfig.RefreshExpiration)
+	slf.logger.Info().Uint("userId", user.ID).Msg("User logged in successfully")
+	return authResponse, nil
+}
+
+// issueTokens opens a session for a user on the client and generates its access and refresh tokens
+func (slf *UserService) issueTokens(user *models.User, client SessionClient) (*response.AuthResponseDTO, error) {
+	session, err := slf.sessionService.Start(user.ID, client)
 	if err != nil {
-		slf.logger.Error().Err(err).Msg("Error generating refresh token")
 		return nil, err
 	}
+	return slf.sessionTokens(user, session)
+}
 
-	user.RefreshToken = refreshToken
-	if err = slf.userRepo.Update(&user); err != nil {
-		slf.logger.Error().Err(err).Msg("Error updating user with refresh token")
+// sessionTokens generates the access token of a session and the refresh token of its current
+// generation
+func (slf *UserService) sessionTokens(user *models.User, session models.Session) (*response.AuthResponseDTO, error) {
+	token, err := pkg.GenerateToken(user.ID, session.ID, user.Email, user.Nom, user.Prenom, string(user.Role), slf.config.JWTConfig.Secret, slf.config.JWTConfig.Expiration)
+	if err != nil {
+		slf.logger.Error().Err(err).Msg("Error generating token")
 		return nil, err
 	}
 
-	slf.logger.Info().Uint("userId", user.ID).Msg("User logged in successfully")
+	refreshToken, err := pkg.GenerateRefreshToken(user.ID, session.ID, session.Generation, slf.config.JWTConfig.Secret, slf.config.JWTConfig.RefreshExpiration)
+	if err != nil {
+		slf.logger.Error().Err(err).Msg("Error generating refresh token")
+		return nil, err
+	}
 
 	return &response.AuthResponseDTO{
 		Token:        token,
 		RefreshToken: refreshToken,
-		User:         slf.userMapper.EntityToUserResponse(user),
+		User:         slf.userMapper.EntityToUserResponse(*user),
 	}, nil
 }
 
@@ -159,11 +172,13 @@ func (slf *UserService) SearchUsers(query string) ([]response.UserResponseDTO, e
 	return result, nil
 }
 
-func (slf *UserService) RefreshToken(refreshToken string) (response.AuthResponseDTO, error) {
+// RefreshToken rotates the session of a refresh token and issues the tokens of its next
+// generation. Presenting a token again revokes its session (ErrRefreshTokenReuse).
+func (slf *UserService) RefreshToken(refreshToken string, client SessionClient) (response.AuthResponseDTO, error) {
 	claims, err := pkg.ValidateRefreshToken(refreshToken, slf.config.JWTConfig.Secret)
-	if err != nil {
-		slf.logger.Error().Err(err).Msg("Invalid refresh token")
-		return response.AuthResponseDTO{}, errors.New("invalid or expired refresh token")
+	if err != nil || claims.SessionID == 0 {
+		slf.logger.Warn().Err(err).Msg("Invalid refresh token")
+		return response.AuthResponseDTO{}, ErrInvalidRefreshToken
 	}
 
 	user, err := slf.userRepo.FindByID(claims.UserID)
@@ -179,33 +194,16 @@ func (slf *UserService) RefreshToken(refreshToken string) (response.AuthResponse
 		return response.AuthResponseDTO{}, errors.New("account is inactive")
 	}
 
-	if user.RefreshToken != refreshToken {
-		slf.logger.Warn().Uint("userId", user.ID).Msg("Refresh token mismatch")
-		return response.AuthResponseDTO{}, errors.New("invalid refresh token")
-	}
-
-	token, err := pkg.GenerateToken(user.ID, user.Email, user.Nom, user.Prenom, string(user.Role), slf.config.JWTConfig.Secret, slf.config.JWTConfig.Expiration)
+	session, err := slf.sessionService.Rotate(claims, client)
 	if err != nil {
-		slf.logger.Error().Err(err).Msg("Error generating token")
 		return response.AuthResponseDTO{}, err
 	}
 
-	newRefreshToken, err := pkg.GenerateRefreshToken(user.ID, slf.config.JWTConfig.Secret, slf.config.JWTConfig.RefreshExpiration)
+	authResponse, err := slf.sessionTokens(&user, session)
 	if err != nil {
-		slf.logger.Error().Err(err).Msg("Error generating refresh token")
 		return response.AuthResponseDTO{}, err
 	}
 
-	user.RefreshToken = newRefreshToken
-	if err = slf.userRepo.Update(&user); err != nil {
-		slf.logger.Error().Err(err).Msg("Error updating user with refresh token")
-		return response.AuthResponseDTO{}, err
-	}
-
-	slf.logger.Info().Uint("userId", user.ID).Msg("Token refreshed successfully")
-	return response.AuthResponseDTO{
-		Token:        token,
-		RefreshToken: newRefreshToken,
-		User:         slf.userMapper.EntityToUserResponse(user),
-	}, nil
+	slf.logger.Info().Uint("userId", user.ID).Uint("sessionId", session.ID).Msg("Token refreshed successfully")
+	return *authResponse, nil
 }
@@ -15,8 +15,8 @@ import (
 func setupUserTestDB(t *testing.T) {
 	api.InitConfig("../../../.env.test")
 
-	err := api.DB.AutoMigrate(&models.User{})
-	require.NoError(t, err, "Failed to migrate user table")
+	err := api.DB.AutoMigrate(&models.User{}, &models.Session{})
+	require.NoError(t, err, "Failed to migrate user tables")
 }
 
 func cleanupUser(t *testing.T, id uint) {
@@ -42,7 +42,7 @@ func TestUser_Register(t *testing.T) {
 		Nom:      "Dupont",
 	}
 
-	result, err := service.Register(dto)
+	result, err := service.Register(dto, SessionClient{})
 	require.NoError(t, err, "Failed to register user")
 	require.NotNil(t, result)
 	defer cleanupUser(t, result.User.ID)
@@ -68,12 +68,12 @@ func TestUser_Register_DuplicateEmail(t *testing.T) {
 		Nom:      "Dupont",
 	}
 
-	result, err := service.Register(dto)
+	result, err := service.Register(dto, SessionClient{})
 	require.NoError(t, err)
 	defer cleanupUser(t, result.User.ID)
 
 	// Try to register again with the same email
-	_, err = service.Register(dto)
+	_, err = service.Register(dto, SessionClient{})
 	require.Error(t, err, "Should fail on duplicate email")
 	assert.Contains(t, err.Error(), "already exists")
 }
@@ -91,7 +91,7 @@ func TestUser_Login(t *testing.T) {
 		Prenom:   "Marie",
 		Nom:      "Martin",
 	}
-	regResult, err := service.Register(regDTO)
+	regResult, err := service.Register(regDTO, SessionClient{})
 	require.NoError(t, err)
 	defer cleanupUser(t, regResult.User.ID)
 
@@ -101,7 +101,7 @@ func TestUser_Login(t *testing.T) {
 		Password: "loginpassword",
 	}
 
-	loginResult, err := service.Login(loginDTO)
+	loginResult, err := service.Login(loginDTO, SessionClient{})
 	require.NoError(t, err, "Failed to login")
 	require.NotNil(t, loginResult)
 
@@ -122,7 +122,7 @@ func TestUser_Login_WrongPassword(t *testing.T) {
 		Prenom:   "Pierre",
 		Nom:      "Durand",
 	}
-	regResult, err := service.Register(regDTO)
+	regResult, err := service.Register(regDTO, SessionClient{})
 	require.NoError(t, err)
 	defer cleanupUser(t, regResult.User.ID)
 
@@ -131,7 +131,7 @@ func TestUser_Login_WrongPassword(t *testing.T) {
 		Password: "wrongpassword",
 	}
 
-	_, err = service.Login(loginDTO)
+	_, err = service.Login(loginDTO, SessionClient{})
 	require.Error(t, err, "Should fail on wrong password")
 	assert.Equal(t, "invalid email or password", err.Error())
 }
@@ -146,7 +146,7 @@ func TestUser_Login_WrongEmail(t *testing.T) {
 		Password: "anything",
 	}
 
-	_, err := service.Login(loginDTO)
+	_, err := service.Login(loginDTO, SessionClient{})
 	require.Error(t, err, "Should fail on wrong email")
 	assert.Equal(t, "invalid email or password", err.Error())
 }
@@ -164,7 +164,7 @@ func TestUser_Login_InactiveAccount(t *testing.T) {
 		Prenom:   "Inactive",
 		Nom:      "User",
 	}
-	regResult, err := service.Register(regDTO)
+	regResult, err := service.Register(regDTO, SessionClient{})
 	require.NoError(t, err)
 	defer cleanupUser(t, regResult.User.ID)
 
@@ -176,7 +176,7 @@ func TestUser_Login_InactiveAccount(t *testing.T) {
 		Password: "testpassword",
 	}
 
-	_, err = service.Login(loginDTO)
+	_, err = service.Login(loginDTO, SessionClient{})
 	require.Error(t, err, "Should fail on inactive account")
 	assert.Equal(t, "account is inactive", err.Error())
 }
@@ -193,7 +193,7 @@ func TestUser_GetByID(t *testing.T) {
 		Prenom:   "GetBy",
 		Nom:      "ID",
 	}
-	regResult, err := service.Register(regDTO)
+	regResult, err := service.Register(regDTO, SessionClient{})
 	require.NoError(t, err)
 	defer cleanupUser(t, regResult.User.ID)
 
@@ -228,12 +228,12 @@ func TestUser_RefreshToken(t *testing.T) {
 		Prenom:   "Refresh",
 		Nom:      "Token",
 	}
-	regResult, err := service.Register(regDTO)
+	regResult, err := service.Register(regDTO, SessionClient{})
 	require.NoError(t, err)
 	defer cleanupUser(t, regResult.User.ID)
 
 	// Use the refresh token to get new tokens
-	refreshResult, err := service.RefreshToken(regResult.RefreshToken)
+	refreshResult, err := service.RefreshToken(regResult.RefreshToken, SessionClient{})
 	require.NoError(t, err, "Failed to refresh token")
 
 	assert.NotEmpty(t, refreshResult.Token)
@@ -248,12 +248,12 @@ func TestUser_RefreshToken_Invalid(t *testing.T) {
 
 	service := NewUserService()
 
-	_, err := service.RefreshToken("not-a-real-token")
+	_, err := service.RefreshToken("not-a-real-token", SessionClient{})
 	require.Error(t, err, "Should fail on invalid refresh token")
 	assert.Contains(t, err.Error(), "invalid or expired refresh token")
 }
 
-func TestUser_RefreshToken_Mismatch(t *testing.T) {
+func TestUser_RefreshToken_Reuse(t *testing.T) {
 	setupUserTestDB(t)
 
 	service := NewUserService()
@@ -262,25 +262,30 @@ func TestUser_RefreshToken_Mismatch(t *testing.T) {
 	regDTO := request.RegisterDTO{
 		Email:    email,
 		Password: "testpassword",
-		Prenom:   "Mismatch",
+		Prenom:   "Reuse",
 		Nom:      "Test",
 	}
-	regResult, err := service.Register(regDTO)
+	regResult, err := service.Register(regDTO, SessionClient{})
 	require.NoError(t, err)
 	defer cleanupUser(t, regResult.User.ID)
 
-	oldRefreshToken := regResult.RefreshToken
-
-	// Change the stored refresh token by logging in again (which generates new tokens)
+	// Logging in again opens another session, the first one stays usable
 	loginDTO := request.LoginDTO{
 		Email:    email,
 		Password: "testpassword",
 	}
-	_, err = service.Login(loginDTO)
+	loginResult, err := service.Login(loginDTO, SessionClient{UserAgent: "other device"})
+	require.NoError(t, err)
+
+	rotated, err := service.RefreshToken(regResult.RefreshToken, SessionClient{})
 	require.NoError(t, err)
 
-	// Try to use the old refresh token — it should be mismatched
-	_, err = service.RefreshToken(oldRefreshToken)
-	require.Error(t, err, "Should fail on mismatched refresh token")
-	assert.Contains(t, err.Error(), "invalid refresh token")
+	// The rotated token is used again: the session and its newest token are revoked
+	_, err = service.RefreshToken(regResult.RefreshToken, SessionClient{})
+	require.ErrorIs(t, err, ErrRefreshTokenReuse)
+	_, err = service.RefreshToken(rotated.RefreshToken, SessionClient{})
+	require.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	_, err = service.RefreshToken(loginResult.RefreshToken, SessionClient{})
+	require.NoError(t, err, "Other sessions are not affected")
 }
@@ -0,0 +1,201 @@
+// Package directory checks passwords against an LDAP directory (OpenLDAP, Active Directory): the
+// user entry is searched with a service account, then the password is checked by binding as it.
+package directory
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+// ErrInvalidCredentials is returned when the user is not found, ambiguous, or the password is wrong
+var ErrInvalidCredentials = errors.New("invalid directory credentials")
+
+// UsernamePlaceholder is replaced by the escaped login in Config.UserFilter
+const UsernamePlaceholder = "{username}"
+
+// Config locates the directory and the user entries
+type Config struct {
+	// URL is ldap://host:389 or ldaps://host:636
+	URL string
+	// StartTLS upgrades an ldap:// connection before binding
+	StartTLS           bool
+	InsecureSkipVerify bool
+	// BindDN and BindPassword search the user entries, anonymous search when BindDN is empty
+	BindDN       string
+	BindPassword string
+	// UserBaseDN is searched (whole subtree) with UserFilter, where {username} is the login
+	UserBaseDN string
+	UserFilter string
+	// Attributes read from the user entry
+	EmailAttribute     string
+	FirstNameAttribute string
+	LastNameAttribute  string
+	GroupAttribute     string
+	Timeout            time.Duration
+}
+
+// Entry is a user of the directory
+type Entry struct {
+	DN        string
+	Email     string
+	FirstName string
+	LastName  string
+	// Groups are the DNs of the groups of the user (memberOf)
+	Groups []string
+}
+
+// MemberOf reports whether the entry is in one of groups, given by DN or by common name
+func (e Entry) MemberOf(groups []string) bool {
+	for _, group := range e.Groups {
+		cn := commonName(group)
+		for _, want := range groups {
+			if strings.EqualFold(group, want) || (cn != "" && strings.EqualFold(cn, want)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// commonName returns the value of the first RDN of dn ("admins" for "cn=admins,ou=groups,dc=corp")
+func commonName(dn string) string {
+	parsed, err := ldap.ParseDN(dn)
+	if err != nil || len(parsed.RDNs) == 0 || len(parsed.RDNs[0].Attributes) == 0 {
+		return ""
+	}
+	return parsed.RDNs[0].Attributes[0].Value
+}
+
+// Directory authenticates users against an LDAP server. A connection is opened per call.
+type Directory struct {
+	config Config
+}
+
+func New(cfg Config) *Directory {
+	if cfg.UserFilter == "" {
+		cfg.UserFilter = "(&(objectClass=person)(|(uid={username})(mail={username})))"
+	}
+	if cfg.EmailAttribute == "" {
+		cfg.EmailAttribute = "mail"
+	}
+	if cfg.FirstNameAttribute == "" {
+		cfg.FirstNameAttribute = "givenName"
+	}
+	if cfg.LastNameAttribute == "" {
+		cfg.LastNameAttribute = "sn"
+	}
+	if cfg.GroupAttribute == "" {
+		cfg.GroupAttribute = "memberOf"
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	return &Directory{config: cfg}
+}
+
+// Authenticate finds the entry of username and checks its password
+func (d *Directory) Authenticate(username, password string) (*Entry, error) {
+	// An empty password is an unauthenticated bind, which many servers accept
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := d.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entry, err := d.search(conn, d.config.UserBaseDN, ldap.ScopeWholeSubtree, userFilter(d.config.UserFilter, username))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("ldap bind as %s: %w", entry.DN, err)
+	}
+	return entry, nil
+}
+
+// Lookup reads the entry dn, ErrInvalidCredentials when it no longer exists
+func (d *Directory) Lookup(dn string) (*Entry, error) {
+	conn, err := d.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	entry, err := d.search(conn, dn, ldap.ScopeBaseObject, "(objectClass=*)")
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return nil, ErrInvalidCredentials
+	}
+	return entry, err
+}
+
+// connect opens a connection bound as the search account
+func (d *Directory) connect() (*ldap.Conn, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: d.config.InsecureSkipVerify}
+	conn, err := ldap.DialURL(d.config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: d.config.Timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ldap connect: %w", err)
+	}
+	conn.SetTimeout(d.config.Timeout)
+
+	if d.config.StartTLS {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldap starttls: %w", err)
+		}
+	}
+	if d.config.BindDN != "" {
+		if err := conn.Bind(d.config.BindDN, d.config.BindPassword); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ldap bind as %s: %w", d.config.BindDN, err)
+		}
+	}
+	return conn, nil
+}
+
+// search returns the only entry matching filter
+func (d *Directory) search(conn *ldap.Conn, baseDN string, scope int, filter string) (*Entry, error) {
+	cfg := d.config
+	req := ldap.NewSearchRequest(baseDN, scope, ldap.NeverDerefAliases, 2, int(cfg.Timeout.Seconds()), false, filter,
+		[]string{cfg.EmailAttribute, cfg.FirstNameAttribute, cfg.LastNameAttribute, cfg.GroupAttribute}, nil)
+
+	result, err := conn.Search(req)
+	if ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ldap search: %w", err)
+	}
+	if len(result.Entries) != 1 {
+		return nil, ErrInvalidCredentials
+	}
+
+	e := result.Entries[0]
+	return &Entry{
+		DN:        e.DN,
+		Email:     e.GetAttributeValue(cfg.EmailAttribute),
+		FirstName: e.GetAttributeValue(cfg.FirstNameAttribute),
+		LastName:  e.GetAttributeValue(cfg.LastNameAttribute),
+		Groups:    e.GetAttributeValues(cfg.GroupAttribute),
+	}, nil
+}
+
+// userFilter puts the escaped username in filter
+func userFilter(filter, username string) string {
+	return strings.ReplaceAll(filter, UsernamePlaceholder, ldap.EscapeFilter(username))
+}
@@ -0,0 +1,54 @@
+package directory
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUserFilter(t *testing.T) {
+	filter := "(&(objectClass=user)(|(sAMAccountName={username})(mail={username})))"
+
+	got := userFilter(filter, "jdoe")
+	if got != "(&(objectClass=user)(|(sAMAccountName=jdoe)(mail=jdoe)))" {
+		t.Errorf("userFilter = %s", got)
+	}
+
+	// The login cannot change the filter
+	got = userFilter(filter, "*)(objectClass=*")
+	if got != `(&(objectClass=user)(|(sAMAccountName=\2a\29\28objectClass=\2a)(mail=\2a\29\28objectClass=\2a)))` {
+		t.Errorf("userFilter = %s", got)
+	}
+}
+
+func TestEntry_MemberOf(t *testing.T) {
+	entry := Entry{Groups: []string{
+		"CN=Domain Users,CN=Users,DC=corp,DC=example",
+		"cn=dos-admins,ou=groups,dc=corp,dc=example",
+	}}
+
+	tests := []struct {
+		groups []string
+		want   bool
+	}{
+		{[]string{"cn=dos-admins,ou=groups,dc=corp,dc=example"}, true},
+		{[]string{"CN=DOS-ADMINS,OU=Groups,DC=corp,DC=example"}, true},
+		{[]string{"dos-admins"}, true},
+		{[]string{"staff", "Domain Users"}, true},
+		{[]string{"cn=dos-admins,ou=other,dc=corp,dc=example"}, false},
+		{[]string{"admins"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := entry.MemberOf(tt.groups); got != tt.want {
+			t.Errorf("MemberOf(%v) = %v, want %v", tt.groups, got, tt.want)
+		}
+	}
+}
+
+func TestAuthenticate_EmptyPassword(t *testing.T) {
+	// Refused before connecting: an empty password would be an unauthenticated bind
+	d := New(Config{URL: "ldap://127.0.0.1:1"})
+	if _, err := d.Authenticate("jdoe", ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("expected ErrInvalidCredentials, got %v", err)
+	}
+}
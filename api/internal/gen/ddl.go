@@ -0,0 +1,342 @@
+package gen
+
+import (
+	"api/internal/api/models"
+	"fmt"
+	"strings"
+)
+
+// columnKind is the portable family of a column type, rendered by each dialect
+type columnKind int
+
+const (
+	kindText columnKind = iota
+	kindString
+	kindSmallInt
+	kindInt
+	kindBigInt
+	kindDecimal
+	kindFloat
+	kindBool
+	kindDate
+	kindTime
+	kindTimestamp
+	kindBytes
+	kindJSON
+	kindUUID
+)
+
+// columnKindOf maps the database type of a column, or its Go type when the database type
+// is unknown, to a column kind
+func columnKindOf(col models.DataModel) columnKind {
+	dbType := strings.ToLower(strings.TrimSpace(col.Type))
+	unsigned := strings.Contains(dbType, "unsigned")
+	dbType = strings.TrimSpace(strings.Replace(dbType, "unsigned", "", 1))
+	if i := strings.IndexByte(dbType, '('); i >= 0 {
+		dbType = strings.TrimSpace(dbType[:i])
+	}
+
+	// MySQL unsigned integers ("UNSIGNED INT" from the driver, "int unsigned" from the catalog)
+	// are widened to the next integer kind so every value fits
+	if unsigned {
+		switch dbType {
+		case "tinyint":
+			return kindSmallInt
+		case "smallint", "mediumint":
+			return kindInt
+		case "int", "integer", "bigint":
+			return kindBigInt
+		}
+	}
+
+	switch dbType {
+	case "varchar", "character varying", "nvarchar", "char", "character", "nchar", "bpchar":
+		if col.Length > 0 {
+			return kindString
+		}
+		return kindText
+	case "text", "ntext", "tinytext", "mediumtext", "longtext", "citext", "xml":
+		return kindText
+	case "smallint", "int2", "tinyint", "utinyint":
+		return kindSmallInt
+	case "integer", "int", "int4", "mediumint", "serial", "usmallint":
+		return kindInt
+	case "bigint", "int8", "bigserial", "uinteger", "ubigint":
+		return kindBigInt
+	case "numeric", "decimal", "money", "smallmoney", "hugeint", "uhugeint":
+		return kindDecimal
+	case "real", "float", "float4", "float8", "double", "double precision":
+		return kindFloat
+	case "boolean", "bool", "bit":
+		return kindBool
+	case "date":
+		return kindDate
+	case "time", "time without time zone", "time with time zone":
+		return kindTime
+	case "timestamp", "timestamp without time zone", "timestamp with time zone", "timestamptz",
+		"datetime", "datetime2", "smalldatetime", "datetimeoffset":
+		return kindTimestamp
+	case "bytea", "blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary", "image":
+		return kindBytes
+	case "json", "jsonb":
+		return kindJSON
+	case "uuid", "uniqueidentifier":
+		return kindUUID
+	}
+
+	switch goType := col.GoType; {
+	case strings.Contains(goType, "Time"):
+		return kindTimestamp
+	case strings.Contains(goType, "bool"), strings.Contains(goType, "Bool"):
+		return kindBool
+	case strings.Contains(goType, "float"), strings.Contains(goType, "Float"):
+		return kindFloat
+	case strings.Contains(goType, "int32"), strings.Contains(goType, "Int32"):
+		return kindInt
+	case strings.Contains(goType, "int"), strings.Contains(goType, "Int"):
+		return kindBigInt
+	case goType == "[]byte", goType == "[]uint8":
+		return kindBytes
+	default:
+		return kindText
+	}
+}
+
+// tableColumn returns a column definition
+func tableColumn(d Dialect, col models.DataModel, key bool) string {
+	// Keys cannot be unbounded text on SQL Server and MySQL
+	if key && columnKindOf(col) == kindText {
+		col.Type = "varchar"
+		col.Length = 255
+	}
+	def := d.QuoteIdent(col.Name) + " " + d.ColumnType(col)
+	if key {
+		def += " NOT NULL"
+	}
+	return def
+}
+
+// CreateTableDDL returns the CREATE TABLE statement of a table holding columns, with keys as primary key.
+// Key columns are NOT NULL, the other columns are nullable.
+func CreateTableDDL(dbType models.DBType, schema, table string, columns []models.DataModel, keys []string) string {
+	d := dialectFor(dbType)
+
+	keySet := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		keySet[k] = true
+	}
+
+	defs := make([]string, 0, len(columns)+1)
+	for _, col := range columns {
+		defs = append(defs, tableColumn(d, col, keySet[col.Name]))
+	}
+	if len(keys) > 0 {
+		defs = append(defs, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(quoteColumns(d, keys), ", ")))
+	}
+
+	return fmt.Sprintf("CREATE TABLE %s (\n  %s\n)", quoteTable(d, schema, table), strings.Join(defs, ",\n  "))
+}
+
+// AddColumnDDL returns the ALTER TABLE statement adding a nullable column
+func AddColumnDDL(dbType models.DBType, schema, table string, col models.DataModel) string {
+	d := dialectFor(dbType)
+	keyword := "ADD COLUMN"
+	if dbType == models.DBTypeSQLServer {
+		keyword = "ADD"
+	}
+	return fmt.Sprintf("ALTER TABLE %s %s %s", quoteTable(d, schema, table), keyword, tableColumn(d, col, false))
+}
+
+// TableDDL returns the statements bringing a table to columns: CREATE TABLE when it has no
+// existing columns, otherwise an ALTER TABLE per missing column
+func TableDDL(dbType models.DBType, schema, table string, columns []models.DataModel, keys, existing []string) []string {
+	if len(existing) == 0 {
+		return []string{CreateTableDDL(dbType, schema, table, columns, keys)}
+	}
+
+	present := make(map[string]bool, len(existing))
+	for _, name := range existing {
+		present[strings.ToLower(name)] = true
+	}
+
+	var statements []string
+	for _, col := range columns {
+		if !present[strings.ToLower(col.Name)] {
+			statements = append(statements, AddColumnDDL(dbType, schema, table, col))
+		}
+	}
+	return statements
+}
+
+// decimalType renders a DECIMAL type, keeping the precision within limit
+func decimalType(name string, col models.DataModel, limit int64) string {
+	if col.Precision <= 0 {
+		return fmt.Sprintf("%s(38, 10)", name)
+	}
+	precision, scale := min(col.Precision, limit), col.Scale
+	if scale > precision {
+		scale = precision
+	}
+	return fmt.Sprintf("%s(%d, %d)", name, precision, scale)
+}
+
+func (postgresDialect) ColumnType(col models.DataModel) string {
+	switch columnKindOf(col) {
+	case kindString:
+		return fmt.Sprintf("VARCHAR(%d)", col.Length)
+	case kindSmallInt:
+		return "SMALLINT"
+	case kindInt:
+		return "INTEGER"
+	case kindBigInt:
+		return "BIGINT"
+	case kindDecimal:
+		return decimalType("NUMERIC", col, 1000)
+	case kindFloat:
+		return "DOUBLE PRECISION"
+	case kindBool:
+		return "BOOLEAN"
+	case kindDate:
+		return "DATE"
+	case kindTime:
+		return "TIME"
+	case kindTimestamp:
+		return "TIMESTAMP"
+	case kindBytes:
+		return "BYTEA"
+	case kindJSON:
+		return "JSONB"
+	case kindUUID:
+		return "UUID"
+	default:
+		return "TEXT"
+	}
+}
+
+func (sqlServerDialect) ColumnType(col models.DataModel) string {
+	switch columnKindOf(col) {
+	case kindString:
+		if col.Length > 4000 {
+			return "NVARCHAR(MAX)"
+		}
+		return fmt.Sprintf("NVARCHAR(%d)", col.Length)
+	case kindSmallInt:
+		return "SMALLINT"
+	case kindInt:
+		return "INT"
+	case kindBigInt:
+		return "BIGINT"
+	case kindDecimal:
+		return decimalType("DECIMAL", col, 38)
+	case kindFloat:
+		return "FLOAT"
+	case kindBool:
+		return "BIT"
+	case kindDate:
+		return "DATE"
+	case kindTime:
+		return "TIME"
+	case kindTimestamp:
+		return "DATETIME2"
+	case kindBytes:
+		return "VARBINARY(MAX)"
+	case kindUUID:
+		return "UNIQUEIDENTIFIER"
+	default:
+		return "NVARCHAR(MAX)"
+	}
+}
+
+func (mysqlDialect) ColumnType(col models.DataModel) string {
+	switch columnKindOf(col) {
+	case kindString:
+		if col.Length > 16383 {
+			return "LONGTEXT"
+		}
+		return fmt.Sprintf("VARCHAR(%d)", col.Length)
+	case kindSmallInt:
+		return "SMALLINT"
+	case kindInt:
+		return "INT"
+	case kindBigInt:
+		return "BIGINT"
+	case kindDecimal:
+		return decimalType("DECIMAL", col, 65)
+	case kindFloat:
+		return "DOUBLE"
+	case kindBool:
+		return "TINYINT(1)"
+	case kindDate:
+		return "DATE"
+	case kindTime:
+		return "TIME"
+	case kindTimestamp:
+		return "DATETIME(6)"
+	case kindBytes:
+		return "LONGBLOB"
+	case kindJSON:
+		return "JSON"
+	case kindUUID:
+		return "CHAR(36)"
+	default:
+		return "LONGTEXT"
+	}
+}
+
+// ColumnType uses the type names SQLite maps to its storage classes; DATE and TIMESTAMP are kept
+// so the driver reads the values back as time.Time
+func (sqliteDialect) ColumnType(col models.DataModel) string {
+	switch columnKindOf(col) {
+	case kindString:
+		return fmt.Sprintf("VARCHAR(%d)", col.Length)
+	case kindSmallInt, kindInt, kindBigInt:
+		return "INTEGER"
+	case kindDecimal:
+		return decimalType("NUMERIC", col, 1000)
+	case kindFloat:
+		return "REAL"
+	case kindBool:
+		return "BOOLEAN"
+	case kindDate:
+		return "DATE"
+	case kindTimestamp:
+		return "TIMESTAMP"
+	case kindBytes:
+		return "BLOB"
+	default:
+		return "TEXT"
+	}
+}
+
+func (duckDBDialect) ColumnType(col models.DataModel) string {
+	switch columnKindOf(col) {
+	case kindString:
+		return fmt.Sprintf("VARCHAR(%d)", col.Length)
+	case kindSmallInt:
+		return "SMALLINT"
+	case kindInt:
+		return "INTEGER"
+	case kindBigInt:
+		return "BIGINT"
+	case kindDecimal:
+		return decimalType("DECIMAL", col, 38)
+	case kindFloat:
+		return "DOUBLE"
+	case kindBool:
+		return "BOOLEAN"
+	case kindDate:
+		return "DATE"
+	case kindTime:
+		return "TIME"
+	case kindTimestamp:
+		return "TIMESTAMP"
+	case kindBytes:
+		return "BLOB"
+	case kindJSON:
+		return "JSON"
+	case kindUUID:
+		return "UUID"
+	default:
+		return "VARCHAR"
+	}
+}
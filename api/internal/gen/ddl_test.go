@@ -0,0 +1,104 @@
+package gen
+
+import (
+	"api/internal/api/models"
+	"reflect"
+	"testing"
+)
+
+var ddlColumns = []models.DataModel{
+	{Name: "id", Type: "integer"},
+	{Name: "code", Type: "text"},
+	{Name: "name", Type: "character varying", Length: 120},
+	{Name: "amount", Type: "numeric", Precision: 12, Scale: 2},
+	{Name: "created_at", Type: "timestamp without time zone"},
+	{Name: "active", GoType: "bool"},
+}
+
+func TestCreateTableDDL(t *testing.T) {
+	tests := []struct {
+		dbType models.DBType
+		want   string
+	}{
+		{
+			dbType: models.DBTypePostgres,
+			want: `CREATE TABLE "public"."customers" (
+  "id" INTEGER NOT NULL,
+  "code" VARCHAR(255) NOT NULL,
+  "name" VARCHAR(120),
+  "amount" NUMERIC(12, 2),
+  "created_at" TIMESTAMP,
+  "active" BOOLEAN,
+  PRIMARY KEY ("id", "code")
+)`,
+		},
+		{
+			dbType: models.DBTypeSQLServer,
+			want: `CREATE TABLE [public].[customers] (
+  [id] INT NOT NULL,
+  [code] NVARCHAR(255) NOT NULL,
+  [name] NVARCHAR(120),
+  [amount] DECIMAL(12, 2),
+  [created_at] DATETIME2,
+  [active] BIT,
+  PRIMARY KEY ([id], [code])
+)`,
+		},
+		{
+			dbType: models.DBTypeMySQL,
+			want: "CREATE TABLE `public`.`customers` (\n" +
+				"  `id` INT NOT NULL,\n" +
+				"  `code` VARCHAR(255) NOT NULL,\n" +
+				"  `name` VARCHAR(120),\n" +
+				"  `amount` DECIMAL(12, 2),\n" +
+				"  `created_at` DATETIME(6),\n" +
+				"  `active` TINYINT(1),\n" +
+				"  PRIMARY KEY (`id`, `code`)\n" +
+				")",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.dbType), func(t *testing.T) {
+			got := CreateTableDDL(tt.dbType, "public", "customers", ddlColumns, []string{"id", "code"})
+			if got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestColumnKindOf_MySQLUnsigned(t *testing.T) {
+	tests := []struct {
+		dbType string
+		want   columnKind
+	}{
+		{"UNSIGNED TINYINT", kindSmallInt},
+		{"UNSIGNED INT", kindBigInt},
+		{"int unsigned", kindBigInt},
+		{"smallint(5) unsigned", kindInt},
+		{"UNSIGNED BIGINT", kindBigInt},
+	}
+
+	for _, tt := range tests {
+		if got := columnKindOf(models.DataModel{Type: tt.dbType}); got != tt.want {
+			t.Errorf("columnKindOf(%q) = %v, want %v", tt.dbType, got, tt.want)
+		}
+	}
+}
+
+func TestTableDDL_AddsMissingColumns(t *testing.T) {
+	got := TableDDL(models.DBTypeSQLServer, "dbo", "customers", ddlColumns, []string{"id"}, []string{"ID", "code", "name", "amount"})
+	want := []string{
+		"ALTER TABLE [dbo].[customers] ADD [created_at] DATETIME2",
+		"ALTER TABLE [dbo].[customers] ADD [active] BIT",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	got = TableDDL(models.DBTypePostgres, "", "customers", ddlColumns[:1], nil, []string{"id"})
+	if len(got) != 0 {
+		t.Errorf("expected no statement for an up-to-date table, got %q", got)
+	}
+}
@@ -0,0 +1,336 @@
+package gen
+
+import (
+	"api/internal/api/models"
+	"fmt"
+	"strings"
+)
+
+// Dialect describes how generated code talks SQL to a target database
+type Dialect interface {
+	// Name returns the database/sql driver name
+	Name() string
+
+	// QuoteIdent quotes a single identifier
+	QuoteIdent(name string) string
+
+	// Placeholder returns the placeholder of the n-th (1-based) statement parameter
+	Placeholder(n int) string
+
+	// PlaceholderExpr returns a Go expression building the placeholder of the parameter
+	// whose 1-based index is computed by indexExpr at runtime
+	PlaceholderExpr(indexExpr string) string
+
+	// MaxParams returns the maximum number of parameters of a single statement
+	MaxParams() int
+
+	// MaxRows returns the maximum number of rows of a multi-row VALUES list (0 = no limit)
+	MaxRows() int
+
+	// UpsertQuery returns the statement inserting or updating rows matched on keys,
+	// split around the comma-separated list of row tuples
+	UpsertQuery(table string, columns, keys []string) (prefix, suffix string)
+
+	// RowValueIn reports whether "(a, b) IN ((...), (...))" is supported
+	RowValueIn() bool
+
+	// NullSafeEqual returns a condition true when both operands are equal or both NULL
+	NullSafeEqual(left, right string) string
+
+	// TruncateStatement returns the statement emptying a table, which must not commit an
+	// enclosing transaction when transactional is set
+	TruncateStatement(table string, transactional bool) string
+
+	// SavepointStatements returns the statements setting a savepoint and rolling back to it,
+	// both empty when the database has no savepoints
+	SavepointStatements(name string) (set, rollback string)
+
+	// ColumnType returns the column type of a data model in CREATE and ALTER TABLE statements
+	ColumnType(col models.DataModel) string
+}
+
+// dialectFor returns the dialect of a database type, PostgreSQL by default
+func dialectFor(dbType models.DBType) Dialect {
+	switch dbType {
+	case models.DBTypeSQLServer:
+		return sqlServerDialect{}
+	case models.DBTypeMySQL:
+		return mysqlDialect{}
+	case models.DBTypeSQLite:
+		return sqliteDialect{}
+	case models.DBTypeDuckDB:
+		return duckDBDialect{}
+	default:
+		return postgresDialect{}
+	}
+}
+
+// quoteTable quotes a table name, prefixed by its schema when set
+func quoteTable(d Dialect, schema, table string) string {
+	if schema == "" {
+		return d.QuoteIdent(table)
+	}
+	return d.QuoteIdent(schema) + "." + d.QuoteIdent(table)
+}
+
+// quoteColumns quotes a list of column names
+func quoteColumns(d Dialect, columns []string) []string {
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = d.QuoteIdent(col)
+	}
+	return quoted
+}
+
+// batchSizeFor caps a batch size so a multi-row statement with paramsPerRow parameters
+// per row stays within the dialect limits
+func batchSizeFor(d Dialect, batchSize, paramsPerRow int) int {
+	if paramsPerRow > 0 {
+		if limit := d.MaxParams() / paramsPerRow; batchSize > limit {
+			batchSize = limit
+		}
+	}
+	if limit := d.MaxRows(); limit > 0 && batchSize > limit {
+		batchSize = limit
+	}
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	return batchSize
+}
+
+// nonKeyColumns returns the columns that are not part of keys
+func nonKeyColumns(columns, keys []string) []string {
+	keySet := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		keySet[k] = true
+	}
+	var result []string
+	for _, col := range columns {
+		if !keySet[col] {
+			result = append(result, col)
+		}
+	}
+	return result
+}
+
+// postgresDialect targets PostgreSQL (lib/pq)
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string { return "postgres" }
+
+func (postgresDialect) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (postgresDialect) Placeholder(n int) string { return fmt.Sprintf("$%d", n) }
+
+func (postgresDialect) PlaceholderExpr(indexExpr string) string {
+	return fmt.Sprintf(`fmt.Sprintf("$%%d", %s)`, indexExpr)
+}
+
+func (postgresDialect) MaxParams() int { return 65535 }
+
+func (postgresDialect) MaxRows() int { return 0 }
+
+func (d postgresDialect) UpsertQuery(table string, columns, keys []string) (string, string) {
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", table, strings.Join(quoteColumns(d, columns), ", "))
+
+	updates := nonKeyColumns(columns, keys)
+	if len(updates) == 0 {
+		return prefix, fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(quoteColumns(d, keys), ", "))
+	}
+	sets := make([]string, len(updates))
+	for i, col := range updates {
+		sets[i] = fmt.Sprintf("%s = EXCLUDED.%s", d.QuoteIdent(col), d.QuoteIdent(col))
+	}
+	return prefix, fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s",
+		strings.Join(quoteColumns(d, keys), ", "), strings.Join(sets, ", "))
+}
+
+func (postgresDialect) RowValueIn() bool { return true }
+
+func (postgresDialect) TruncateStatement(table string, _ bool) string {
+	return "TRUNCATE TABLE " + table
+}
+
+func (postgresDialect) SavepointStatements(name string) (string, string) {
+	return "SAVEPOINT " + name, "ROLLBACK TO SAVEPOINT " + name
+}
+
+func (postgresDialect) NullSafeEqual(left, right string) string {
+	return left + " IS NOT DISTINCT FROM " + right
+}
+
+// sqlServerDialect targets SQL Server (go-mssqldb)
+type sqlServerDialect struct{}
+
+func (sqlServerDialect) Name() string { return "sqlserver" }
+
+func (sqlServerDialect) QuoteIdent(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+func (sqlServerDialect) Placeholder(n int) string { return fmt.Sprintf("@p%d", n) }
+
+func (sqlServerDialect) PlaceholderExpr(indexExpr string) string {
+	return fmt.Sprintf(`fmt.Sprintf("@p%%d", %s)`, indexExpr)
+}
+
+// MaxParams is the RPC parameter limit of SQL Server
+func (sqlServerDialect) MaxParams() int { return 2100 }
+
+// MaxRows is the row limit of a table value constructor in INSERT ... VALUES
+func (sqlServerDialect) MaxRows() int { return 1000 }
+
+func (d sqlServerDialect) UpsertQuery(table string, columns, keys []string) (string, string) {
+	quoted := quoteColumns(d, columns)
+	sourceCols := make([]string, len(columns))
+	for i, col := range columns {
+		sourceCols[i] = "source." + d.QuoteIdent(col)
+	}
+	matches := make([]string, len(keys))
+	for i, key := range keys {
+		matches[i] = fmt.Sprintf("target.%s = source.%s", d.QuoteIdent(key), d.QuoteIdent(key))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, ") AS source (%s) ON %s", strings.Join(quoted, ", "), strings.Join(matches, " AND "))
+	if updates := nonKeyColumns(columns, keys); len(updates) > 0 {
+		sets := make([]string, len(updates))
+		for i, col := range updates {
+			sets[i] = fmt.Sprintf("target.%s = source.%s", d.QuoteIdent(col), d.QuoteIdent(col))
+		}
+		fmt.Fprintf(&b, " WHEN MATCHED THEN UPDATE SET %s", strings.Join(sets, ", "))
+	}
+	fmt.Fprintf(&b, " WHEN NOT MATCHED THEN INSERT (%s) VALUES (%s);", strings.Join(quoted, ", "), strings.Join(sourceCols, ", "))
+
+	return fmt.Sprintf("MERGE INTO %s AS target USING (VALUES ", table), b.String()
+}
+
+func (sqlServerDialect) RowValueIn() bool { return false }
+
+func (sqlServerDialect) TruncateStatement(table string, _ bool) string {
+	return "TRUNCATE TABLE " + table
+}
+
+func (sqlServerDialect) SavepointStatements(name string) (string, string) {
+	return "SAVE TRANSACTION " + name, "ROLLBACK TRANSACTION " + name
+}
+
+// NullSafeEqual avoids IS NOT DISTINCT FROM, only available from SQL Server 2022
+func (sqlServerDialect) NullSafeEqual(left, right string) string {
+	return fmt.Sprintf("(%s = %s OR (%s IS NULL AND %s IS NULL))", left, right, left, right)
+}
+
+// mysqlDialect targets MySQL and MariaDB (go-sql-driver/mysql)
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string { return "mysql" }
+
+func (mysqlDialect) QuoteIdent(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+func (mysqlDialect) Placeholder(int) string { return "?" }
+
+func (mysqlDialect) PlaceholderExpr(string) string { return `"?"` }
+
+func (mysqlDialect) MaxParams() int { return 65535 }
+
+func (mysqlDialect) MaxRows() int { return 0 }
+
+func (d mysqlDialect) UpsertQuery(table string, columns, keys []string) (string, string) {
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", table, strings.Join(quoteColumns(d, columns), ", "))
+
+	// The key is matched by the table's primary or unique key; with nothing else to
+	// update, a no-op assignment keeps existing rows untouched
+	updates := nonKeyColumns(columns, keys)
+	if len(updates) == 0 {
+		updates = keys[:1]
+	}
+	sets := make([]string, len(updates))
+	for i, col := range updates {
+		sets[i] = fmt.Sprintf("%s = VALUES(%s)", d.QuoteIdent(col), d.QuoteIdent(col))
+	}
+	return prefix, " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+}
+
+func (mysqlDialect) RowValueIn() bool { return true }
+
+// TruncateStatement falls back to DELETE in a transaction, TRUNCATE causing an implicit commit
+func (mysqlDialect) TruncateStatement(table string, transactional bool) string {
+	if transactional {
+		return "DELETE FROM " + table
+	}
+	return "TRUNCATE TABLE " + table
+}
+
+func (mysqlDialect) SavepointStatements(name string) (string, string) {
+	return "SAVEPOINT " + name, "ROLLBACK TO SAVEPOINT " + name
+}
+
+func (mysqlDialect) NullSafeEqual(left, right string) string {
+	return left + " <=> " + right
+}
+
+// sqliteDialect targets SQLite (modernc.org/sqlite)
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string { return "sqlite" }
+
+func (sqliteDialect) QuoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (sqliteDialect) Placeholder(n int) string { return fmt.Sprintf("?%d", n) }
+
+func (sqliteDialect) PlaceholderExpr(indexExpr string) string {
+	return fmt.Sprintf(`fmt.Sprintf("?%%d", %s)`, indexExpr)
+}
+
+// MaxParams is SQLITE_MAX_VARIABLE_NUMBER since SQLite 3.32
+func (sqliteDialect) MaxParams() int { return 32766 }
+
+func (sqliteDialect) MaxRows() int { return 0 }
+
+func (d sqliteDialect) UpsertQuery(table string, columns, keys []string) (string, string) {
+	prefix := fmt.Sprintf("INSERT INTO %s (%s) VALUES ", table, strings.Join(quoteColumns(d, columns), ", "))
+
+	updates := nonKeyColumns(columns, keys)
+	if len(updates) == 0 {
+		return prefix, fmt.Sprintf(" ON CONFLICT (%s) DO NOTHING", strings.Join(quoteColumns(d, keys), ", "))
+	}
+	sets := make([]string, len(updates))
+	for i, col := range updates {
+		sets[i] = fmt.Sprintf("%s = excluded.%s", d.QuoteIdent(col), d.QuoteIdent(col))
+	}
+	return prefix, fmt.Sprintf(" ON CONFLICT (%s) DO UPDATE SET %s",
+		strings.Join(quoteColumns(d, keys), ", "), strings.Join(sets, ", "))
+}
+
+func (sqliteDialect) RowValueIn() bool { return true }
+
+// TruncateStatement uses DELETE, SQLite having no TRUNCATE
+func (sqliteDialect) TruncateStatement(table string, _ bool) string {
+	return "DELETE FROM " + table
+}
+
+func (sqliteDialect) SavepointStatements(name string) (string, string) {
+	return "SAVEPOINT " + name, "ROLLBACK TO SAVEPOINT " + name
+}
+
+func (sqliteDialect) NullSafeEqual(left, right string) string {
+	return left + " IS " + right
+}
+
+// duckDBDialect targets DuckDB (duckdb-go), whose quoting, placeholders, upserts and
+// TRUNCATE follow PostgreSQL
+type duckDBDialect struct {
+	postgresDialect
+}
+
+func (duckDBDialect) Name() string { return "duckdb" }
+
+// SavepointStatements returns nothing, DuckDB having no savepoints
+func (duckDBDialect) SavepointStatements(string) (string, string) { return "", "" }
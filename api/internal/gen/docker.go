@@ -2,7 +2,9 @@ package gen
 
 import (
 	"api"
+	"api/internal/gen/lib"
 	"api/pkg"
+	"context"
 	"embed"
 	"fmt"
 	"io/fs"
@@ -12,7 +14,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 )
 
@@ -24,8 +25,21 @@ var knownDriverVersions = map[string]string{
 	"github.com/lib/pq":                "v1.10.9",
 	"github.com/denisenkom/go-mssqldb": "v0.12.3",
 	"github.com/go-sql-driver/mysql":   "v1.8.1",
+	"modernc.org/sqlite":               "v1.38.2",
+	"github.com/duckdb/duckdb-go/v2":   "v2.10505.0",
 }
 
+// cgoDrivers are the drivers that link a C library and cannot build on Alpine
+var cgoDrivers = map[string]bool{
+	"github.com/duckdb/duckdb-go/v2": true,
+}
+
+// CertsMountDir is where the TLS files of the connections are mounted in the job container
+const CertsMountDir = "/run/dos-certs"
+
+// SecretsMountDir is where the secrets of the job are mounted in the job container
+const SecretsMountDir = lib.DefaultSecretsDir
+
 // fixedDependencies are always included in generated go.mod (required by lib/)
 var fixedDependencies = map[string]string{
 	"github.com/nats-io/nats.go": "v1.48.0",
@@ -38,6 +52,14 @@ RUN go mod tidy && go build -o /job .
 CMD ["/job"]
 `
 
+// cgoDockerfileContent builds jobs using a cgo driver against glibc
+const cgoDockerfileContent = `FROM golang:1.25
+WORKDIR /app
+COPY . .
+RUN go mod tidy && CGO_ENABLED=1 go build -o /job .
+CMD ["/job"]
+`
+
 // prepareWorkspace creates an isolated directory with all files needed to build the job Docker image
 func (j *JobExecution) prepareWorkspace() (string, error) {
 	workDir, err := os.MkdirTemp("", "job-*")
@@ -62,7 +84,7 @@ func (j *JobExecution) prepareWorkspace() (string, error) {
 	}
 
 	// Write Dockerfile
-	if err := os.WriteFile(filepath.Join(workDir, "Dockerfile"), []byte(dockerfileContent), 0644); err != nil {
+	if err := os.WriteFile(filepath.Join(workDir, "Dockerfile"), []byte(j.dockerfile()), 0644); err != nil {
 		return workDir, fmt.Errorf("failed to write Dockerfile: %w", err)
 	}
 
@@ -83,6 +105,10 @@ func extractLib(destDir string) error {
 		if d.IsDir() {
 			return os.MkdirAll(destPath, 0755)
 		}
+		// Tests of the lib package are not part of the generated program
+		if strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
 
 		data, err := libFS.ReadFile(path)
 		if err != nil {
@@ -92,6 +118,80 @@ func extractLib(destDir string) error {
 	})
 }
 
+// dockerfile returns the Dockerfile of the job, building with cgo when one of its drivers needs it
+func (j *JobExecution) dockerfile() string {
+	for _, conn := range j.Context.DBConnections {
+		if cgoDrivers[conn.GetImportPath()] {
+			return cgoDockerfileContent
+		}
+	}
+	return dockerfileContent
+}
+
+// fileDatabaseDirs returns the directories of the file-based databases used by the job,
+// mounted at the same path in the container
+func (j *JobExecution) fileDatabaseDirs() []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, conn := range j.Context.DBConnections {
+		if !conn.IsFileBased() || conn.Database == "" {
+			continue
+		}
+		dir, err := filepath.Abs(filepath.Dir(conn.Database))
+		if err != nil || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// writeCertFiles writes the TLS files of the connections to a new directory, one subdirectory
+// per connection, to be mounted at CertsMountDir. It returns "" when no connection uses TLS files.
+func (j *JobExecution) writeCertFiles() (string, error) {
+	var dir string
+	for _, conn := range j.Context.DBConnections {
+		files := conn.CertFiles()
+		if len(files) == 0 {
+			continue
+		}
+		if dir == "" {
+			var err error
+			if dir, err = os.MkdirTemp("", "job-certs-*"); err != nil {
+				return "", fmt.Errorf("failed to create certs dir: %w", err)
+			}
+		}
+		connDir := filepath.Join(dir, conn.GetConnectionID())
+		if err := os.MkdirAll(connDir, 0755); err != nil {
+			os.RemoveAll(dir)
+			return "", fmt.Errorf("failed to create certs dir: %w", err)
+		}
+		for name, pem := range files {
+			// Drivers refuse private keys readable by others
+			if err := os.WriteFile(filepath.Join(connDir, name), []byte(pem), 0600); err != nil {
+				os.RemoveAll(dir)
+				return "", fmt.Errorf("failed to write %s: %w", name, err)
+			}
+		}
+	}
+	return dir, nil
+}
+
+// writeSecretFiles writes the secrets of the job to dir, one file per secret named after it,
+// creating dir when missing. dir is mounted at SecretsMountDir in the job container.
+func (j *JobExecution) writeSecretFiles(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create secrets dir: %w", err)
+	}
+	for name, value := range j.FileBuilder.Secrets() {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(value), 0600); err != nil {
+			return fmt.Errorf("failed to write secret %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
 // generateGoMod produces a go.mod with the module name "test" (matching the generated import "test/lib")
 // and requires for whichever database drivers the job uses
 func (j *JobExecution) generateGoMod() string {
@@ -129,12 +229,22 @@ func (j *JobExecution) dockerBuild(workDir, imageTag string) error {
 }
 
 // dockerRun executes the job inside a Docker container and captures logs and stats.
-func (j *JobExecution) dockerRun(imageTag, containerName string) error {
+// When ctx is done before the container exits, the container is stopped and interrupted is set.
+func (j *JobExecution) dockerRun(ctx context.Context, imageTag, containerName, certsDir, secretsDir string) (interrupted bool, err error) {
 	j.logger.Info().Msgf("Running job container: %s", containerName)
 	args := []string{"run", "--network", "host", "--name", containerName}
 	if !j.isDebug() {
 		args = append(args, "--rm")
 	}
+	for _, dir := range j.fileDatabaseDirs() {
+		args = append(args, "-v", dir+":"+dir)
+	}
+	if certsDir != "" {
+		args = append(args, "-v", certsDir+":"+CertsMountDir+":ro")
+	}
+	if secretsDir != "" {
+		args = append(args, "-v", secretsDir+":"+SecretsMountDir+":ro")
+	}
 	args = append(args, imageTag)
 
 	// Collect stats in a background goroutine
@@ -146,12 +256,27 @@ func (j *JobExecution) dockerRun(imageTag, containerName string) error {
 		j.collectDockerStats(containerName, stopStats)
 	}()
 
+	// Stop the container on cancellation, docker run then returns with the container's exit code
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-ctx.Done():
+			interrupted = true
+			if err := dockerStopContainer(containerName, j.logger); err != nil {
+				j.logger.Warn().Err(err).Msgf("Failed to stop container %s on cancellation", containerName)
+			}
+		case <-stopStats:
+		}
+	}()
+
 	stdout, stderr, err := pkg.RunCommandLineWithOutput("", "docker", args...)
 	close(stopStats)
 	wg.Wait()
 
 	j.Logs = stdout + stderr
-	return err
+	// A container that exited cleanly completed its run, even when ctx ended meanwhile
+	return interrupted && err != nil, err
 }
 
 // collectDockerStats periodically samples docker stats and keeps peak values.
@@ -188,20 +313,39 @@ func (j *JobExecution) dockerCleanup(containerName, imageTag string) {
 	}
 }
 
-// containerName returns a deterministic container name for this job so it can be stopped by job ID.
+// containerName returns the container name of this run, prefixed with the job ID so the
+// containers of a job can be found by DockerStop. Runs of the same job can overlap.
 func (j *JobExecution) containerName() string {
-	return fmt.Sprintf("job-%d", j.Job.ID)
+	return fmt.Sprintf("%s%s", containerPrefix(j.Job.ID), j.runID)
 }
 
-// newImageTag generates a unique Docker image tag for this job run
+// containerPrefix is the start of the container names of a job
+func containerPrefix(jobID uint) string {
+	return fmt.Sprintf("job-%d-run-", jobID)
+}
+
+// newImageTag returns the Docker image tag of this job run
 func (j *JobExecution) newImageTag() string {
-	return fmt.Sprintf("job-%d-%s", j.Job.ID, uuid.NewString()[:8])
+	return fmt.Sprintf("job-%d-%s", j.Job.ID, j.runID)
 }
 
-// DockerStop gracefully stops a running container for the given job ID.
+// DockerStop gracefully stops the running containers of the given job ID.
 // docker stop sends SIGTERM, waits for the timeout, then SIGKILL.
 func DockerStop(jobID uint, logger zerolog.Logger) error {
-	name := fmt.Sprintf("job-%d", jobID)
+	stdout, _, err := pkg.RunCommandLineWithOutput("", "docker", "ps", "-q", "--filter", "name=^"+containerPrefix(jobID))
+	if err != nil {
+		return fmt.Errorf("failed to list containers of job %d: %w", jobID, err)
+	}
+	for _, id := range strings.Fields(stdout) {
+		if err := dockerStopContainer(id, logger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dockerStopContainer gracefully stops a container by name or ID
+func dockerStopContainer(name string, logger zerolog.Logger) error {
 	logger.Info().Msgf("Stopping container %s", name)
 	if err := pkg.RunCommandLine("", "docker", "stop", "-t", "5", name); err != nil {
 		return fmt.Errorf("failed to stop container %s: %w", name, err)
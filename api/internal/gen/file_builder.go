@@ -3,6 +3,7 @@ package gen
 import (
 	"api/internal/api/models"
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -23,6 +24,9 @@ type FileBuilder struct {
 	natsURL  string
 	tenantID string
 	jobID    uint
+
+	// Resume from the checkpoints of a previous run
+	resume bool
 }
 
 // NewFileBuilder creates a new file builder
@@ -38,9 +42,15 @@ func NewFileBuilder(job *models.Job) *FileBuilder {
 		panic(fmt.Sprintf("failed to create template engine: %v", err))
 	}
 
+	ctx := NewGeneratorContext()
+	ctx.JobID = job.ID
+	for name, value := range job.Parameters {
+		ctx.Parameters[name] = value
+	}
+
 	return &FileBuilder{
 		job:           job,
-		ctx:           NewGeneratorContext(),
+		ctx:           ctx,
 		engine:        engine,
 		nodeIDs:       make(map[int]bool),
 		dbConnections: make([]models.DBConnectionConfig, 0),
@@ -81,6 +91,24 @@ func (b *FileBuilder) SetProgressConfig(natsURL, tenantID string, jobID uint) {
 	b.jobID = jobID
 }
 
+// SetResume makes the generated program resume checkpointed outputs where the previous run stopped
+func (b *FileBuilder) SetResume(resume bool) {
+	b.resume = resume
+}
+
+// SetWatermarks sets the high-water marks incremental db_input nodes read above, keyed by node ID
+func (b *FileBuilder) SetWatermarks(watermarks map[int]string) {
+	for nodeID, value := range watermarks {
+		b.ctx.Watermarks[nodeID] = value
+	}
+}
+
+// Secrets returns the secrets the generated program reads at runtime, keyed by name.
+// Only valid after Build.
+func (b *FileBuilder) Secrets() map[string]string {
+	return b.ctx.Secrets
+}
+
 // Build generates all code for the job
 func (b *FileBuilder) Build() error {
 	// Pass 1: Generate all structs first so NodeStructNames is fully populated
@@ -106,6 +134,16 @@ func (b *FileBuilder) Build() error {
 		}
 	}
 
+	// Find the db_input nodes feeding checkpointed outputs before generating their functions
+	if err := b.collectCheckpoints(); err != nil {
+		return err
+	}
+
+	// Declare the reject structs of nodes routing failing rows to their reject port
+	if err := b.collectRejects(); err != nil {
+		return err
+	}
+
 	// Pass 2: Generate all functions (now all struct names are available)
 	for i := range b.job.Nodes {
 		node := &b.job.Nodes[i]
@@ -145,11 +183,7 @@ func (b *FileBuilder) Build() error {
 
 	// Collect DB connections
 	for _, conn := range b.dbConnections {
-		b.templateData.DBConnections = append(b.templateData.DBConnections, DBConnectionData{
-			ID:         conn.GetConnectionID(),
-			Driver:     conn.GetDriverName(),
-			ConnString: conn.BuildConnectionString(),
-		})
+		b.templateData.DBConnections = append(b.templateData.DBConnections, b.connectionData(conn))
 	}
 
 	// Collect node launches
@@ -184,6 +218,11 @@ func (b *FileBuilder) Build() error {
 	}
 
 	b.templateData.NodeCount = len(b.templateData.NodeFunctions)
+	b.templateData.Resume = b.resume && len(b.templateData.Checkpoints) > 0
+	b.templateData.Watermarks = b.ctx.UsesWatermarks
+	if b.job.MaxRuntime > 0 {
+		b.templateData.MaxRuntime = b.job.MaxRuntime
+	}
 
 	return nil
 }
@@ -202,6 +241,272 @@ func (b *FileBuilder) EmitFile() ([]byte, error) {
 	return result, err
 }
 
+// connectionData describes how the generated program opens, tunes and checks a connection
+func (b *FileBuilder) connectionData(conn models.DBConnectionConfig) DBConnectionData {
+	data := DBConnectionData{
+		ID:     conn.GetConnectionID(),
+		Driver: conn.GetDriverName(),
+		// The connection string holds the password, the program reads it at runtime
+		DSNSecret: b.ctx.AddSecret("DOS_DB_"+conn.GetConnectionID(), conn.BuildConnectionString()),
+		Name:      fmt.Sprintf("%s:%d/%s", conn.Host, conn.Port, conn.Database),
+	}
+	if conn.IsFileBased() {
+		data.Name = conn.Database
+	}
+
+	if p := conn.Pool; p != nil {
+		data.MaxOpenConns = p.MaxOpenConns
+		data.MaxIdleConns = p.MaxIdleConns
+		data.ConnMaxLifetime = p.ConnMaxLifetime
+		data.ConnMaxIdleTime = p.ConnMaxIdleTime
+		data.PingTimeout = p.ConnectTimeout
+		if p.ConnMaxLifetime > 0 || p.ConnMaxIdleTime > 0 {
+			b.ctx.AddImport("time")
+		}
+	}
+
+	if conn.Type == models.DBTypeMySQL && conn.UsesTLSFiles() {
+		data.MySQLTLS = &MySQLTLSData{
+			Name:       conn.MySQLTLSName(),
+			CAFile:     conn.CertPath(models.CACertFile),
+			CertFile:   conn.CertPath(models.ClientCertFile),
+			KeyFile:    conn.CertPath(models.ClientKeyFile),
+			ServerName: conn.TLS.ServerName,
+		}
+		// Named rather than blank import, to call mysql.RegisterTLSConfig
+		b.ctx.Imports[conn.GetImportPath()] = ""
+	}
+
+	return data
+}
+
+// collectCheckpoints registers checkpointed db_output nodes and, for each of them, the upstream
+// db_input nodes whose query must skip the rows already written when resuming. A db_input node
+// can only resume from one checkpoint.
+func (b *FileBuilder) collectCheckpoints() error {
+	for i := range b.job.Nodes {
+		node := &b.job.Nodes[i]
+		if node.Type != models.NodeTypeDBOutput {
+			continue
+		}
+		if len(b.nodeIDs) > 0 && !b.nodeIDs[node.ID] {
+			continue
+		}
+
+		config, err := node.GetDBOutputConfig()
+		if err != nil || !supportsCheckpoint(&config) {
+			continue
+		}
+
+		b.templateData.Checkpoints = append(b.templateData.Checkpoints, CheckpointData{
+			JobID:  b.job.ID,
+			NodeID: node.ID,
+			DBVar:  fmt.Sprintf("db_%s", config.Connection.GetConnectionID()),
+			Driver: config.Connection.GetDriverName(),
+		})
+
+		sources, err := b.resumeSources(node, config.Checkpoint.KeyColumn)
+		if err != nil {
+			return fmt.Errorf("checkpointed db_output node %q: %w", node.Name, err)
+		}
+		for sourceID, keyColumn := range sources {
+			if other, ok := b.ctx.ResumeSources[sourceID]; ok {
+				return fmt.Errorf("db_input node %q feeds the checkpointed db_output nodes %d and %d, it can only resume from one of them",
+					b.nodeByID[sourceID].Name, other.OutputNodeID, node.ID)
+			}
+			b.ctx.ResumeSources[sourceID] = ResumeSource{
+				OutputNodeID: node.ID,
+				KeyColumn:    keyColumn,
+			}
+		}
+	}
+	return nil
+}
+
+// resumeSources walks the data inputs of a checkpointed db_output node upstream until reaching
+// db_input nodes, returning for each of them the name of the checkpoint key column in its rows
+// (empty when resuming by row offset). Maps may rename the key column but must copy it unchanged.
+func (b *FileBuilder) resumeSources(output *models.Node, keyColumn string) (map[int]string, error) {
+	type step struct {
+		node      *models.Node
+		keyColumn string
+	}
+
+	sources := make(map[int]string)
+	visited := make(map[int]bool)
+	pending := []step{{node: output, keyColumn: keyColumn}}
+	for len(pending) > 0 {
+		current := pending[0]
+		pending = pending[1:]
+
+		upstream, err := b.keyUpstream(current.node, current.keyColumn)
+		if err != nil {
+			return nil, err
+		}
+		for sourceID, key := range upstream {
+			source, ok := b.nodeByID[sourceID]
+			if !ok || visited[source.ID] {
+				continue
+			}
+			visited[source.ID] = true
+			if source.Type != models.NodeTypeDBInput {
+				pending = append(pending, step{node: source, keyColumn: key})
+				continue
+			}
+
+			if key != "" {
+				config, err := source.GetDBInputConfig()
+				if err != nil {
+					return nil, err
+				}
+				if !slices.ContainsFunc(config.DataModels, func(dm models.DataModel) bool { return dm.Name == key }) {
+					return nil, fmt.Errorf("checkpoint key column %q does not exist in the rows of db_input node %q", key, source.Name)
+				}
+			}
+			sources[source.ID] = key
+		}
+	}
+	return sources, nil
+}
+
+// keyUpstream returns the nodes node reads its rows from, each with the name the checkpoint key
+// column keyColumn of node has in its rows. Through a map, only the input the key is copied from
+// is followed.
+func (b *FileBuilder) keyUpstream(node *models.Node, keyColumn string) (map[int]string, error) {
+	upstream := make(map[int]string)
+	if node.Type != models.NodeTypeMap || keyColumn == "" {
+		for _, port := range node.InputPort {
+			if port.Type == models.PortTypeInput {
+				upstream[int(port.ConnectedNodeID)] = keyColumn
+			}
+		}
+		return upstream, nil
+	}
+
+	config, err := node.GetMapConfig()
+	if err != nil {
+		return nil, err
+	}
+	if len(config.Outputs) == 0 {
+		return nil, fmt.Errorf("map node %q has no outputs defined", node.Name)
+	}
+	idx := slices.IndexFunc(config.Outputs[0].Columns, func(col models.MapOutputCol) bool { return col.Name == keyColumn })
+	if idx < 0 {
+		return nil, fmt.Errorf("checkpoint key column %q is not an output column of map node %q", keyColumn, node.Name)
+	}
+	col := config.Outputs[0].Columns[idx]
+	if col.FuncType != models.FuncTypeDirect {
+		return nil, fmt.Errorf("checkpoint key column %q is computed by map node %q and does not exist at the source", keyColumn, node.Name)
+	}
+
+	inputName, sourceColumn := parseInputRef(col.InputRef)
+	input := config.GetInputByName(inputName)
+	if input == nil && len(config.Inputs) == 1 {
+		input = &config.Inputs[0]
+	}
+	if input == nil || input.PortID < 0 || input.PortID >= len(node.InputPort) {
+		return nil, fmt.Errorf("map node %q: cannot find the input of checkpoint key column %q", node.Name, keyColumn)
+	}
+	upstream[int(node.InputPort[input.PortID].ConnectedNodeID)] = sourceColumn
+	return upstream, nil
+}
+
+// collectRejects validates reject port connections and generates, for each node in reject mode,
+// a struct embedding the failing row with the error message and the node ID.
+func (b *FileBuilder) collectRejects() error {
+	included := func(node *models.Node) bool {
+		return len(b.nodeIDs) == 0 || b.nodeIDs[node.ID]
+	}
+
+	// Register reject edges first so row types of chained rejects can be resolved
+	for i := range b.job.Nodes {
+		node := &b.job.Nodes[i]
+		if !included(node) {
+			continue
+		}
+		for _, targetID := range node.GetRejectNodeIDs() {
+			if b.ctx.RejectEdges[targetID] == nil {
+				b.ctx.RejectEdges[targetID] = make(map[int]bool)
+			}
+			b.ctx.RejectEdges[targetID][node.ID] = true
+		}
+	}
+
+	for i := range b.job.Nodes {
+		node := &b.job.Nodes[i]
+		if !included(node) {
+			continue
+		}
+
+		mode, err := node.GetErrorPolicyMode()
+		if err != nil {
+			return fmt.Errorf("node %q: %w", node.Name, err)
+		}
+		targets := node.GetRejectNodeIDs()
+		if mode != models.ErrorPolicyReject {
+			if len(targets) > 0 {
+				return fmt.Errorf("node %q: reject port is connected but the error policy is %q", node.Name, mode)
+			}
+			continue
+		}
+
+		switch {
+		case !supportsErrorPolicy(node.Type):
+			return fmt.Errorf("node %q: %s nodes do not support the reject error policy", node.Name, node.Type)
+		case len(targets) == 0:
+			return fmt.Errorf("node %q: error policy is reject but no node is connected to the reject port", node.Name)
+		case len(targets) > 1:
+			return fmt.Errorf("node %q: only one node can be connected to the reject port", node.Name)
+		}
+
+		rowType := b.rejectedRowType(node)
+		if rowType == "" {
+			return fmt.Errorf("node %q: cannot determine the type of rejected rows", node.Name)
+		}
+
+		structName := fmt.Sprintf("Node%dReject", node.ID)
+		b.templateData.Structs = append(b.templateData.Structs, StructData{
+			Name:   structName,
+			NodeID: node.ID,
+			Fields: []FieldData{
+				{Name: rowType},
+				{Name: "RejectNodeId", Type: "int", Tag: `db:"reject_node_id"`},
+				{Name: "RejectError", Type: "string", Tag: `db:"reject_error"`},
+			},
+		})
+		b.ctx.RejectStructNames[node.ID] = structName
+	}
+
+	return nil
+}
+
+// rejectedRowType returns the type of the rows a node can reject: its own rows for
+// db_input nodes, the rows it receives otherwise
+func (b *FileBuilder) rejectedRowType(node *models.Node) string {
+	if node.Type == models.NodeTypeDBInput {
+		return b.ctx.NodeStructNames[node.ID]
+	}
+	for _, port := range node.InputPort {
+		if port.Type != models.PortTypeInput {
+			continue
+		}
+		if name, ok := b.ctx.InputStructName(node.ID, int(port.ConnectedNodeID)); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// supportsErrorPolicy reports whether generated code of a node type honours its error policy
+func supportsErrorPolicy(nodeType models.NodeType) bool {
+	switch nodeType {
+	case models.NodeTypeDBInput, models.NodeTypeDBOutput, models.NodeTypeEmailOutput:
+		return true
+	default:
+		return false
+	}
+}
+
 // channelInfo holds info about a channel between nodes
 type channelInfo struct {
 	portID     uint
@@ -209,6 +514,7 @@ type channelInfo struct {
 	toNodeID   int
 	rowType    string
 	bufferSize int
+	reject     bool // carries the rejected rows of fromNodeID
 }
 
 // collectChannels collects all channels needed for data flow
@@ -233,6 +539,17 @@ func (b *FileBuilder) collectChannels() []channelInfo {
 					bufferSize: 1000, // default buffer size
 				})
 			}
+			if port.Type == models.PortTypeReject && !seen[port.ID] {
+				seen[port.ID] = true
+				channels = append(channels, channelInfo{
+					portID:     port.ID,
+					fromNodeID: node.ID,
+					toNodeID:   int(port.ConnectedNodeID),
+					rowType:    b.ctx.RejectStructNames[node.ID],
+					bufferSize: 1000,
+					reject:     true,
+				})
+			}
 		}
 	}
 
@@ -266,13 +583,23 @@ func (b *FileBuilder) generateNodeLaunchData(node *models.Node, channels []chann
 	hasOutput := false
 	outputChan := ""
 	for _, ch := range channels {
-		if ch.fromNodeID == node.ID {
+		if ch.fromNodeID == node.ID && !ch.reject {
 			outputChan = fmt.Sprintf("ch_%d", ch.portID)
 			hasOutput = true
 			break
 		}
 	}
 
+	// The reject channel always comes right before the progress func
+	rejectChan := ""
+	for _, ch := range channels {
+		if ch.fromNodeID == node.ID && ch.reject {
+			rejectChan = fmt.Sprintf("ch_%d", ch.portID)
+			args = append(args, rejectChan)
+			break
+		}
+	}
+
 	return &NodeLaunchData{
 		NodeID:           node.ID,
 		NodeName:         node.Name,
@@ -280,6 +607,7 @@ func (b *FileBuilder) generateNodeLaunchData(node *models.Node, channels []chann
 		Args:             args,
 		HasOutputChannel: hasOutput,
 		OutputChannel:    outputChan,
+		RejectChannel:    rejectChan,
 	}
 }
 
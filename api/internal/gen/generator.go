@@ -3,6 +3,7 @@ package gen
 import (
 	"api/internal/api/models"
 	"fmt"
+	"strings"
 )
 
 // NodeGenerator generates code data for a specific node type
@@ -39,6 +40,36 @@ type GeneratorContext struct {
 
 	// Imports collects all imports needed
 	Imports map[string]string // path -> alias (empty string for no alias)
+
+	// JobID is the ID of the job being generated (used to key checkpoints)
+	JobID uint
+
+	// ResumeSources maps a db_input node ID to the checkpointed db_output it feeds
+	ResumeSources map[int]ResumeSource
+
+	// RejectStructNames maps a node ID to the struct sent on its reject port
+	RejectStructNames map[int]string
+
+	// RejectEdges maps a node ID to the source nodes connected to it through their reject port
+	RejectEdges map[int]map[int]bool
+
+	// Parameters holds the job parameters bound to named query parameters
+	Parameters map[string]string
+
+	// Watermarks maps an incremental db_input node ID to the high-water mark of the previous run
+	Watermarks map[int]string
+
+	// UsesWatermarks is set once a node reports a high-water mark
+	UsesWatermarks bool
+
+	// Secrets maps the name of a secret to its value, provided to the program at runtime
+	Secrets map[string]string
+}
+
+// ResumeSource tells a db_input node which checkpoint to resume from
+type ResumeSource struct {
+	OutputNodeID int
+	KeyColumn    string // empty = resume by row offset
 }
 
 // NewGeneratorContext creates a new generator context
@@ -47,6 +78,13 @@ func NewGeneratorContext() *GeneratorContext {
 		NodeStructNames: make(map[int]string),
 		NodeFuncNames:   make(map[int]string),
 		Imports:         make(map[string]string),
+		ResumeSources:   make(map[int]ResumeSource),
+		Parameters:      make(map[string]string),
+		Watermarks:      make(map[int]string),
+		Secrets:         make(map[string]string),
+
+		RejectStructNames: make(map[int]string),
+		RejectEdges:       make(map[int]map[int]bool),
 	}
 }
 
@@ -57,11 +95,32 @@ func (ctx *GeneratorContext) AddImport(path string) {
 	}
 }
 
-// AddImportAlias adds an aliased import
+// AddImportAlias adds an aliased import. A blank import never replaces an existing one,
+// so a driver package can be both registered and used by name.
 func (ctx *GeneratorContext) AddImportAlias(alias, path string) {
+	if _, exists := ctx.Imports[path]; exists && alias == "_" {
+		return
+	}
 	ctx.Imports[path] = alias
 }
 
+// AddSecret registers a value the generated program reads with lib.Secret instead of embedding
+// it, and returns its name: name in upper case with non-alphanumeric characters replaced by '_'.
+func (ctx *GeneratorContext) AddSecret(name, value string) string {
+	name = strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		}
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+	ctx.Secrets[name] = value
+	ctx.AddImport("test/lib")
+	return name
+}
+
 // StructName returns or generates a struct name for a node
 func (ctx *GeneratorContext) StructName(node *models.Node) string {
 	if name, exists := ctx.NodeStructNames[node.ID]; exists {
@@ -82,6 +141,38 @@ func (ctx *GeneratorContext) FuncName(node *models.Node) string {
 	return name
 }
 
+// InputStructName returns the row type a node receives from one of its data sources:
+// the source's reject struct when connected to its reject port, its row struct otherwise
+func (ctx *GeneratorContext) InputStructName(nodeID, sourceNodeID int) (string, bool) {
+	if ctx.RejectEdges[nodeID][sourceNodeID] {
+		name, ok := ctx.RejectStructNames[sourceNodeID]
+		return name, ok
+	}
+	name, ok := ctx.NodeStructNames[sourceNodeID]
+	return name, ok
+}
+
+// errorPolicyTemplateData returns the error policy of a node failing on rows of rowType,
+// nil when the node fails on the first error
+func errorPolicyTemplateData(node *models.Node, ctx *GeneratorContext, rowType string) (*ErrorPolicyTemplateData, error) {
+	mode, err := node.GetErrorPolicyMode()
+	if err != nil {
+		return nil, fmt.Errorf("node %q: %w", node.Name, err)
+	}
+	if mode == models.ErrorPolicyFail {
+		return nil, nil
+	}
+
+	data := &ErrorPolicyTemplateData{
+		Mode:    string(mode),
+		RowType: rowType,
+	}
+	if mode == models.ErrorPolicyReject {
+		data.RejectType = ctx.RejectStructNames[node.ID]
+	}
+	return data, nil
+}
+
 // uniqueFieldNames returns deduplicated Go field names for a list of DataModels.
 // If two columns have the same name (e.g. "id" from two joined tables),
 // the duplicates get a numeric suffix: Id, Id2, Id3, etc.
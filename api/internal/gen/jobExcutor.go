@@ -3,15 +3,29 @@ package gen
 import (
 	"api"
 	"api/internal/api/models"
-	"api/pkg"
+	"api/internal/gen/lib"
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"maps"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/rs/zerolog"
 )
 
+var (
+	// ErrTimedOut is returned when a run exceeds the job's max runtime
+	ErrTimedOut = errors.New("job run timed out")
+	// ErrCancelled is returned when a run is stopped before completion
+	ErrCancelled = errors.New("job run cancelled")
+)
+
 type Step struct {
 	nodes []models.Node
 }
@@ -30,7 +44,15 @@ type JobExecution struct {
 	FileBuilder *FileBuilder
 	Logs        string
 	Stats       DockerStats
-	logger      zerolog.Logger
+	// Resume restarts checkpointed db_output nodes after their last committed batch
+	Resume bool
+	// Watermarks holds the high-water marks of incremental db_input nodes, keyed by node ID
+	Watermarks map[int]string
+	// FindDatabase loads the saved databases db nodes reference by MetadataDatabaseID
+	FindDatabase func(id uint) (*models.MetadataDatabase, error)
+	logger       zerolog.Logger
+	// runID tells apart the containers of overlapping runs of the job
+	runID string
 }
 
 // NewJobExecution creates a new pipeline from a job
@@ -40,6 +62,7 @@ func NewJobExecution(job *models.Job) *JobExecution {
 		Context:     NewExecutionContext(),
 		FileBuilder: NewFileBuilder(job),
 		logger:      api.Logger,
+		runID:       uuid.NewString()[:8],
 	}
 }
 
@@ -51,14 +74,48 @@ func NewPipelineExecutor(job *models.Job) *JobExecution {
 // Run builds the pipeline and either outputs the generated files locally (dev)
 // or executes them inside a Docker container (prod).
 func (j *JobExecution) Run() error {
+	return j.RunContext(context.Background())
+}
+
+// RunContext is like Run but stops the run when ctx is cancelled or when the job's
+// max runtime is exceeded, returning ErrCancelled or ErrTimedOut. A run that completed
+// before ctx ended keeps its own outcome.
+func (j *JobExecution) RunContext(ctx context.Context) error {
+	if j.Job.MaxRuntime > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(j.Job.MaxRuntime)*time.Second)
+		defer cancel()
+	}
+
 	if _, err := j.build(); err != nil {
 		return err
 	}
+	if ctx.Err() != nil {
+		return j.contextError(ctx, nil)
+	}
 
-	//if j.isDebug() {
-	return j.outputToLocal()
-	//}
-	//return j.runInDocker()
+	if j.isDebug() {
+		// Nothing is executed in dev mode, so there is nothing to interrupt
+		return j.outputToLocal()
+	}
+	interrupted, err := j.runInDocker(ctx)
+	if interrupted {
+		return j.contextError(ctx, err)
+	}
+	return err
+}
+
+// contextError wraps the error of an interrupted run with ErrTimedOut or ErrCancelled depending
+// on why ctx ended
+func (j *JobExecution) contextError(ctx context.Context, err error) error {
+	reason := ErrCancelled
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		reason = fmt.Errorf("%w after %ds", ErrTimedOut, j.Job.MaxRuntime)
+	}
+	if err == nil {
+		return reason
+	}
+	return fmt.Errorf("%w: %v", reason, err)
 }
 
 func (j *JobExecution) LogDebug() (string, []Step, error) {
@@ -70,7 +127,8 @@ func (j *JobExecution) LogDebug() (string, []Step, error) {
 	return string(source), j.Steps, err
 }
 
-// outputToLocal writes the generated source, runtime lib and go.mod to ../../bin for inspection.
+// outputToLocal writes the generated source, runtime lib and go.mod to ../bin for inspection.
+// Secrets are not written, the decrypted credentials would stay on disk in plain text.
 func (j *JobExecution) outputToLocal() error {
 	binDir := "../bin"
 	if err := os.MkdirAll(binDir, 0755); err != nil {
@@ -87,38 +145,67 @@ func (j *JobExecution) outputToLocal() error {
 	if err := os.WriteFile(filepath.Join(binDir, "go.mod"), []byte(goMod), 0644); err != nil {
 		return fmt.Errorf("failed to write go.mod: %w", err)
 	}
+	// Drop the secrets written there by earlier versions
+	if err := os.RemoveAll(filepath.Join(binDir, "secrets")); err != nil {
+		return fmt.Errorf("failed to remove secrets dir: %w", err)
+	}
 
 	abs, _ := filepath.Abs(binDir)
-	j.logger.Info().Str("path", abs).Msg("Generated files written (dev mode)")
+	names := slices.Sorted(maps.Keys(j.FileBuilder.Secrets()))
+	j.logger.Info().Str("path", abs).Strs("secrets", names).
+		Msgf("Generated files written (dev mode), run with %s pointing to a directory holding the secrets", lib.SecretsDirEnv)
 	return nil
 }
 
-// runInDocker builds a Docker image from the generated code and runs it.
-func (j *JobExecution) runInDocker() error {
+// runInDocker builds a Docker image from the generated code and runs it. The container is
+// stopped as soon as ctx is done, interrupted then reports that the run did not complete.
+func (j *JobExecution) runInDocker(ctx context.Context) (interrupted bool, err error) {
 	workDir, err := j.prepareWorkspace()
 	if err != nil {
 		if workDir != "" {
 			os.RemoveAll(workDir)
 		}
-		return err
+		return false, err
 	}
 	defer os.RemoveAll(workDir)
 
+	// TLS files stay out of the workspace, which is copied into the image
+	certsDir, err := j.writeCertFiles()
+	if err != nil {
+		return false, err
+	}
+	if certsDir != "" {
+		defer os.RemoveAll(certsDir)
+	}
+
+	// Secrets are mounted rather than passed with -e, which docker inspect would show
+	var secretsDir string
+	if len(j.FileBuilder.Secrets()) > 0 {
+		if secretsDir, err = os.MkdirTemp("", "job-secrets-*"); err != nil {
+			return false, fmt.Errorf("failed to create secrets dir: %w", err)
+		}
+		defer os.RemoveAll(secretsDir)
+		if err := j.writeSecretFiles(secretsDir); err != nil {
+			return false, err
+		}
+	}
+
 	imageTag := j.newImageTag()
 	containerName := j.containerName()
 
-	// Remove any stale container from a previous run of this job
-	_ = pkg.RunCommandLine("", "docker", "rm", "-f", containerName)
-
 	defer j.dockerCleanup(containerName, imageTag)
 
 	if err := j.dockerBuild(workDir, imageTag); err != nil {
-		return fmt.Errorf("docker build failed: %w", err)
+		return false, fmt.Errorf("docker build failed: %w", err)
 	}
-	if err := j.dockerRun(imageTag, containerName); err != nil {
-		return fmt.Errorf("job execution failed: %w", err)
+	if ctx.Err() != nil {
+		return true, nil
 	}
-	return nil
+	interrupted, err = j.dockerRun(ctx, imageTag, containerName, certsDir, secretsDir)
+	if err != nil {
+		return interrupted, fmt.Errorf("job execution failed: %w", err)
+	}
+	return interrupted, nil
 }
 
 // withDbConnection adds a database connection to the execution context if not already present
@@ -129,6 +216,10 @@ func (j *JobExecution) withDbConnection(conn models.DBConnectionConfig) *JobExec
 			return j
 		}
 	}
+	// TLS files are written for the run and mounted in the container
+	if len(conn.CertFiles()) > 0 {
+		conn.CertDir = path.Join(CertsMountDir, conn.GetConnectionID())
+	}
 	j.Context.DBConnections = append(j.Context.DBConnections, conn)
 	return j
 }
@@ -257,26 +348,74 @@ func (j *JobExecution) withStepsSetup() (*JobExecution, error) {
 	return j, nil
 }
 
-// withGlobalVariables Fill global variables in the execution context like db connections or file path for certificates
-func (j *JobExecution) withGlobalVariables(node models.Node) (*JobExecution, error) {
+// withGlobalVariables Fill global variables in the execution context like db connections or file path for certificates.
+// Connections referencing a saved database are resolved first, so every job picks up its current settings.
+func (j *JobExecution) withGlobalVariables(node *models.Node) (*JobExecution, error) {
 	switch node.Type {
 	case models.NodeTypeDBInput:
 		dbInputConfig, err := node.GetDBInputConfig()
 		if err != nil {
 			return nil, err
 		}
+		if dbInputConfig.MetadataDatabaseID != nil {
+			if dbInputConfig.Connection, err = j.resolveDatabase(*dbInputConfig.MetadataDatabaseID); err != nil {
+				return nil, err
+			}
+			if err := j.setNodeData(node, dbInputConfig); err != nil {
+				return nil, err
+			}
+		}
+		if err := dbInputConfig.Connection.ValidateTLS(); err != nil {
+			return nil, err
+		}
 		j.withDbConnection(dbInputConfig.Connection)
 	case models.NodeTypeDBOutput:
 		dbOutputConfig, err := node.GetDBOutputConfig()
 		if err != nil {
 			return nil, err
 		}
+		if dbOutputConfig.MetadataDatabaseID != nil {
+			if dbOutputConfig.Connection, err = j.resolveDatabase(*dbOutputConfig.MetadataDatabaseID); err != nil {
+				return nil, err
+			}
+			if err := j.setNodeData(node, dbOutputConfig); err != nil {
+				return nil, err
+			}
+		}
+		if err := dbOutputConfig.Connection.ValidateTLS(); err != nil {
+			return nil, err
+		}
 		j.withDbConnection(dbOutputConfig.Connection)
 	}
 
 	return j, nil
 }
 
+// resolveDatabase returns the connection of the saved database id
+func (j *JobExecution) resolveDatabase(id uint) (models.DBConnectionConfig, error) {
+	if j.FindDatabase == nil {
+		return models.DBConnectionConfig{}, fmt.Errorf("database %d is referenced but saved databases cannot be loaded", id)
+	}
+	meta, err := j.FindDatabase(id)
+	if err != nil {
+		return models.DBConnectionConfig{}, fmt.Errorf("database %d: %w", id, err)
+	}
+	return meta.ConnectionConfig(), nil
+}
+
+// setNodeData stores data in node and in the job node it was copied from, which the generators read
+func (j *JobExecution) setNodeData(node *models.Node, data any) error {
+	if err := node.SetData(data); err != nil {
+		return err
+	}
+	for i := range j.Job.Nodes {
+		if j.Job.Nodes[i].ID == node.ID {
+			j.Job.Nodes[i].Data = node.Data
+		}
+	}
+	return nil
+}
+
 // build builds the job file for compilation
 func (j *JobExecution) build() (*JobExecution, error) {
 	// Setup execution steps
@@ -293,8 +432,9 @@ func (j *JobExecution) build() (*JobExecution, error) {
 
 	// Collect global variables and node IDs
 	nodeIDs := make([]int, 0)
-	for _, step := range j.Steps {
-		for _, node := range step.nodes {
+	for si := range j.Steps {
+		for ni := range j.Steps[si].nodes {
+			node := &j.Steps[si].nodes[ni]
 			nodeIDs = append(nodeIDs, node.ID)
 			if _, err := j.withGlobalVariables(node); err != nil {
 				return nil, fmt.Errorf("failed to collect globals for node %d: %w", node.ID, err)
@@ -308,6 +448,8 @@ func (j *JobExecution) build() (*JobExecution, error) {
 	// Pass DB connections and steps to FileBuilder
 	j.FileBuilder.SetDBConnections(j.Context.DBConnections)
 	j.FileBuilder.SetSteps(j.Steps)
+	j.FileBuilder.SetResume(j.Resume)
+	j.FileBuilder.SetWatermarks(j.Watermarks)
 
 	// Generate code using FileBuilder
 	if err := j.FileBuilder.Build(); err != nil {
@@ -0,0 +1,57 @@
+package lib
+
+import (
+	"bufio"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// tsvEscaper escapes the characters that are special in the default LOAD DATA format
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`, "\x00", `\0`)
+
+// WriteTSVRow writes a row in the default MySQL LOAD DATA format: tab-separated fields,
+// newline-terminated lines, backslash escapes and \N for NULL
+func WriteTSVRow(w *bufio.Writer, values ...any) error {
+	for i, v := range values {
+		if i > 0 {
+			if err := w.WriteByte('\t'); err != nil {
+				return err
+			}
+		}
+		if _, err := w.WriteString(tsvField(v)); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte('\n')
+}
+
+// tsvField formats a single value
+func tsvField(v any) string {
+	if valuer, ok := v.(driver.Valuer); ok {
+		val, err := valuer.Value()
+		if err != nil {
+			return `\N`
+		}
+		v = val
+	}
+
+	switch val := v.(type) {
+	case nil:
+		return `\N`
+	case string:
+		return tsvEscaper.Replace(val)
+	case []byte:
+		return tsvEscaper.Replace(string(val))
+	case time.Time:
+		return val.Format("2006-01-02 15:04:05.999999")
+	case bool:
+		if val {
+			return "1"
+		}
+		return "0"
+	default:
+		return tsvEscaper.Replace(fmt.Sprint(val))
+	}
+}
@@ -0,0 +1,28 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestWriteTSVRow(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+
+	ts := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	err := WriteTSVRow(w, 42, "a\tb\nc\\d", sql.NullString{}, sql.NullInt64{Int64: 7, Valid: true}, true, ts, []byte("raw"))
+	if err != nil {
+		t.Fatalf("WriteTSVRow failed: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	want := "42\ta\\tb\\nc\\\\d\t\\N\t7\t1\t2024-03-01 12:30:00\traw\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,150 @@
+package lib
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CheckpointTable is the table, created in the target database, that stores output checkpoints
+const CheckpointTable = "dos_checkpoint"
+
+// Checkpoint records how far a db_output node got: the last committed batch,
+// the number of rows written and the key of the last written row.
+type Checkpoint struct {
+	JobID   uint
+	NodeID  int
+	Batch   int64
+	Offset  int64
+	LastKey string
+}
+
+// Querier is implemented by both *sql.DB and *sql.Tx
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Rebind rewrites "?" placeholders into the driver's placeholder style
+func Rebind(driverName, query string) string {
+	var prefix string
+	switch driverName {
+	case "postgres":
+		prefix = "$"
+	case "sqlserver":
+		prefix = "@p"
+	default:
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			fmt.Fprintf(&b, "%s%d", prefix, n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// EnsureCheckpointTable creates the checkpoint table if it does not exist yet
+func EnsureCheckpointTable(ctx context.Context, db Querier, driverName string) error {
+	columns := `job_id BIGINT NOT NULL,
+		node_id INT NOT NULL,
+		batch_no BIGINT NOT NULL,
+		row_offset BIGINT NOT NULL,
+		last_key VARCHAR(255),
+		updated_at %s NOT NULL,
+		PRIMARY KEY (job_id, node_id)`
+
+	var query string
+	switch driverName {
+	case "sqlserver":
+		query = fmt.Sprintf("IF OBJECT_ID('%s', 'U') IS NULL CREATE TABLE %s ("+columns+")",
+			CheckpointTable, CheckpointTable, "DATETIME2")
+	default:
+		query = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s ("+columns+")", CheckpointTable, "TIMESTAMP")
+	}
+
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create checkpoint table: %w", err)
+	}
+	return nil
+}
+
+// LoadCheckpoint returns the checkpoint of a node, or nil when there is none
+func LoadCheckpoint(ctx context.Context, db Querier, driverName string, jobID uint, nodeID int) (*Checkpoint, error) {
+	query := Rebind(driverName, fmt.Sprintf(
+		"SELECT batch_no, row_offset, last_key FROM %s WHERE job_id = ? AND node_id = ?", CheckpointTable))
+
+	cp := Checkpoint{JobID: jobID, NodeID: nodeID}
+	var lastKey sql.NullString
+	err := db.QueryRowContext(ctx, query, jobID, nodeID).Scan(&cp.Batch, &cp.Offset, &lastKey)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load checkpoint for node %d: %w", nodeID, err)
+	}
+	cp.LastKey = lastKey.String
+	return &cp, nil
+}
+
+// SaveCheckpoint stores a checkpoint. Call it inside the transaction that wrote the batch
+// so the checkpoint is committed together with the rows.
+func SaveCheckpoint(ctx context.Context, tx Querier, driverName string, cp Checkpoint) error {
+	if err := ClearCheckpoint(ctx, tx, driverName, cp.JobID, cp.NodeID); err != nil {
+		return err
+	}
+
+	query := Rebind(driverName, fmt.Sprintf(
+		"INSERT INTO %s (job_id, node_id, batch_no, row_offset, last_key, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+		CheckpointTable))
+	if _, err := tx.ExecContext(ctx, query, cp.JobID, cp.NodeID, cp.Batch, cp.Offset, cp.LastKey, time.Now()); err != nil {
+		return fmt.Errorf("failed to save checkpoint for node %d: %w", cp.NodeID, err)
+	}
+	return nil
+}
+
+// ClearCheckpoint removes the checkpoint of a node, typically once it completed successfully
+func ClearCheckpoint(ctx context.Context, db Querier, driverName string, jobID uint, nodeID int) error {
+	query := Rebind(driverName, fmt.Sprintf("DELETE FROM %s WHERE job_id = ? AND node_id = ?", CheckpointTable))
+	if _, err := db.ExecContext(ctx, query, jobID, nodeID); err != nil {
+		return fmt.Errorf("failed to clear checkpoint for node %d: %w", nodeID, err)
+	}
+	return nil
+}
+
+// CheckpointKey converts a row field (plain value or sql.Null* type) to its checkpoint representation
+func CheckpointKey(v any) string {
+	if valuer, ok := v.(driver.Valuer); ok {
+		val, err := valuer.Value()
+		if err != nil || val == nil {
+			return ""
+		}
+		v = val
+	}
+	switch t := v.(type) {
+	case time.Time:
+		return t.Format(time.RFC3339Nano)
+	case []byte:
+		return string(t)
+	default:
+		return fmt.Sprintf("%v", t)
+	}
+}
+
+// QuoteLiteral quotes a checkpoint key as a SQL string literal for the given driver
+func QuoteLiteral(driverName, s string) string {
+	if driverName == "mysql" {
+		s = strings.ReplaceAll(s, `\`, `\\`)
+	}
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
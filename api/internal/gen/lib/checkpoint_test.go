@@ -0,0 +1,42 @@
+package lib
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCheckpoint_SQLite(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+
+	if err := EnsureCheckpointTable(ctx, db, "sqlite"); err != nil {
+		t.Fatalf("EnsureCheckpointTable failed: %v", err)
+	}
+
+	committer, err := NewCommitter(ctx, db, -1)
+	if err != nil {
+		t.Fatalf("NewCommitter failed: %v", err)
+	}
+	cp := Checkpoint{JobID: 1, NodeID: 2, Batch: 3, Offset: 300, LastKey: "k300"}
+	if err := SaveCheckpoint(ctx, committer.Exec(), "sqlite", cp); err != nil {
+		t.Fatalf("SaveCheckpoint failed: %v", err)
+	}
+	if err := committer.Commit(); err != nil {
+		t.Fatalf("Commit failed: %v", err)
+	}
+
+	got, err := LoadCheckpoint(ctx, db, "sqlite", 1, 2)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint failed: %v", err)
+	}
+	if got == nil || *got != cp {
+		t.Fatalf("got %+v, want %+v", got, cp)
+	}
+
+	if err := ClearCheckpoint(ctx, db, "sqlite", 1, 2); err != nil {
+		t.Fatalf("ClearCheckpoint failed: %v", err)
+	}
+	if got, err := LoadCheckpoint(ctx, db, "sqlite", 1, 2); err != nil || got != nil {
+		t.Fatalf("got %+v, %v after clear, want nil", got, err)
+	}
+}
@@ -0,0 +1,81 @@
+package lib
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Committer hands out the executor batches are written with and commits them according to a
+// strategy: every < 0 writes all batches in a single transaction, every == 0 autocommits each
+// statement and every > 0 commits every that many batches.
+type Committer struct {
+	db      *sql.DB
+	tx      *sql.Tx
+	every   int
+	pending int
+}
+
+// NewCommitter starts the first transaction of the strategy, if any
+func NewCommitter(ctx context.Context, db *sql.DB, every int) (*Committer, error) {
+	c := &Committer{db: db, every: every}
+	if every != 0 {
+		if err := c.begin(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Exec returns the current transaction, or the database in autocommit mode
+func (c *Committer) Exec() Querier {
+	if c.tx != nil {
+		return c.tx
+	}
+	return c.db
+}
+
+// BatchDone records a written batch and commits when the strategy says so
+func (c *Committer) BatchDone(ctx context.Context) error {
+	if c.every <= 0 {
+		return nil
+	}
+	c.pending++
+	if c.pending < c.every {
+		return nil
+	}
+	if err := c.Commit(); err != nil {
+		return err
+	}
+	return c.begin(ctx)
+}
+
+// Commit commits the batches written since the last commit
+func (c *Committer) Commit() error {
+	if c.tx == nil {
+		return nil
+	}
+	tx := c.tx
+	c.tx, c.pending = nil, 0
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit failed: %w", err)
+	}
+	return nil
+}
+
+// Rollback discards the batches written since the last commit
+func (c *Committer) Rollback() {
+	if c.tx != nil {
+		_ = c.tx.Rollback()
+		c.tx = nil
+	}
+}
+
+func (c *Committer) begin(ctx context.Context) error {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx failed: %w", err)
+	}
+	c.tx = tx
+	return nil
+}
@@ -0,0 +1,61 @@
+package lib
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// DefaultPingTimeout bounds the startup ping of connections without a connect timeout
+const DefaultPingTimeout = 30
+
+// Ping checks a connection at startup so an unreachable database fails the run before any node
+// starts, naming the database rather than the node that first used it
+func Ping(ctx context.Context, db *sql.DB, name string, timeoutSeconds int) error {
+	if timeoutSeconds <= 0 {
+		timeoutSeconds = DefaultPingTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(timeoutSeconds)*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("cannot reach database %s: no answer after %ds: %w", name, timeoutSeconds, err)
+		}
+		return fmt.Errorf("cannot reach database %s: %w", name, err)
+	}
+	return nil
+}
+
+// LoadTLSConfig builds the TLS configuration of a connection from PEM files. caFile verifies the
+// server certificate (system roots when empty), certFile and keyFile authenticate the client.
+func LoadTLSConfig(caFile, certFile, keyFile, serverName string) (*tls.Config, error) {
+	cfg := &tls.Config{ServerName: serverName, MinVersion: tls.VersionTLS12}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in CA bundle %s", caFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,87 @@
+package lib
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeSelfSigned writes a self-signed certificate and its key, returning their paths
+func writeSelfSigned(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("key generation failed: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "db.internal"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+		KeyUsage:     x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("certificate creation failed: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("key marshalling failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	return certFile, keyFile
+}
+
+func TestLoadTLSConfig(t *testing.T) {
+	certFile, keyFile := writeSelfSigned(t)
+
+	cfg, err := LoadTLSConfig(certFile, certFile, keyFile, "db.internal")
+	if err != nil {
+		t.Fatalf("LoadTLSConfig failed: %v", err)
+	}
+	if cfg.RootCAs == nil || len(cfg.Certificates) != 1 || cfg.ServerName != "db.internal" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	if _, err := LoadTLSConfig(keyFile, "", "", ""); err == nil || !strings.Contains(err.Error(), "no certificate found") {
+		t.Errorf("expected an invalid CA bundle error, got %v", err)
+	}
+}
+
+func TestPing(t *testing.T) {
+	ctx := context.Background()
+	if err := Ping(ctx, openSQLite(t), "test.db", 0); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+
+	// A directory cannot be opened as a database file
+	db, err := sql.Open("sqlite", "file:"+t.TempDir())
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	defer db.Close()
+	if err := Ping(ctx, db, "broken.db", 1); err == nil || !strings.Contains(err.Error(), "cannot reach database broken.db") {
+		t.Errorf("expected a named ping error, got %v", err)
+	}
+}
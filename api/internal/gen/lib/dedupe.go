@@ -0,0 +1,20 @@
+package lib
+
+// LastByKey keeps one row per key, the last one read, at the position of the first row of the
+// key. It reuses the backing array of rows and returns the number of rows dropped.
+func LastByKey[T any, K comparable](rows []T, key func(T) K) ([]T, int) {
+	index := make(map[K]int, len(rows))
+	kept := rows[:0]
+	dropped := 0
+	for _, row := range rows {
+		k := key(row)
+		if i, ok := index[k]; ok {
+			kept[i] = row
+			dropped++
+			continue
+		}
+		index[k] = len(kept)
+		kept = append(kept, row)
+	}
+	return kept, dropped
+}
@@ -0,0 +1,35 @@
+package lib
+
+import (
+	"database/sql"
+	"slices"
+	"testing"
+)
+
+func TestLastByKey_RepeatedKey(t *testing.T) {
+	type row struct {
+		ID     int
+		Tenant sql.NullInt64
+		Name   string
+	}
+	rows := []*row{
+		{ID: 1, Tenant: sql.NullInt64{Int64: 7, Valid: true}, Name: "first"},
+		{ID: 2, Name: "other"},
+		{ID: 1, Tenant: sql.NullInt64{Int64: 7, Valid: true}, Name: "second"},
+		{ID: 1, Name: "no tenant"},
+		{ID: 1, Tenant: sql.NullInt64{Int64: 7, Valid: true}, Name: "last"},
+	}
+
+	kept, dropped := LastByKey(rows, func(r *row) [2]any { return [2]any{r.ID, r.Tenant} })
+
+	var names []string
+	for _, r := range kept {
+		names = append(names, r.Name)
+	}
+	if want := []string{"last", "other", "no tenant"}; !slices.Equal(names, want) {
+		t.Errorf("kept %v, want %v", names, want)
+	}
+	if dropped != 2 {
+		t.Errorf("dropped %d rows, want 2", dropped)
+	}
+}
@@ -0,0 +1,29 @@
+package lib
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// NumericText returns a scanner reading an exact numeric value into dest as its decimal text.
+// duckdb-go returns DECIMAL and HUGEINT values as a Decimal struct or a *big.Int, which
+// database/sql cannot convert to a string on its own.
+func NumericText(dest *sql.NullString) sql.Scanner {
+	return numericText{dest: dest}
+}
+
+type numericText struct {
+	dest *sql.NullString
+}
+
+func (n numericText) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		*n.dest = sql.NullString{}
+	case fmt.Stringer:
+		*n.dest = sql.NullString{String: v.String(), Valid: true}
+	default:
+		return n.dest.Scan(value)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package lib
+
+import (
+	"database/sql"
+	"math/big"
+	"testing"
+)
+
+// decimal mimics the duckdb-go Decimal value, a struct printing itself exactly
+type decimal struct {
+	value *big.Int
+	scale int
+}
+
+func (d decimal) String() string {
+	s := d.value.String()
+	return s[:len(s)-d.scale] + "." + s[len(s)-d.scale:]
+}
+
+func TestNumericText(t *testing.T) {
+	huge, _ := new(big.Int).SetString("170141183460469231731687303715884105727", 10)
+
+	tests := []struct {
+		name  string
+		value any
+		want  sql.NullString
+	}{
+		{"null", nil, sql.NullString{}},
+		{"decimal", decimal{value: big.NewInt(123456789012345678), scale: 2}, sql.NullString{String: "1234567890123456.78", Valid: true}},
+		{"hugeint", huge, sql.NullString{String: "170141183460469231731687303715884105727", Valid: true}},
+		{"bytes", []byte("12.50"), sql.NullString{String: "12.50", Valid: true}},
+		{"int", int64(42), sql.NullString{String: "42", Valid: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sql.NullString{String: "stale", Valid: true}
+			if err := NumericText(&got).Scan(tt.value); err != nil {
+				t.Fatalf("Scan failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package lib
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// orderWindow is the number of rows per worker that can be in flight while waiting
+// for an earlier row in ordered mode
+const orderWindow = 64
+
+// ParallelMap applies fn to the rows of in on the given number of workers and sends the results
+// to out. When ordered is true the results are emitted in input order.
+func ParallelMap[In, Out any](ctx context.Context, in <-chan *In, out chan<- *Out, workers int, ordered bool, fn func(*In) *Out) error {
+	if workers < 1 {
+		workers = 1
+	}
+	if ordered {
+		return orderedMap(ctx, in, out, workers, fn)
+	}
+
+	return runWorkers(workers, func(int) error {
+		for row := range in {
+			select {
+			case out <- fn(row):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+}
+
+// PartitionedMap applies fn to the rows of in on the given number of workers. Rows with the same
+// key are always handled by the same worker, so their relative order is preserved.
+func PartitionedMap[In, Out any](ctx context.Context, in <-chan *In, out chan<- *Out, workers int, key func(*In) uint64, fn func(*In) *Out) error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	partitions := make([]chan *In, workers)
+	for i := range partitions {
+		partitions[i] = make(chan *In, orderWindow)
+	}
+
+	// Route each row to the partition of its key
+	go func() {
+		defer func() {
+			for _, p := range partitions {
+				close(p)
+			}
+		}()
+		for row := range in {
+			select {
+			case partitions[key(row)%uint64(workers)] <- row:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return runWorkers(workers, func(i int) error {
+		for row := range partitions[i] {
+			select {
+			case out <- fn(row):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+}
+
+// HashKey hashes the values of a partition key
+func HashKey(values ...any) uint64 {
+	h := fnv.New64a()
+	for _, v := range values {
+		if valuer, ok := v.(driver.Valuer); ok {
+			if val, err := valuer.Value(); err == nil {
+				v = val
+			}
+		}
+		fmt.Fprint(h, v)
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// sequenced is a row tagged with its position in the input
+type sequenced[T any] struct {
+	seq uint64
+	row *T
+}
+
+// orderedMap runs fn on the workers and reorders the results before sending them to out
+func orderedMap[In, Out any](ctx context.Context, in <-chan *In, out chan<- *Out, workers int, fn func(*In) *Out) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan sequenced[In], workers)
+	results := make(chan sequenced[Out], workers)
+	window := make(chan struct{}, workers*orderWindow) // bounds the rows buffered for reordering
+
+	// Tag rows with their sequence number
+	go func() {
+		defer close(jobs)
+		var seq uint64
+		for row := range in {
+			select {
+			case window <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- sequenced[In]{seq: seq, row: row}:
+			case <-ctx.Done():
+				return
+			}
+			seq++
+		}
+	}()
+
+	go func() {
+		runWorkers(workers, func(int) error {
+			for job := range jobs {
+				select {
+				case results <- sequenced[Out]{seq: job.seq, row: fn(job.row)}:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+		close(results)
+	}()
+
+	// Emit results in input order
+	pending := make(map[uint64]*Out)
+	var next uint64
+	for res := range results {
+		pending[res.seq] = res.row
+		for {
+			row, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			select {
+			case out <- row:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			<-window
+			next++
+		}
+	}
+
+	return ctx.Err()
+}
+
+// runWorkers runs work on n goroutines and returns the first error
+func runWorkers(n int, work func(worker int) error) error {
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := work(i); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
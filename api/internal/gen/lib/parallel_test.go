@@ -0,0 +1,111 @@
+package lib
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type parallelRow struct {
+	Key   int
+	Value int
+}
+
+func feedRows(n, keys int) <-chan *parallelRow {
+	in := make(chan *parallelRow)
+	go func() {
+		defer close(in)
+		for i := 0; i < n; i++ {
+			in <- &parallelRow{Key: i % keys, Value: i}
+		}
+	}()
+	return in
+}
+
+// slowDouble takes longer for even values so workers finish out of order
+func slowDouble(row *parallelRow) *parallelRow {
+	if row.Value%2 == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	return &parallelRow{Key: row.Key, Value: row.Value * 2}
+}
+
+func TestParallelMap_Ordered(t *testing.T) {
+	out := make(chan *parallelRow, 1000)
+	if err := ParallelMap(context.Background(), feedRows(200, 1), out, 4, true, slowDouble); err != nil {
+		t.Fatalf("ParallelMap failed: %v", err)
+	}
+	close(out)
+
+	i := 0
+	for row := range out {
+		if row.Value != i*2 {
+			t.Fatalf("row %d: got value %d, want %d", i, row.Value, i*2)
+		}
+		i++
+	}
+	if i != 200 {
+		t.Fatalf("got %d rows, want 200", i)
+	}
+}
+
+func TestParallelMap_Unordered(t *testing.T) {
+	out := make(chan *parallelRow, 1000)
+	if err := ParallelMap(context.Background(), feedRows(200, 1), out, 4, false, slowDouble); err != nil {
+		t.Fatalf("ParallelMap failed: %v", err)
+	}
+	close(out)
+
+	seen := make(map[int]bool)
+	for row := range out {
+		seen[row.Value] = true
+	}
+	if len(seen) != 200 {
+		t.Fatalf("got %d distinct rows, want 200", len(seen))
+	}
+}
+
+func TestPartitionedMap_KeepsOrderPerKey(t *testing.T) {
+	out := make(chan *parallelRow, 1000)
+	key := func(row *parallelRow) uint64 { return HashKey(row.Key) }
+	if err := PartitionedMap(context.Background(), feedRows(300, 7), out, 3, key, slowDouble); err != nil {
+		t.Fatalf("PartitionedMap failed: %v", err)
+	}
+	close(out)
+
+	last := make(map[int]int)
+	count := 0
+	for row := range out {
+		if prev, ok := last[row.Key]; ok && row.Value <= prev {
+			t.Fatalf("key %d: value %d emitted after %d", row.Key, row.Value, prev)
+		}
+		last[row.Key] = row.Value
+		count++
+	}
+	if count != 300 {
+		t.Fatalf("got %d rows, want 300", count)
+	}
+}
+
+func TestParallelMap_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan *parallelRow)
+	out := make(chan *parallelRow) // never read
+
+	done := make(chan error, 1)
+	go func() {
+		done <- ParallelMap(ctx, in, out, 2, true, slowDouble)
+	}()
+	in <- &parallelRow{Value: 1}
+	cancel()
+	close(in)
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected an error after cancellation")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("ParallelMap did not return after cancellation")
+	}
+}
@@ -0,0 +1,109 @@
+package lib
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// SchemaConn returns a connection of db on which stmt selected the schema. Bound queries hold a
+// single statement, so the schema cannot be selected in the query itself.
+func SchemaConn(ctx context.Context, db *sql.DB, stmt string) (*sql.Conn, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, stmt); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("schema selection failed: %w", err)
+	}
+	return conn, nil
+}
+
+// RangeBounds splits the values of the integer column returned by query into n ranges of equal
+// width and returns the split points, fewer than n-1 when the range is narrow and none when the
+// column holds no value.
+func RangeBounds(ctx context.Context, q Querier, query, column string, n int, args ...any) ([]int64, error) {
+	var lo, hi sql.NullInt64
+	boundsQuery := fmt.Sprintf("SELECT MIN(%s), MAX(%s) FROM (%s) AS part", column, column, query)
+	if err := q.QueryRowContext(ctx, boundsQuery, args...).Scan(&lo, &hi); err != nil {
+		return nil, fmt.Errorf("partition bounds query failed: %w", err)
+	}
+	if !lo.Valid || !hi.Valid || n < 2 {
+		return nil, nil
+	}
+
+	// Unsigned arithmetic so the widest int64 ranges do not overflow
+	step := uint64(hi.Int64-lo.Int64)/uint64(n) + 1
+	bounds := make([]int64, 0, n-1)
+	for i := 1; i < n; i++ {
+		bound := lo.Int64 + int64(uint64(i)*step)
+		if bound > hi.Int64 || bound <= lo.Int64 {
+			break
+		}
+		bounds = append(bounds, bound)
+	}
+	return bounds, nil
+}
+
+// RangePartitionQuery restricts query to partition part (0-based) of the ranges delimited by
+// bounds. The first and last partitions are open-ended and NULLs go to the first one.
+func RangePartitionQuery(query, column string, bounds []int64, part int) string {
+	var conds []string
+	if part > 0 {
+		conds = append(conds, fmt.Sprintf("%s >= %d", column, bounds[part-1]))
+	}
+	if part < len(bounds) {
+		conds = append(conds, fmt.Sprintf("%s < %d", column, bounds[part]))
+	}
+	if len(conds) == 0 {
+		return query
+	}
+	cond := strings.Join(conds, " AND ")
+	if part == 0 {
+		cond += fmt.Sprintf(" OR %s IS NULL", column)
+	}
+	return fmt.Sprintf("SELECT * FROM (%s) AS part WHERE %s", query, cond)
+}
+
+// ModuloPartitionQuery restricts query to the rows of partition part (0-based) of n, picked by
+// the remainder of the integer column. NULLs go to the first partition.
+func ModuloPartitionQuery(query, column string, n, part int) string {
+	if n < 2 {
+		return query
+	}
+	cond := fmt.Sprintf("ABS(%s %% %d) = %d", column, n, part)
+	if part == 0 {
+		cond += fmt.Sprintf(" OR %s IS NULL", column)
+	}
+	return fmt.Sprintf("SELECT * FROM (%s) AS part WHERE %s", query, cond)
+}
+
+// ReadPartitions runs read for each of n partitions concurrently. The first error cancels the
+// other partitions and is returned.
+func ReadPartitions(ctx context.Context, n int, read func(ctx context.Context, part int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for part := 0; part < n; part++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := read(ctx, part); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,87 @@
+package lib
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestPartitionQueries_CoverEachRowOnce(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+
+	if _, err := db.ExecContext(ctx, `CREATE TABLE items (id INTEGER, name TEXT)`); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	for i := -5; i <= 100; i++ {
+		if _, err := db.ExecContext(ctx, `INSERT INTO items VALUES (?, 'x')`, i); err != nil {
+			t.Fatalf("insert failed: %v", err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO items VALUES (NULL, 'null')`); err != nil {
+		t.Fatalf("insert failed: %v", err)
+	}
+	const total = 107
+	query := `SELECT id, name FROM items WHERE name <> ?`
+
+	countRows := func(t *testing.T, partQuery string) int {
+		t.Helper()
+		var n int
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM ("+partQuery+") AS c", "skip").Scan(&n); err != nil {
+			t.Fatalf("count failed for %s: %v", partQuery, err)
+		}
+		return n
+	}
+
+	t.Run("range", func(t *testing.T) {
+		bounds, err := RangeBounds(ctx, db, query, `"id"`, 4, "skip")
+		if err != nil {
+			t.Fatalf("RangeBounds failed: %v", err)
+		}
+		if len(bounds) != 3 {
+			t.Fatalf("got bounds %v, want 3 split points", bounds)
+		}
+		sum := 0
+		for part := 0; part <= len(bounds); part++ {
+			n := countRows(t, RangePartitionQuery(query, `"id"`, bounds, part))
+			if n == 0 {
+				t.Errorf("partition %d is empty", part)
+			}
+			sum += n
+		}
+		if sum != total {
+			t.Errorf("partitions returned %d rows, want %d", sum, total)
+		}
+	})
+
+	t.Run("modulo", func(t *testing.T) {
+		sum := 0
+		for part := 0; part < 4; part++ {
+			sum += countRows(t, ModuloPartitionQuery(query, `"id"`, 4, part))
+		}
+		if sum != total {
+			t.Errorf("partitions returned %d rows, want %d", sum, total)
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		bounds, err := RangeBounds(ctx, db, query+" AND id > 1000", `"id"`, 4, "skip")
+		if err != nil || bounds != nil {
+			t.Fatalf("got bounds %v, err %v, want none", bounds, err)
+		}
+	})
+}
+
+func TestReadPartitions_CancelsOnError(t *testing.T) {
+	boom := errors.New("boom")
+	err := ReadPartitions(context.Background(), 3, func(ctx context.Context, part int) error {
+		if part == 1 {
+			return boom
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("got %v, want %v", err, boom)
+	}
+}
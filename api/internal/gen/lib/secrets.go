@@ -0,0 +1,36 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SecretsDirEnv names the environment variable overriding DefaultSecretsDir
+const SecretsDirEnv = "DOS_SECRETS_DIR"
+
+// DefaultSecretsDir is where the executor mounts the secrets of a job, one file per secret
+const DefaultSecretsDir = "/run/dos-secrets"
+
+// Secret returns the secret the executor provided under name: the environment variable name when
+// set, the file name of the secrets directory otherwise. Secrets never appear in the source.
+func Secret(name string) (string, error) {
+	if value, ok := os.LookupEnv(name); ok {
+		return value, nil
+	}
+
+	dir := os.Getenv(SecretsDirEnv)
+	if dir == "" {
+		dir = DefaultSecretsDir
+	}
+	value, err := os.ReadFile(filepath.Join(dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("secret %s is not provided: set the %s environment variable or mount it in %s", name, name, dir)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret %s: %w", name, err)
+	}
+	return strings.TrimSuffix(string(value), "\n"), nil
+}
@@ -0,0 +1,30 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecret(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(SecretsDirEnv, dir)
+	if err := os.WriteFile(filepath.Join(dir, "DOS_DB_MAIN"), []byte("user:pw@tcp(db)/shop\n"), 0600); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+
+	if v, err := Secret("DOS_DB_MAIN"); err != nil || v != "user:pw@tcp(db)/shop" {
+		t.Errorf("Secret from file = %q, %v", v, err)
+	}
+
+	// The environment takes precedence over the mounted file
+	t.Setenv("DOS_DB_MAIN", "from-env")
+	if v, err := Secret("DOS_DB_MAIN"); err != nil || v != "from-env" {
+		t.Errorf("Secret from env = %q, %v", v, err)
+	}
+
+	if _, err := Secret("DOS_MISSING"); err == nil || !strings.Contains(err.Error(), "secret DOS_MISSING is not provided") {
+		t.Errorf("expected a missing secret error, got %v", err)
+	}
+}
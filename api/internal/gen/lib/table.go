@@ -0,0 +1,90 @@
+package lib
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// ColumnDDL is the statement adding a column to an existing table
+type ColumnDDL struct {
+	Name string
+	DDL  string
+}
+
+// EnsureTable runs createDDL when the table does not exist (an empty createDDL makes it an error).
+// When it does, the statements of the columns it is missing are run, so the table follows new
+// upstream columns.
+func EnsureTable(ctx context.Context, db *sql.DB, driverName, schema, table, createDDL string, addColumns []ColumnDDL) error {
+	existing, err := tableColumns(ctx, db, driverName, schema, table)
+	if err != nil {
+		return fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+
+	if len(existing) == 0 {
+		if createDDL == "" {
+			return fmt.Errorf("table %s does not exist", table)
+		}
+		if _, err := db.ExecContext(ctx, createDDL); err != nil {
+			return fmt.Errorf("failed to create table %s: %w", table, err)
+		}
+		return nil
+	}
+
+	for _, col := range addColumns {
+		if existing[strings.ToLower(col.Name)] {
+			continue
+		}
+		if _, err := db.ExecContext(ctx, col.DDL); err != nil {
+			return fmt.Errorf("failed to add column %s to %s: %w", col.Name, table, err)
+		}
+	}
+	return nil
+}
+
+// tableColumns returns the lower-cased column names of a table, empty when it does not exist
+func tableColumns(ctx context.Context, db *sql.DB, driverName, schema, table string) (map[string]bool, error) {
+	// SQLite has no information_schema
+	if driverName == "sqlite" {
+		if schema == "" {
+			schema = "main"
+		}
+		return queryColumnNames(ctx, db, "SELECT name FROM pragma_table_info(?, ?)", table, schema)
+	}
+
+	query := "SELECT column_name FROM information_schema.columns WHERE table_name = ?"
+	args := []any{table}
+	switch {
+	case schema != "":
+		query += " AND table_schema = ?"
+		args = append(args, schema)
+	case driverName == "postgres", driverName == "duckdb":
+		query += " AND table_schema = current_schema()"
+	case driverName == "mysql":
+		query += " AND table_schema = DATABASE()"
+	case driverName == "sqlserver":
+		query += " AND table_schema = SCHEMA_NAME()"
+	}
+
+	return queryColumnNames(ctx, db, Rebind(driverName, query), args...)
+}
+
+// queryColumnNames runs a query returning column names and lower-cases them
+func queryColumnNames(ctx context.Context, db *sql.DB, query string, args ...any) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[strings.ToLower(name)] = true
+	}
+	return columns, rows.Err()
+}
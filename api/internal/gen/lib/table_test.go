@@ -0,0 +1,47 @@
+package lib
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+func openSQLite(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestEnsureTable_SQLite(t *testing.T) {
+	ctx := context.Background()
+	db := openSQLite(t)
+
+	create := `CREATE TABLE "main"."users" ("id" INTEGER NOT NULL, PRIMARY KEY ("id"))`
+	addName := []ColumnDDL{{Name: "name", DDL: `ALTER TABLE "main"."users" ADD COLUMN "name" TEXT`}}
+
+	if err := EnsureTable(ctx, db, "sqlite", "", "users", create, addName); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+	// The table exists now, so only the missing column is added
+	if err := EnsureTable(ctx, db, "sqlite", "", "users", create, addName); err != nil {
+		t.Fatalf("evolve failed: %v", err)
+	}
+	if err := EnsureTable(ctx, db, "sqlite", "main", "users", create, addName); err != nil {
+		t.Fatalf("second evolve failed: %v", err)
+	}
+
+	columns, err := tableColumns(ctx, db, "sqlite", "", "users")
+	if err != nil {
+		t.Fatalf("tableColumns failed: %v", err)
+	}
+	if len(columns) != 2 || !columns["id"] || !columns["name"] {
+		t.Fatalf("got columns %v, want id and name", columns)
+	}
+}
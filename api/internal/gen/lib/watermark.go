@@ -0,0 +1,167 @@
+package lib
+
+import (
+	"bufio"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WatermarkLogPrefix starts the output line through which a job hands its new high-water marks
+// to the API, which stores them for the next run
+const WatermarkLogPrefix = "##dos-watermark "
+
+// watermarkReport is the payload of a watermark output line
+type watermarkReport struct {
+	NodeID int    `json:"nodeId"`
+	Value  string `json:"value"`
+}
+
+// WatermarkSet collects the high-water marks reached by incremental db_input nodes
+type WatermarkSet struct {
+	mu     sync.Mutex
+	values map[int]string
+}
+
+// Set records the high-water mark reached by a node
+func (w *WatermarkSet) Set(nodeID int, value string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.values == nil {
+		w.values = make(map[int]string)
+	}
+	w.values[nodeID] = value
+}
+
+// Report prints the recorded high-water marks. Call it once the whole run succeeded, so a
+// failed run reads the same rows again.
+func (w *WatermarkSet) Report() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for nodeID, value := range w.values {
+		line, _ := json.Marshal(watermarkReport{NodeID: nodeID, Value: value})
+		fmt.Println(WatermarkLogPrefix + string(line))
+	}
+}
+
+// ParseWatermarkReports returns the high-water marks reported in a job output, keyed by node ID
+func ParseWatermarkReports(logs string) map[int]string {
+	values := make(map[int]string)
+	scanner := bufio.NewScanner(strings.NewReader(logs))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		payload, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), WatermarkLogPrefix)
+		if !ok {
+			continue
+		}
+		var report watermarkReport
+		if err := json.Unmarshal([]byte(payload), &report); err != nil {
+			continue
+		}
+		values[report.NodeID] = report.Value
+	}
+	return values
+}
+
+// ParseWatermark converts a stored high-water mark to the value bound in the incremental query:
+// an int64 for "int", a time.Time for "timestamp", the string itself otherwise
+func ParseWatermark(value, kind string) any {
+	switch kind {
+	case "int":
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+	case "timestamp":
+		if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+			return t
+		}
+	}
+	return value
+}
+
+// Watermark tracks the highest value read from the column of an incremental db_input node.
+// Rows may come in any order, so each value is compared rather than the last one kept.
+type Watermark struct {
+	max any
+}
+
+// Observe records a value of the tracked column, NULLs are ignored
+func (w *Watermark) Observe(v any) {
+	v = watermarkValue(v)
+	if v == nil {
+		return
+	}
+	if w.max == nil || watermarkLess(w.max, v) {
+		w.max = v
+	}
+}
+
+// Merge records the high-water mark of another tracker, e.g. of a partition read concurrently
+func (w *Watermark) Merge(other *Watermark) {
+	w.Observe(other.max)
+}
+
+// String returns the high-water mark as stored, empty when no row was read
+func (w *Watermark) String() string {
+	if w.max == nil {
+		return ""
+	}
+	return CheckpointKey(w.max)
+}
+
+// watermarkValue unwraps nullable and pointer values and widens numbers so they compare
+func watermarkValue(v any) any {
+	if valuer, ok := v.(driver.Valuer); ok {
+		val, err := valuer.Value()
+		if err != nil {
+			return nil
+		}
+		v = val
+	}
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint()
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	}
+	return rv.Interface()
+}
+
+func watermarkLess(a, b any) bool {
+	switch x := a.(type) {
+	case int64:
+		if y, ok := b.(int64); ok {
+			return x < y
+		}
+	case uint64:
+		if y, ok := b.(uint64); ok {
+			return x < y
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			return x < y
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Before(y)
+		}
+	}
+	return CheckpointKey(a) < CheckpointKey(b)
+}
@@ -0,0 +1,71 @@
+package lib
+
+import (
+	"database/sql"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestWatermarkSet_ReportAndParse(t *testing.T) {
+	var set WatermarkSet
+	set.Set(3, "2024-03-01T12:30:00.5Z")
+	set.Set(7, "42")
+
+	// Capture what Report prints
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe failed: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	set.Report()
+	os.Stdout = stdout
+	w.Close()
+	out, _ := io.ReadAll(r)
+
+	logs := "starting\n" + string(out) + "Pipeline completed successfully\n"
+	want := map[int]string{3: "2024-03-01T12:30:00.5Z", 7: "42"}
+	if got := ParseWatermarkReports(logs); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestParseWatermark(t *testing.T) {
+	if got := ParseWatermark("42", "int"); got != int64(42) {
+		t.Errorf("int: got %#v", got)
+	}
+	want := time.Date(2024, 3, 1, 12, 30, 0, 500000000, time.UTC)
+	if got, ok := ParseWatermark("2024-03-01T12:30:00.5Z", "timestamp").(time.Time); !ok || !got.Equal(want) {
+		t.Errorf("timestamp: got %#v", got)
+	}
+	if got := ParseWatermark("b3f1", "uuid"); got != "b3f1" {
+		t.Errorf("uuid: got %#v", got)
+	}
+}
+
+func TestWatermark_KeepsHighest(t *testing.T) {
+	var ids Watermark
+	for _, v := range []int32{5, 12, 3} {
+		ids.Observe(v)
+	}
+	ids.Observe(sql.NullInt64{})
+	if got := ids.String(); got != "12" {
+		t.Errorf("int: got %q, want 12", got)
+	}
+
+	var updated Watermark
+	late := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	updated.Observe(sql.NullTime{Time: late, Valid: true})
+	updated.Observe(sql.NullTime{Time: late.Add(-time.Hour), Valid: true})
+	if got := updated.String(); got != "2024-03-02T00:00:00Z" {
+		t.Errorf("timestamp: got %q", got)
+	}
+
+	var empty Watermark
+	if got := empty.String(); got != "" {
+		t.Errorf("empty: got %q", got)
+	}
+}
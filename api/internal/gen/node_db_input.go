@@ -3,6 +3,7 @@ package gen
 import (
 	"api/internal/api/models"
 	"fmt"
+	"strings"
 )
 
 // DBInputGenerator generates code for db_input nodes
@@ -55,7 +56,7 @@ func (g *DBInputGenerator) GetLaunchArgs(node *models.Node, channels []channelIn
 
 	// Add output channel
 	for _, ch := range channels {
-		if ch.fromNodeID == node.ID {
+		if ch.fromNodeID == node.ID && !ch.reject {
 			args = append(args, fmt.Sprintf("ch_%d", ch.portID))
 			break
 		}
@@ -84,6 +85,10 @@ func (g *DBInputGenerator) GenerateFuncData(node *models.Node, ctx *GeneratorCon
 
 	// Build scan fields list (must match struct field names)
 	scanFields := uniqueFieldNames(config.DataModels)
+	scanTargets := make([]string, len(scanFields))
+	for i, col := range config.DataModels {
+		scanTargets[i] = scanTarget(config.Connection.Type, col, scanFields[i])
+	}
 
 	// Use template engine
 	engine, err := NewTemplateEngine()
@@ -91,6 +96,55 @@ func (g *DBInputGenerator) GenerateFuncData(node *models.Node, ctx *GeneratorCon
 		return nil, fmt.Errorf("failed to create template engine: %w", err)
 	}
 
+	query, err := g.buildQuery(node, &config, ctx, scanFields)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split the read into concurrent queries over partitions of a column
+	templateName := "node_db_input.go.tmpl"
+	var parallel *DBInputParallelTemplateData
+	if p := config.Parallel; p != nil {
+		if source, ok := ctx.ResumeSources[node.ID]; ok {
+			return nil, fmt.Errorf("db_input node %d: parallel reads return rows in no particular order and cannot feed checkpointed db_output node %d", node.ID, source.OutputNodeID)
+		}
+		strategy := p.Strategy
+		if strategy == "" {
+			strategy = models.PartitionRange
+		}
+		parallel = &DBInputParallelTemplateData{
+			Column:     dialectFor(config.Connection.Type).QuoteIdent(p.Column),
+			Partitions: max(p.Partitions, 1),
+			Strategy:   string(strategy),
+		}
+		templateName = "node_db_input_parallel.go.tmpl"
+		ctx.AddImport("sync/atomic")
+	}
+
+	// Resume after the checkpoint of the output this node feeds, if any
+	var resume *DBInputResumeTemplateData
+	if source, ok := ctx.ResumeSources[node.ID]; ok {
+		before, after, err := config.ResumeQueryParts(source.KeyColumn)
+		if query.Bound {
+			before, after, err = config.WrapResumeQuery(query.Query, source.KeyColumn)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("db_input node %d feeding checkpointed db_output node %d: %w", node.ID, source.OutputNodeID, err)
+		}
+		resume = &DBInputResumeTemplateData{
+			OutputNodeID: source.OutputNodeID,
+			KeyColumn:    source.KeyColumn,
+			Driver:       config.Connection.GetDriverName(),
+			QueryBefore:  before,
+			QueryAfter:   after,
+		}
+	}
+
+	onError, err := errorPolicyTemplateData(node, ctx, structName)
+	if err != nil {
+		return nil, err
+	}
+
 	// Prepare template data
 	templateData := struct {
 		FuncName         string
@@ -98,20 +152,32 @@ func (g *DBInputGenerator) GenerateFuncData(node *models.Node, ctx *GeneratorCon
 		NodeID           int
 		NodeName         string
 		Query            string
-		ScanFields       []string
+		Args             []string
+		SchemaStatement  string
+		Watermark        string
+		ScanTargets      []string
 		ProgressInterval int
+		Resume           *DBInputResumeTemplateData
+		Parallel         *DBInputParallelTemplateData
+		OnError          *ErrorPolicyTemplateData
 	}{
 		FuncName:         funcName,
 		StructName:       structName,
 		NodeID:           node.ID,
 		NodeName:         node.Name,
-		Query:            config.QueryWithSchema,
-		ScanFields:       scanFields,
+		Query:            query.Query,
+		Args:             query.Args,
+		SchemaStatement:  query.SchemaStatement,
+		Watermark:        query.Watermark,
+		ScanTargets:      scanTargets,
 		ProgressInterval: 1000,
+		Resume:           resume,
+		Parallel:         parallel,
+		OnError:          onError,
 	}
 
 	// Generate body using template
-	body, err := engine.GenerateNodeFunction("node_db_input.go.tmpl", templateData)
+	body, err := engine.GenerateNodeFunction(templateName, templateData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate db_input function: %w", err)
 	}
@@ -124,3 +190,142 @@ func (g *DBInputGenerator) GenerateFuncData(node *models.Node, ctx *GeneratorCon
 		Body:      body,
 	}, nil
 }
+
+// scanTarget returns the rows.Scan argument of a column. DuckDB exact numerics read as text go
+// through lib.NumericText, the driver returning them as a Decimal or a *big.Int.
+func scanTarget(dbType models.DBType, col models.DataModel, field string) string {
+	target := "&row." + field
+	if dbType != models.DBTypeDuckDB || col.GoFieldType() != "sql.NullString" {
+		return target
+	}
+	t := strings.ToUpper(col.Type)
+	if i := strings.IndexByte(t, '('); i >= 0 {
+		t = t[:i]
+	}
+	switch t {
+	case "DECIMAL", "NUMERIC", "HUGEINT", "UHUGEINT":
+		return "lib.NumericText(" + target + ")"
+	}
+	return target
+}
+
+// dbInputQuery is the query a db_input node runs and the Go expressions of its arguments
+type dbInputQuery struct {
+	Query           string
+	Args            []string
+	SchemaStatement string // run on the connection first when the query has arguments
+	Watermark       string // struct field tracked as high-water mark, incremental mode only
+	Bound           bool   // run without the schema prefix, which cannot be wrapped nor bound
+}
+
+// buildQuery binds the named parameters of the query to the job parameters and, in incremental
+// mode, restricts it to the rows above the high-water mark of the previous run. Queries without
+// either, and not split into partitions, run as is behind the schema prefix.
+func (g *DBInputGenerator) buildQuery(node *models.Node, config *models.DBInputConfig, ctx *GeneratorContext, fieldNames []string) (dbInputQuery, error) {
+	d := dialectFor(config.Connection.Type)
+
+	query, values, err := bindNamedParams(d, config.Query, ctx.Parameters, 1)
+	if err != nil {
+		return dbInputQuery{}, fmt.Errorf("db_input node %d: %w", node.ID, err)
+	}
+	args := make([]string, len(values))
+	for i, v := range values {
+		args[i] = fmt.Sprintf("%q", v)
+	}
+
+	var watermark string
+	if inc := config.Incremental; inc != nil {
+		for i, col := range config.DataModels {
+			if strings.EqualFold(col.Name, inc.Column) {
+				watermark = fieldNames[i]
+				break
+			}
+		}
+		if watermark == "" {
+			return dbInputQuery{}, fmt.Errorf("db_input node %d: incremental column %q is not in the data models", node.ID, inc.Column)
+		}
+
+		if last, ok := ctx.Watermarks[node.ID]; ok && last != "" {
+			query = fmt.Sprintf("SELECT * FROM (%s) AS inc WHERE %s > %s",
+				strings.TrimSuffix(strings.TrimSpace(query), ";"), d.QuoteIdent(inc.Column), d.Placeholder(len(args)+1))
+			args = append(args, fmt.Sprintf("lib.ParseWatermark(%q, %q)", last, string(inc.Type)))
+		}
+		ctx.UsesWatermarks = true
+	}
+
+	if len(args) == 0 && watermark == "" && config.Parallel == nil {
+		return dbInputQuery{Query: config.QueryWithSchema}, nil
+	}
+	return dbInputQuery{
+		Query:           strings.TrimSuffix(strings.TrimSpace(query), ";"),
+		Args:            args,
+		SchemaStatement: config.SchemaStatement(),
+		Watermark:       watermark,
+		Bound:           true,
+	}, nil
+}
+
+// bindNamedParams replaces the :name parameters of a query with the placeholders of the
+// dialect, numbered from first, and returns the values bound to them in order. Casts (::),
+// comments and quoted strings or identifiers are left untouched.
+func bindNamedParams(d Dialect, query string, params map[string]string, first int) (string, []string, error) {
+	var b strings.Builder
+	var values []string
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			end := c
+			if c == '[' {
+				end = ']'
+			}
+			j := i + 1
+			for j < len(query) && query[j] != end {
+				j++
+			}
+			b.WriteString(query[i:min(j+1, len(query))])
+			i = j
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			j := strings.IndexByte(query[i:], '\n')
+			if j < 0 {
+				j = len(query) - i
+			}
+			b.WriteString(query[i : i+j])
+			i += j - 1
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			j := strings.Index(query[i+2:], "*/")
+			if j < 0 {
+				j = len(query) - i - 2
+			} else {
+				j += 2
+			}
+			b.WriteString(query[i : i+2+j])
+			i += 1 + j
+		case c == ':' && i+1 < len(query) && query[i+1] == ':':
+			b.WriteString("::")
+			i++
+		case c == ':' && i+1 < len(query) && isParamStart(query[i+1]):
+			j := i + 1
+			for j < len(query) && (isParamStart(query[j]) || (query[j] >= '0' && query[j] <= '9')) {
+				j++
+			}
+			name := query[i+1 : j]
+			value, ok := params[name]
+			if !ok {
+				return "", nil, fmt.Errorf("query parameter %q is not defined in the job parameters", name)
+			}
+			values = append(values, value)
+			b.WriteString(d.Placeholder(first + len(values) - 1))
+			i = j - 1
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String(), values, nil
+}
+
+func isParamStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
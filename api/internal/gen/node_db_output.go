@@ -3,6 +3,7 @@ package gen
 import (
 	"api/internal/api/models"
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -29,7 +30,6 @@ func (g *DBOutputGenerator) GenerateFuncData(node *models.Node, ctx *GeneratorCo
 	ctx.AddImport("context")
 	ctx.AddImport("database/sql")
 	ctx.AddImport("fmt")
-	ctx.AddImport("strings")
 	ctx.AddImport("test/lib")
 	ctx.AddImportAlias("_", config.Connection.GetImportPath())
 
@@ -41,6 +41,16 @@ func (g *DBOutputGenerator) GenerateFuncData(node *models.Node, ctx *GeneratorCo
 		inputRowType = "any" // fallback
 	}
 
+	// A managed table without explicit columns follows the upstream row, and an evolving one
+	// gains the upstream columns it does not list yet
+	if config.ManagesTable() {
+		if len(config.DataModels) == 0 {
+			config.DataModels = upstreamDataModels(node)
+		} else if config.EvolveSchema {
+			config.DataModels = mergeDataModels(config.DataModels, upstreamDataModels(node))
+		}
+	}
+
 	// build the function based on mode
 	switch config.Mode {
 	case models.DbOutputModeInsert:
@@ -53,6 +63,10 @@ func (g *DBOutputGenerator) GenerateFuncData(node *models.Node, ctx *GeneratorCo
 		return g.generateMergeFuncData(node, &config, ctx, funcName, inputRowType)
 	case models.DbOutputModeTruncate:
 		return g.generateTruncateFuncData(node, &config, ctx, funcName)
+	case models.DbOutputModeBulk:
+		return g.generateBulkFuncData(node, &config, ctx, funcName, inputRowType)
+	case models.DbOutputModeSCD2:
+		return g.generateSCD2FuncData(node, &config, ctx, funcName, inputRowType)
 	default:
 		return nil, fmt.Errorf("db_output node %q: unsupported mode %q", node.Name, config.Mode)
 	}
@@ -91,6 +105,9 @@ func (g *DBOutputGenerator) findInputRowType(node *models.Node, ctx *GeneratorCo
 			// port.Node is the source node
 			sourceNode := &port.Node
 			if sourceNode.ID != 0 {
+				if structName, ok := ctx.InputStructName(node.ID, sourceNode.ID); ok {
+					return structName
+				}
 				return ctx.StructName(sourceNode)
 			}
 		}
@@ -98,16 +115,172 @@ func (g *DBOutputGenerator) findInputRowType(node *models.Node, ctx *GeneratorCo
 	return ""
 }
 
+// upstreamDataModels returns the columns of the node feeding a db_output node
+func upstreamDataModels(node *models.Node) []models.DataModel {
+	for _, port := range node.InputPort {
+		if port.Type != models.PortTypeInput {
+			continue
+		}
+		source := &port.Node
+		switch source.Type {
+		case models.NodeTypeDBInput:
+			if config, err := source.GetDBInputConfig(); err == nil {
+				return config.DataModels
+			}
+		case models.NodeTypeMap:
+			if config, err := source.GetMapConfig(); err == nil && len(config.Outputs) > 0 {
+				columns := make([]models.DataModel, len(config.Outputs[0].Columns))
+				for i, col := range config.Outputs[0].Columns {
+					columns[i] = models.DataModel{Name: col.Name, GoType: col.DataType, Nullable: true}
+				}
+				return columns
+			}
+		}
+	}
+	return nil
+}
+
+// mergeDataModels appends to columns the upstream columns missing from it, keeping the configured ones first
+func mergeDataModels(columns, upstream []models.DataModel) []models.DataModel {
+	merged := slices.Clone(columns)
+	for _, col := range upstream {
+		if !slices.ContainsFunc(merged, func(c models.DataModel) bool { return c.Name == col.Name }) {
+			merged = append(merged, col)
+		}
+	}
+	return merged
+}
+
+// tableTemplateData returns the statements creating or evolving the target table, nil when not managed
+func tableTemplateData(config *models.DBOutputConfig) *DBOutputTableTemplateData {
+	if !config.ManagesTable() {
+		return nil
+	}
+
+	data := &DBOutputTableTemplateData{
+		Driver: config.Connection.GetDriverName(),
+		Schema: config.DbSchema,
+		Table:  config.Table,
+	}
+	if config.AutoCreateTable {
+		data.Create = CreateTableDDL(config.Connection.Type, config.DbSchema, config.Table, config.DataModels, config.KeyColumns)
+	}
+	if config.EvolveSchema {
+		for _, col := range config.DataModels {
+			data.AddColumns = append(data.AddColumns, ColumnDDLTemplate{
+				Name: col.Name,
+				DDL:  AddColumnDDL(config.Connection.Type, config.DbSchema, config.Table, col),
+			})
+		}
+	}
+	return data
+}
+
+// commitTemplateData returns the commit strategy of a db_output node, nil when each batch commits on its own
+func commitTemplateData(node *models.Node, config *models.DBOutputConfig, checkpoint *DBOutputCheckpointTemplateData, onError *ErrorPolicyTemplateData) (*DBOutputCommitTemplateData, error) {
+	// Rows of a failed batch are retried one by one in the checkpoint transaction, each
+	// behind a savepoint so a failing row does not abort the others
+	if checkpoint != nil && onError != nil && checkpoint.Savepoint == "" {
+		return nil, fmt.Errorf("db_output node %q: the %s error policy cannot be combined with checkpoints on %s", node.Name, onError.Mode, config.Connection.Type)
+	}
+
+	strategy := config.CommitStrategy
+	if strategy == "" {
+		strategy = models.CommitAutocommit
+	}
+	if strategy == models.CommitAutocommit && !config.TruncateBeforeLoad {
+		return nil, nil
+	}
+
+	data := &DBOutputCommitTemplateData{}
+	switch strategy {
+	case models.CommitAutocommit:
+	case models.CommitSingle:
+		data.Every = -1
+	case models.CommitBatches:
+		if config.CommitEvery <= 0 {
+			return nil, fmt.Errorf("db_output node %q: commitEvery must be positive with the batches commit strategy", node.Name)
+		}
+		data.Every = config.CommitEvery
+	default:
+		return nil, fmt.Errorf("db_output node %q: unknown commit strategy %q", node.Name, strategy)
+	}
+
+	// A failed statement aborts the transaction, so rows cannot be retried one by one, and
+	// checkpoints are only valid once committed
+	if data.Every != 0 && checkpoint != nil {
+		return nil, fmt.Errorf("db_output node %q: checkpoints require the autocommit strategy", node.Name)
+	}
+	if data.Every != 0 && onError != nil {
+		return nil, fmt.Errorf("db_output node %q: the %s error policy requires the autocommit strategy", node.Name, onError.Mode)
+	}
+
+	if config.TruncateBeforeLoad {
+		if config.Mode != models.DbOutputModeInsert && config.Mode != models.DbOutputModeMerge {
+			return nil, fmt.Errorf("db_output node %q: truncateBeforeLoad only applies to insert and merge modes", node.Name)
+		}
+		d := dialectFor(config.Connection.Type)
+		data.Truncate = d.TruncateStatement(quoteTable(d, config.DbSchema, config.Table), data.Every != 0)
+	}
+
+	return data, nil
+}
+
+// rejectCommitSettings refuses the commit settings of a mode that manages its own transactions
+func rejectCommitSettings(node *models.Node, config *models.DBOutputConfig) error {
+	if config.TruncateBeforeLoad {
+		return fmt.Errorf("db_output node %q: truncateBeforeLoad only applies to insert and merge modes", node.Name)
+	}
+	if (config.CommitStrategy != "" && config.CommitStrategy != models.CommitAutocommit) || config.CommitEvery != 0 {
+		return fmt.Errorf("db_output node %q: %s mode manages its own transactions and does not support commitStrategy or commitEvery", node.Name, config.Mode)
+	}
+	return nil
+}
+
+// supportsCheckpoint reports whether a db_output node records per-batch checkpoints
+func supportsCheckpoint(config *models.DBOutputConfig) bool {
+	if config.Checkpoint == nil {
+		return false
+	}
+	return config.Mode == models.DbOutputModeInsert || config.Mode == models.DbOutputModeMerge
+}
+
+// checkpointTemplateData returns the checkpoint settings of a db_output node, nil when disabled
+func (g *DBOutputGenerator) checkpointTemplateData(node *models.Node, config *models.DBOutputConfig, ctx *GeneratorContext, action string) (*DBOutputCheckpointTemplateData, error) {
+	if !supportsCheckpoint(config) {
+		return nil, nil
+	}
+
+	data := &DBOutputCheckpointTemplateData{
+		JobID:  ctx.JobID,
+		Driver: config.Connection.GetDriverName(),
+		Action: action,
+	}
+	data.Savepoint, data.RollbackToSavepoint = dialectFor(config.Connection.Type).SavepointStatements("dos_row")
+
+	if key := config.Checkpoint.KeyColumn; key != "" {
+		for _, col := range config.DataModels {
+			if col.Name == key {
+				data.KeyField = col.GoFieldName()
+				break
+			}
+		}
+		if data.KeyField == "" {
+			return nil, fmt.Errorf("db_output node %q: checkpoint key column %q is not in DataModels", node.Name, key)
+		}
+	}
+
+	return data, nil
+}
+
 // generateInsertFuncData generates a batch insert function data using template
 func (g *DBOutputGenerator) generateInsertFuncData(node *models.Node, config *models.DBOutputConfig, ctx *GeneratorContext, funcName, inputRowType string) (*NodeFunctionData, error) {
 	if len(config.DataModels) == 0 {
 		return nil, fmt.Errorf("db_output node %q: DataModels is empty - cannot generate INSERT without columns", node.Name)
 	}
 
-	batchSize := config.BatchSize
-	if batchSize <= 0 {
-		batchSize = 500
-	}
+	ctx.AddImport("strings")
+	d := dialectFor(config.Connection.Type)
 
 	// build column names and field accessors
 	columns := make([]string, len(config.DataModels))
@@ -117,10 +290,19 @@ func (g *DBOutputGenerator) generateInsertFuncData(node *models.Node, config *mo
 		fieldAccessors[i] = col.GoFieldName()
 	}
 
-	// build schema-qualified table name
-	tableName := config.Table
-	if config.DbSchema != "" {
-		tableName = fmt.Sprintf("%s.%s", config.DbSchema, config.Table)
+	checkpoint, err := g.checkpointTemplateData(node, config, ctx, "insert")
+	if err != nil {
+		return nil, err
+	}
+
+	onError, err := errorPolicyTemplateData(node, ctx, inputRowType)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := commitTemplateData(node, config, checkpoint, onError)
+	if err != nil {
+		return nil, err
 	}
 
 	// Use template engine
@@ -130,15 +312,20 @@ func (g *DBOutputGenerator) generateInsertFuncData(node *models.Node, config *mo
 	}
 
 	templateData := DBOutputInsertTemplateData{
-		FuncName:       funcName,
-		NodeID:         node.ID,
-		NodeName:       node.Name,
-		InputType:      inputRowType,
-		TableName:      tableName,
-		ColumnNames:    strings.Join(columns, ", "),
+		FuncName:  funcName,
+		NodeID:    node.ID,
+		NodeName:  node.Name,
+		InputType: inputRowType,
+		QueryPrefix: fmt.Sprintf("INSERT INTO %s (%s) VALUES ",
+			quoteTable(d, config.DbSchema, config.Table), strings.Join(quoteColumns(d, columns), ", ")),
+		Placeholder:    d.PlaceholderExpr("len(args)+j+1"),
 		NumColumns:     len(config.DataModels),
 		FieldAccessors: fieldAccessors,
-		BatchSize:      batchSize,
+		BatchSize:      batchSizeFor(d, defaultBatchSize(config.BatchSize), len(columns)),
+		Table:          tableTemplateData(config),
+		Checkpoint:     checkpoint,
+		Commit:         commit,
+		OnError:        onError,
 	}
 
 	body, err := engine.GenerateNodeFunction("node_db_output_insert.go.tmpl", templateData)
@@ -164,15 +351,7 @@ func (g *DBOutputGenerator) generateUpdateFuncData(node *models.Node, config *mo
 		return nil, fmt.Errorf("db_output node %q: KeyColumns is empty - cannot generate UPDATE without key columns", node.Name)
 	}
 
-	batchSize := config.BatchSize
-	if batchSize <= 0 {
-		batchSize = 500
-	}
-
-	tableName := config.Table
-	if config.DbSchema != "" {
-		tableName = fmt.Sprintf("%s.%s", config.DbSchema, config.Table)
-	}
+	d := dialectFor(config.Connection.Type)
 
 	keySet := make(map[string]bool, len(config.KeyColumns))
 	for _, k := range config.KeyColumns {
@@ -189,6 +368,29 @@ func (g *DBOutputGenerator) generateUpdateFuncData(node *models.Node, config *mo
 			setAccessors = append(setAccessors, col.GoFieldName())
 		}
 	}
+	if len(setColumns) == 0 {
+		return nil, fmt.Errorf("db_output node %q: every column is a key column - cannot generate UPDATE without columns to set", node.Name)
+	}
+
+	// SET parameters come first, then the WHERE parameters
+	setClauses := make([]string, len(setColumns))
+	for i, col := range setColumns {
+		setClauses[i] = fmt.Sprintf("%s = %s", d.QuoteIdent(col), d.Placeholder(i+1))
+	}
+	whereClauses := make([]string, len(keyColumns))
+	for i, col := range keyColumns {
+		whereClauses[i] = fmt.Sprintf("%s = %s", d.QuoteIdent(col), d.Placeholder(len(setColumns)+i+1))
+	}
+
+	onError, err := errorPolicyTemplateData(node, ctx, inputRowType)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := commitTemplateData(node, config, nil, onError)
+	if err != nil {
+		return nil, err
+	}
 
 	engine, err := NewTemplateEngine()
 	if err != nil {
@@ -196,17 +398,17 @@ func (g *DBOutputGenerator) generateUpdateFuncData(node *models.Node, config *mo
 	}
 
 	templateData := DBOutputUpdateTemplateData{
-		FuncName:     funcName,
-		NodeID:       node.ID,
-		NodeName:     node.Name,
-		InputType:    inputRowType,
-		TableName:    tableName,
-		NumColumns:   len(config.DataModels),
-		BatchSize:    batchSize,
-		SetColumns:   setColumns,
+		FuncName:  funcName,
+		NodeID:    node.ID,
+		NodeName:  node.Name,
+		InputType: inputRowType,
+		Query: fmt.Sprintf("UPDATE %s SET %s WHERE %s", quoteTable(d, config.DbSchema, config.Table),
+			strings.Join(setClauses, ", "), strings.Join(whereClauses, " AND ")),
+		BatchSize:    defaultBatchSize(config.BatchSize),
 		SetAccessors: setAccessors,
-		KeyColumns:   keyColumns,
 		KeyAccessors: keyAccessors,
+		Commit:       commit,
+		OnError:      onError,
 	}
 
 	body, err := engine.GenerateNodeFunction("node_db_output_update.go.tmpl", templateData)
@@ -228,15 +430,9 @@ func (g *DBOutputGenerator) generateDeleteFuncData(node *models.Node, config *mo
 		return nil, fmt.Errorf("db_output node %q: KeyColumns is empty - cannot generate DELETE without key columns", node.Name)
 	}
 
-	batchSize := config.BatchSize
-	if batchSize <= 0 {
-		batchSize = 500
-	}
-
-	tableName := config.Table
-	if config.DbSchema != "" {
-		tableName = fmt.Sprintf("%s.%s", config.DbSchema, config.Table)
-	}
+	ctx.AddImport("strings")
+	d := dialectFor(config.Connection.Type)
+	tableName := quoteTable(d, config.DbSchema, config.Table)
 
 	var keyColumns, keyAccessors []string
 	keySet := make(map[string]bool, len(config.KeyColumns))
@@ -245,11 +441,21 @@ func (g *DBOutputGenerator) generateDeleteFuncData(node *models.Node, config *mo
 	}
 	for _, col := range config.DataModels {
 		if keySet[col.Name] {
-			keyColumns = append(keyColumns, col.Name)
+			keyColumns = append(keyColumns, d.QuoteIdent(col.Name))
 			keyAccessors = append(keyAccessors, col.GoFieldName())
 		}
 	}
 
+	onError, err := errorPolicyTemplateData(node, ctx, inputRowType)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := commitTemplateData(node, config, nil, onError)
+	if err != nil {
+		return nil, err
+	}
+
 	engine, err := NewTemplateEngine()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create template engine: %w", err)
@@ -260,10 +466,23 @@ func (g *DBOutputGenerator) generateDeleteFuncData(node *models.Node, config *mo
 		NodeID:       node.ID,
 		NodeName:     node.Name,
 		InputType:    inputRowType,
-		TableName:    tableName,
-		BatchSize:    batchSize,
+		Placeholder:  d.PlaceholderExpr("len(args)+j+1"),
+		BatchSize:    batchSizeFor(d, defaultBatchSize(config.BatchSize), len(keyColumns)),
 		KeyColumns:   keyColumns,
 		KeyAccessors: keyAccessors,
+		Commit:       commit,
+		OnError:      onError,
+	}
+
+	// Composite keys need row values, otherwise each row is matched by its own condition
+	if len(keyColumns) == 1 || d.RowValueIn() {
+		templateData.RowValueIn = true
+		templateData.QueryPrefix = fmt.Sprintf("DELETE FROM %s WHERE (%s) IN (", tableName, strings.Join(keyColumns, ", "))
+		templateData.QuerySuffix = ")"
+		templateData.RowSeparator = ", "
+	} else {
+		templateData.QueryPrefix = fmt.Sprintf("DELETE FROM %s WHERE ", tableName)
+		templateData.RowSeparator = " OR "
 	}
 
 	body, err := engine.GenerateNodeFunction("node_db_output_delete.go.tmpl", templateData)
@@ -279,7 +498,7 @@ func (g *DBOutputGenerator) generateDeleteFuncData(node *models.Node, config *mo
 	}, nil
 }
 
-// generateMergeFuncData generates a PostgreSQL UPSERT function using template
+// generateMergeFuncData generates an UPSERT function in the dialect of the connection using template
 func (g *DBOutputGenerator) generateMergeFuncData(node *models.Node, config *models.DBOutputConfig, ctx *GeneratorContext, funcName, inputRowType string) (*NodeFunctionData, error) {
 	if len(config.DataModels) == 0 {
 		return nil, fmt.Errorf("db_output node %q: DataModels is empty - cannot generate MERGE without columns", node.Name)
@@ -288,15 +507,8 @@ func (g *DBOutputGenerator) generateMergeFuncData(node *models.Node, config *mod
 		return nil, fmt.Errorf("db_output node %q: KeyColumns is empty - cannot generate MERGE without key columns", node.Name)
 	}
 
-	batchSize := config.BatchSize
-	if batchSize <= 0 {
-		batchSize = 500
-	}
-
-	tableName := config.Table
-	if config.DbSchema != "" {
-		tableName = fmt.Sprintf("%s.%s", config.DbSchema, config.Table)
-	}
+	ctx.AddImport("strings")
+	d := dialectFor(config.Connection.Type)
 
 	keySet := make(map[string]bool, len(config.KeyColumns))
 	for _, k := range config.KeyColumns {
@@ -305,35 +517,56 @@ func (g *DBOutputGenerator) generateMergeFuncData(node *models.Node, config *mod
 
 	columns := make([]string, len(config.DataModels))
 	fieldAccessors := make([]string, len(config.DataModels))
-	var keyColumns, updateColumns []string
+	var keyColumns []string
 
 	for i, col := range config.DataModels {
 		columns[i] = col.Name
 		fieldAccessors[i] = col.GoFieldName()
 		if keySet[col.Name] {
 			keyColumns = append(keyColumns, col.Name)
-		} else {
-			updateColumns = append(updateColumns, col.Name)
 		}
 	}
+	if len(keyColumns) == 0 {
+		return nil, fmt.Errorf("db_output node %q: none of the KeyColumns is in DataModels", node.Name)
+	}
+
+	checkpoint, err := g.checkpointTemplateData(node, config, ctx, "merge")
+	if err != nil {
+		return nil, err
+	}
+
+	onError, err := errorPolicyTemplateData(node, ctx, inputRowType)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := commitTemplateData(node, config, checkpoint, onError)
+	if err != nil {
+		return nil, err
+	}
 
 	engine, err := NewTemplateEngine()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create template engine: %w", err)
 	}
 
+	queryPrefix, querySuffix := d.UpsertQuery(quoteTable(d, config.DbSchema, config.Table), columns, keyColumns)
+
 	templateData := DBOutputMergeTemplateData{
 		FuncName:       funcName,
 		NodeID:         node.ID,
 		NodeName:       node.Name,
 		InputType:      inputRowType,
-		TableName:      tableName,
-		ColumnNames:    strings.Join(columns, ", "),
+		QueryPrefix:    queryPrefix,
+		QuerySuffix:    querySuffix,
+		Placeholder:    d.PlaceholderExpr("len(args)+j+1"),
 		NumColumns:     len(config.DataModels),
-		BatchSize:      batchSize,
+		BatchSize:      batchSizeFor(d, defaultBatchSize(config.BatchSize), len(columns)),
 		FieldAccessors: fieldAccessors,
-		KeyColumns:     keyColumns,
-		UpdateColumns:  updateColumns,
+		Table:          tableTemplateData(config),
+		Checkpoint:     checkpoint,
+		Commit:         commit,
+		OnError:        onError,
 	}
 
 	body, err := engine.GenerateNodeFunction("node_db_output_merge.go.tmpl", templateData)
@@ -349,11 +582,60 @@ func (g *DBOutputGenerator) generateMergeFuncData(node *models.Node, config *mod
 	}, nil
 }
 
-// generateTruncateFuncData generates a TRUNCATE function using template
-func (g *DBOutputGenerator) generateTruncateFuncData(node *models.Node, config *models.DBOutputConfig, ctx *GeneratorContext, funcName string) (*NodeFunctionData, error) {
-	tableName := config.Table
-	if config.DbSchema != "" {
-		tableName = fmt.Sprintf("%s.%s", config.DbSchema, config.Table)
+// generateBulkFuncData generates a function streaming rows through the native bulk path of the database
+func (g *DBOutputGenerator) generateBulkFuncData(node *models.Node, config *models.DBOutputConfig, ctx *GeneratorContext, funcName, inputRowType string) (*NodeFunctionData, error) {
+	if len(config.DataModels) == 0 {
+		return nil, fmt.Errorf("db_output node %q: DataModels is empty - cannot generate BULK without columns", node.Name)
+	}
+	onError, err := errorPolicyTemplateData(node, ctx, inputRowType)
+	if err != nil {
+		return nil, err
+	}
+	if onError != nil {
+		return nil, fmt.Errorf("db_output node %q: bulk mode loads all rows at once and only supports the fail error policy", node.Name)
+	}
+	if err := rejectCommitSettings(node, config); err != nil {
+		return nil, err
+	}
+
+	d := dialectFor(config.Connection.Type)
+	tableName := quoteTable(d, config.DbSchema, config.Table)
+
+	columns := make([]string, len(config.DataModels))
+	fieldAccessors := make([]string, len(config.DataModels))
+	for i, col := range config.DataModels {
+		columns[i] = col.Name
+		fieldAccessors[i] = col.GoFieldName()
+	}
+
+	templateData := DBOutputBulkTemplateData{
+		FuncName:       funcName,
+		NodeID:         node.ID,
+		NodeName:       node.Name,
+		InputType:      inputRowType,
+		Dialect:        d.Name(),
+		FieldAccessors: fieldAccessors,
+		Table:          tableTemplateData(config),
+	}
+
+	switch d.(type) {
+	case sqliteDialect, duckDBDialect:
+		return nil, fmt.Errorf("db_output node %q: bulk mode is not available on %s, use insert with the single commit strategy", node.Name, d.Name())
+	case sqlServerDialect:
+		ctx.AddImportAlias("mssql", config.Connection.GetImportPath())
+		templateData.TableName = tableName
+		templateData.Columns = columns // the driver matches columns by their unquoted names
+	case mysqlDialect:
+		ctx.AddImport("bufio")
+		ctx.AddImport("io")
+		ctx.AddImportAlias("mysql", config.Connection.GetImportPath())
+		templateData.ReaderName = fmt.Sprintf("node_%d", node.ID)
+		templateData.Query = fmt.Sprintf(
+			"LOAD DATA LOCAL INFILE 'Reader::%s' INTO TABLE %s CHARACTER SET utf8mb4 "+
+				"FIELDS TERMINATED BY '\\t' ESCAPED BY '\\\\' LINES TERMINATED BY '\\n' (%s)",
+			templateData.ReaderName, tableName, strings.Join(quoteColumns(d, columns), ", "))
+	default:
+		templateData.Query = fmt.Sprintf("COPY %s (%s) FROM STDIN", tableName, strings.Join(quoteColumns(d, columns), ", "))
 	}
 
 	engine, err := NewTemplateEngine()
@@ -361,11 +643,162 @@ func (g *DBOutputGenerator) generateTruncateFuncData(node *models.Node, config *
 		return nil, fmt.Errorf("failed to create template engine: %w", err)
 	}
 
+	body, err := engine.GenerateNodeFunction("node_db_output_bulk.go.tmpl", templateData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate db_output bulk function: %w", err)
+	}
+
+	return &NodeFunctionData{
+		Name:     funcName,
+		NodeID:   node.ID,
+		NodeName: node.Name,
+		Body:     body,
+	}, nil
+}
+
+// generateSCD2FuncData generates a Slowly Changing Dimension type 2 function using template
+func (g *DBOutputGenerator) generateSCD2FuncData(node *models.Node, config *models.DBOutputConfig, ctx *GeneratorContext, funcName, inputRowType string) (*NodeFunctionData, error) {
+	if len(config.KeyColumns) == 0 {
+		return nil, fmt.Errorf("db_output node %q: KeyColumns is empty - cannot generate SCD2 without key columns", node.Name)
+	}
+	onError, err := errorPolicyTemplateData(node, ctx, inputRowType)
+	if err != nil {
+		return nil, err
+	}
+	if onError != nil {
+		return nil, fmt.Errorf("db_output node %q: scd2 mode only supports the fail error policy", node.Name)
+	}
+	if err := rejectCommitSettings(node, config); err != nil {
+		return nil, err
+	}
+
+	scd := models.SCD2Config{}
+	if config.SCD2 != nil {
+		scd = *config.SCD2
+	}
+	scd = scd.WithDefaults()
+	versionColumns := map[string]bool{scd.ValidFromColumn: true, scd.ValidToColumn: true, scd.IsCurrentColumn: true}
+
+	keySet := make(map[string]bool, len(config.KeyColumns))
+	for _, k := range config.KeyColumns {
+		keySet[k] = true
+	}
+	trackedSet := make(map[string]bool, len(scd.TrackedColumns))
+	for _, c := range scd.TrackedColumns {
+		trackedSet[c] = true
+	}
+
+	// version columns are written by the generated code, not read from the input row
+	var columns, fieldAccessors, keyColumns, keyAccessors, trackedColumns, trackedAccessors []string
+	for _, col := range config.DataModels {
+		if versionColumns[col.Name] {
+			continue
+		}
+		columns = append(columns, col.Name)
+		fieldAccessors = append(fieldAccessors, "row."+col.GoFieldName())
+		switch {
+		case keySet[col.Name]:
+			keyColumns = append(keyColumns, col.Name)
+			keyAccessors = append(keyAccessors, "row."+col.GoFieldName())
+		case len(trackedSet) == 0 || trackedSet[col.Name]:
+			trackedColumns = append(trackedColumns, col.Name)
+			trackedAccessors = append(trackedAccessors, "row."+col.GoFieldName())
+		}
+	}
+	if len(keyColumns) != len(config.KeyColumns) {
+		return nil, fmt.Errorf("db_output node %q: every key column must be in DataModels", node.Name)
+	}
+	if len(trackedColumns) == 0 || (len(trackedSet) > 0 && len(trackedColumns) != len(trackedSet)) {
+		return nil, fmt.Errorf("db_output node %q: tracked columns must be non-key columns of DataModels", node.Name)
+	}
+
+	d := dialectFor(config.Connection.Type)
+	tableName := quoteTable(d, config.DbSchema, config.Table)
+	isCurrent, validFrom, validTo := d.QuoteIdent(scd.IsCurrentColumn), d.QuoteIdent(scd.ValidFromColumn), d.QuoteIdent(scd.ValidToColumn)
+
+	// param returns the placeholder of the next statement parameter
+	n := 0
+	param := func() string {
+		n++
+		return d.Placeholder(n)
+	}
+	keyConditions := func() string {
+		conditions := make([]string, len(keyColumns))
+		for i, col := range keyColumns {
+			conditions[i] = fmt.Sprintf("%s = %s", d.QuoteIdent(col), param())
+		}
+		return strings.Join(conditions, " AND ")
+	}
+
+	n = 0
+	closeQuery := fmt.Sprintf("UPDATE %s SET %s = %s, %s = %s WHERE ", tableName, validTo, param(), isCurrent, param())
+	closeQuery += keyConditions() + fmt.Sprintf(" AND %s = %s", isCurrent, param())
+	unchanged := make([]string, len(trackedColumns))
+	for i, col := range trackedColumns {
+		unchanged[i] = d.NullSafeEqual(d.QuoteIdent(col), param())
+	}
+	closeQuery += fmt.Sprintf(" AND NOT (%s)", strings.Join(unchanged, " AND "))
+
+	n = 0
+	existsQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s AND %s = %s", tableName, keyConditions(), isCurrent, param())
+
+	n = 0
+	placeholders := make([]string, len(columns))
+	for i := range columns {
+		placeholders[i] = param()
+	}
+	insertQuery := fmt.Sprintf("INSERT INTO %s (%s, %s, %s, %s) VALUES (%s, %s, NULL, %s)",
+		tableName, strings.Join(quoteColumns(d, columns), ", "), validFrom, validTo, isCurrent,
+		strings.Join(placeholders, ", "), param(), param())
+
+	ctx.AddImport("time")
+
+	engine, err := NewTemplateEngine()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create template engine: %w", err)
+	}
+
+	templateData := DBOutputSCD2TemplateData{
+		FuncName:    funcName,
+		NodeID:      node.ID,
+		NodeName:    node.Name,
+		InputType:   inputRowType,
+		BatchSize:   defaultBatchSize(config.BatchSize),
+		KeyCount:    len(keyAccessors),
+		KeyArgs:     strings.Join(keyAccessors, ", "),
+		CloseQuery:  closeQuery,
+		CloseArgs:   strings.Join(slices.Concat([]string{"now", "false"}, keyAccessors, []string{"true"}, trackedAccessors), ", "),
+		ExistsQuery: existsQuery,
+		ExistsArgs:  strings.Join(slices.Concat(keyAccessors, []string{"true"}), ", "),
+		InsertQuery: insertQuery,
+		InsertArgs:  strings.Join(slices.Concat(fieldAccessors, []string{"now", "true"}), ", "),
+	}
+
+	body, err := engine.GenerateNodeFunction("node_db_output_scd2.go.tmpl", templateData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate db_output scd2 function: %w", err)
+	}
+
+	return &NodeFunctionData{
+		Name:     funcName,
+		NodeID:   node.ID,
+		NodeName: node.Name,
+		Body:     body,
+	}, nil
+}
+
+// generateTruncateFuncData generates a TRUNCATE function using template
+func (g *DBOutputGenerator) generateTruncateFuncData(node *models.Node, config *models.DBOutputConfig, ctx *GeneratorContext, funcName string) (*NodeFunctionData, error) {
+	engine, err := NewTemplateEngine()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create template engine: %w", err)
+	}
+
 	templateData := DBOutputTruncateTemplateData{
 		FuncName:  funcName,
 		NodeID:    node.ID,
 		NodeName:  node.Name,
-		TableName: tableName,
+		TableName: quoteTable(dialectFor(config.Connection.Type), config.DbSchema, config.Table),
 	}
 
 	body, err := engine.GenerateNodeFunction("node_db_output_truncate.go.tmpl", templateData)
@@ -380,3 +813,11 @@ func (g *DBOutputGenerator) generateTruncateFuncData(node *models.Node, config *
 		Body:     body,
 	}, nil
 }
+
+// defaultBatchSize returns the configured batch size, 500 when unset
+func defaultBatchSize(batchSize int) int {
+	if batchSize <= 0 {
+		return 500
+	}
+	return batchSize
+}
@@ -38,6 +38,11 @@ func (g *EmailOutputGenerator) GenerateFuncData(node *models.Node, ctx *Generato
 		inputRowType = "any"
 	}
 
+	onError, err := errorPolicyTemplateData(node, ctx, inputRowType)
+	if err != nil {
+		return nil, err
+	}
+
 	engine, err := NewTemplateEngine()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create template engine: %w", err)
@@ -52,7 +57,7 @@ func (g *EmailOutputGenerator) GenerateFuncData(node *models.Node, ctx *Generato
 		SmtpHost:        config.SmtpHost,
 		SmtpPort:        config.SmtpPort,
 		Username:        config.Username,
-		Password:        config.Password,
+		PasswordSecret:  ctx.AddSecret(fmt.Sprintf("DOS_SMTP_PASSWORD_NODE_%d", node.ID), config.Password),
 		UseTLS:          config.UseTLS,
 		To:              strings.Join(config.To, ", "),
 		CC:              strings.Join(config.CC, ", "),
@@ -60,6 +65,7 @@ func (g *EmailOutputGenerator) GenerateFuncData(node *models.Node, ctx *Generato
 		Subject:         config.Subject,
 		Body:            config.Body,
 		IsHTML:          config.IsHTML,
+		OnError:         onError,
 	}
 
 	body, err := engine.GenerateNodeFunction("node_email_output.go.tmpl", templateData)
@@ -98,7 +104,7 @@ func (g *EmailOutputGenerator) findInputRowType(node *models.Node, ctx *Generato
 			if sourceNodeID == 0 {
 				continue
 			}
-			if structName, exists := ctx.NodeStructNames[sourceNodeID]; exists {
+			if structName, exists := ctx.InputStructName(node.ID, sourceNodeID); exists {
 				return structName
 			}
 		}
@@ -82,7 +82,7 @@ func (g *LogGenerator) findInputRowType(node *models.Node, ctx *GeneratorContext
 			if sourceNodeID == 0 {
 				continue
 			}
-			if structName, exists := ctx.NodeStructNames[sourceNodeID]; exists {
+			if structName, exists := ctx.InputStructName(node.ID, sourceNodeID); exists {
 				return structName
 			}
 		}
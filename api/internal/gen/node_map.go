@@ -68,6 +68,10 @@ func (g *MapGenerator) GenerateFuncData(node *models.Node, ctx *GeneratorContext
 	// Determine input row types from connected nodes
 	inputTypes := g.findInputRowTypes(node, &config, ctx)
 
+	if config.IsParallel() && len(config.Inputs) != 1 {
+		return nil, fmt.Errorf("map node %q: parallelism is only supported on single-input maps", node.Name)
+	}
+
 	if len(config.Inputs) == 1 {
 		// Single input - simple transform
 		return g.generateSingleInputFuncData(node, &config, ctx, funcName, outputStructName, inputTypes)
@@ -164,6 +168,29 @@ func (g *MapGenerator) generateSingleInputFuncData(node *models.Node, config *mo
 		Transforms: transforms,
 	}
 
+	if config.IsParallel() {
+		ctx.AddImport("sync/atomic")
+
+		templateData.Workers = config.Parallelism
+		templateData.ParallelMode = string(config.ParallelMode)
+		if templateData.ParallelMode == "" {
+			templateData.ParallelMode = string(models.ParallelModeOrdered)
+		}
+
+		switch models.ParallelMode(templateData.ParallelMode) {
+		case models.ParallelModeOrdered, models.ParallelModeUnordered:
+		case models.ParallelModePartitioned:
+			if len(config.PartitionKeys) == 0 {
+				return nil, fmt.Errorf("map node %q: partitioned mode requires partition keys", node.Name)
+			}
+			for _, key := range config.PartitionKeys {
+				templateData.PartitionKeys = append(templateData.PartitionKeys, toPascalCase(extractFieldName(key)))
+			}
+		default:
+			return nil, fmt.Errorf("map node %q: unknown parallel mode %q", node.Name, config.ParallelMode)
+		}
+	}
+
 	// Generate body using template
 	body, err := engine.GenerateNodeFunction("node_map_transform.go.tmpl", templateData)
 	if err != nil {
@@ -561,7 +588,7 @@ func (g *MapGenerator) findInputRowTypes(node *models.Node, config *models.MapCo
 		if sourceNodeID == 0 {
 			continue
 		}
-		if structName, exists := ctx.NodeStructNames[sourceNodeID]; exists {
+		if structName, exists := ctx.InputStructName(node.ID, sourceNodeID); exists {
 			result[input.Name] = structName
 		}
 	}
@@ -3,6 +3,7 @@ package gen
 import (
 	"api/internal/api/models"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -101,3 +102,826 @@ func TestDBOutputGeneration(t *testing.T) {
 	fmt.Println(string(source))
 	fmt.Println("=== End Generated Code ===")
 }
+
+func TestDBOutputCheckpointGeneration(t *testing.T) {
+	conn := models.DBConnectionConfig{
+		Type:     models.DBTypePostgres,
+		Host:     "localhost",
+		Port:     5433,
+		Database: "test",
+		Username: "postgres",
+		Password: "postgres",
+		SSLMode:  "disable",
+	}
+
+	startNode := models.Node{
+		ID:    0,
+		Type:  models.NodeTypeStart,
+		Name:  "Start",
+		JobID: 1,
+	}
+
+	inputNode := models.Node{
+		ID:    1,
+		Type:  models.NodeTypeDBInput,
+		Name:  "Read Users",
+		JobID: 1,
+	}
+	inputNode.SetData(models.DBInputConfig{
+		Query:      "SELECT id, name FROM users ORDER BY id;",
+		DbSchema:   "public",
+		Connection: conn,
+		DataModels: []models.DataModel{
+			{Name: "id", Type: "integer", GoType: "int"},
+			{Name: "name", Type: "varchar", GoType: "string"},
+		},
+	})
+
+	outputNode := models.Node{
+		ID:    2,
+		Type:  models.NodeTypeDBOutput,
+		Name:  "Write Users",
+		JobID: 1,
+	}
+	outputConfig := models.DBOutputConfig{
+		Table:      "users_copy",
+		Mode:       models.DbOutputModeInsert,
+		BatchSize:  100,
+		DbSchema:   "public",
+		Connection: conn,
+		DataModels: []models.DataModel{
+			{Name: "id", Type: "integer", GoType: "int"},
+			{Name: "name", Type: "varchar", GoType: "string"},
+		},
+		Checkpoint: &models.CheckpointConfig{KeyColumn: "id"},
+	}
+	outputNode.SetData(outputConfig)
+
+	startNode.OutputPort = []models.Port{
+		{ID: 1, Type: models.PortNodeFlowOutput, Node: inputNode, NodeID: 0, ConnectedNodeID: 1},
+	}
+	inputNode.InputPort = []models.Port{
+		{ID: 2, Type: models.PortNodeFlowInput, Node: startNode, NodeID: 1, ConnectedNodeID: 0},
+	}
+	inputNode.OutputPort = []models.Port{
+		{ID: 3, Type: models.PortNodeFlowOutput, Node: outputNode, NodeID: 1, ConnectedNodeID: 2},
+		{ID: 4, Type: models.PortTypeOutput, Node: outputNode, NodeID: 1, ConnectedNodeID: 2},
+	}
+	outputNode.InputPort = []models.Port{
+		{ID: 5, Type: models.PortNodeFlowInput, Node: inputNode, NodeID: 2, ConnectedNodeID: 1},
+		{ID: 6, Type: models.PortTypeInput, Node: inputNode, NodeID: 2, ConnectedNodeID: 1},
+	}
+
+	job := models.Job{
+		ID:    1,
+		Name:  "Checkpoint Job",
+		Nodes: []models.Node{startNode, inputNode, outputNode},
+	}
+
+	exec := NewJobExecution(&job)
+	exec.Resume = true
+	if _, err := exec.build(); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+
+	source, err := exec.generateSource()
+	if err != nil {
+		t.Fatalf("generateSource failed: %v", err)
+	}
+
+	fmt.Println("=== CHECKPOINT GENERATED CODE ===")
+	fmt.Println(string(source))
+	fmt.Println("=== END ===")
+
+	for _, want := range []string{
+		`lib.LoadCheckpoint(ctx, db_`,
+		`lib.SaveCheckpoint(ctx, tx, "postgres", cp)`,
+		`LastKey: lib.CheckpointKey(batch[len(batch)-1].Id)`,
+		`tx.Commit()`,
+		`lib.ClearCheckpoint(ctx, db, "postgres", 1, 2)`,
+		`SELECT * FROM (SELECT id, name FROM users ORDER BY id) AS src WHERE id > `,
+	} {
+		if !strings.Contains(string(source), want) {
+			t.Errorf("generated code is missing %q", want)
+		}
+	}
+
+	// With an error policy, the rows of a failed batch are retried behind savepoints in the
+	// transaction saving the checkpoint, and only the written ones are counted
+	outputConfig.ErrorPolicy = &models.ErrorPolicy{Mode: models.ErrorPolicySkip}
+	outputNode.SetData(outputConfig)
+	job.Nodes[2] = outputNode
+	exec = NewJobExecution(&job)
+	if _, err := exec.build(); err != nil {
+		t.Fatalf("build with error policy failed: %v", err)
+	}
+	if source, err = exec.generateSource(); err != nil {
+		t.Fatalf("generateSource with error policy failed: %v", err)
+	}
+	code := string(source)
+	for _, want := range []string{
+		`tx.ExecContext(ctx, "SAVEPOINT dos_row")`,
+		`tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT dos_row")`,
+		`written--`,
+		`totalRows += written`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code with error policy is missing %q", want)
+		}
+	}
+	if strings.Contains(code, `db.ExecContext(ctx, rowQuery, rowArgs...)`) {
+		t.Error("rows of a checkpointed batch must not be written outside the checkpoint transaction")
+	}
+	if i, j := strings.Index(code, `"ROLLBACK TO SAVEPOINT dos_row"`), strings.Index(code, `lib.SaveCheckpoint(ctx, tx,`); i < 0 || j < i {
+		t.Error("the checkpoint must be saved after the isolated rows, in their transaction")
+	}
+
+	// DuckDB has no savepoints to isolate rows in the checkpoint transaction
+	outputConfig.Connection.Type = models.DBTypeDuckDB
+	outputNode.SetData(outputConfig)
+	job.Nodes[2] = outputNode
+	if _, err := NewJobExecution(&job).build(); err == nil || !strings.Contains(err.Error(), "checkpoints on") {
+		t.Errorf("expected build to refuse an error policy with checkpoints on DuckDB, got %v", err)
+	}
+}
+
+func TestDBOutputCheckpointResumeSources(t *testing.T) {
+	conn := models.DBConnectionConfig{
+		Type:     models.DBTypePostgres,
+		Host:     "localhost",
+		Port:     5433,
+		Database: "test",
+		Username: "postgres",
+		Password: "postgres",
+		SSLMode:  "disable",
+	}
+
+	// buildJob wires start -> db_input (1) -> map (2) -> db_output (3), and db_input -> db_output (4)
+	// when secondOutput is set
+	buildJob := func(query string, keyColumn string, keyCol models.MapOutputCol, secondOutput bool) *models.Job {
+		startNode := models.Node{ID: 0, Type: models.NodeTypeStart, Name: "Start", JobID: 1}
+		inputNode := models.Node{ID: 1, Type: models.NodeTypeDBInput, Name: "Read Orders", JobID: 1}
+		inputNode.SetData(models.DBInputConfig{
+			Query:      query,
+			DbSchema:   "public",
+			Connection: conn,
+			DataModels: []models.DataModel{
+				{Name: "id", Type: "integer", GoType: "int"},
+				{Name: "amount", Type: "numeric", GoType: "float64"},
+			},
+		})
+		mapNode := models.Node{ID: 2, Type: models.NodeTypeMap, Name: "Rename", JobID: 1}
+		mapNode.SetData(models.MapConfig{
+			Inputs: []models.InputFlow{{Name: "A", PortID: 1, Schema: []models.DataModel{
+				{Name: "id", Type: "integer", GoType: "int"},
+				{Name: "amount", Type: "numeric", GoType: "float64"},
+			}}},
+			Outputs: []models.OutputFlow{{Name: "main", PortID: 7, Columns: []models.MapOutputCol{
+				keyCol,
+				{Name: "amount", DataType: "float64", FuncType: models.FuncTypeDirect, InputRef: "A.amount"},
+			}}},
+		})
+		output := func(id int, name string, dataModels []models.DataModel, key string) models.Node {
+			node := models.Node{ID: id, Type: models.NodeTypeDBOutput, Name: name, JobID: 1}
+			node.SetData(models.DBOutputConfig{
+				Table:      name,
+				Mode:       models.DbOutputModeInsert,
+				BatchSize:  100,
+				DbSchema:   "public",
+				Connection: conn,
+				DataModels: dataModels,
+				Checkpoint: &models.CheckpointConfig{KeyColumn: key},
+			})
+			return node
+		}
+		outputNode := output(3, "orders_copy", []models.DataModel{
+			{Name: keyCol.Name, Type: "integer", GoType: "int"},
+			{Name: "amount", Type: "numeric", GoType: "float64"},
+		}, keyColumn)
+		secondNode := output(4, "orders_raw", []models.DataModel{
+			{Name: "id", Type: "integer", GoType: "int"},
+			{Name: "amount", Type: "numeric", GoType: "float64"},
+		}, "id")
+
+		startNode.OutputPort = []models.Port{{ID: 1, Type: models.PortNodeFlowOutput, NodeID: 0, ConnectedNodeID: 1}}
+		inputNode.InputPort = []models.Port{{ID: 2, Type: models.PortNodeFlowInput, NodeID: 1, ConnectedNodeID: 0}}
+		inputNode.OutputPort = []models.Port{
+			{ID: 3, Type: models.PortNodeFlowOutput, NodeID: 1, ConnectedNodeID: 2},
+			{ID: 4, Type: models.PortTypeOutput, NodeID: 1, ConnectedNodeID: 2},
+		}
+		mapNode.InputPort = []models.Port{
+			{ID: 5, Type: models.PortNodeFlowInput, NodeID: 2, ConnectedNodeID: 1},
+			{ID: 6, Type: models.PortTypeInput, NodeID: 2, ConnectedNodeID: 1},
+		}
+		mapNode.OutputPort = []models.Port{
+			{ID: 7, Type: models.PortTypeOutput, NodeID: 2, ConnectedNodeID: 3},
+			{ID: 8, Type: models.PortNodeFlowOutput, NodeID: 2, ConnectedNodeID: 3},
+		}
+		outputNode.InputPort = []models.Port{
+			{ID: 9, Type: models.PortNodeFlowInput, NodeID: 3, ConnectedNodeID: 2},
+			{ID: 10, Type: models.PortTypeInput, NodeID: 3, ConnectedNodeID: 2},
+		}
+		nodes := []models.Node{startNode, inputNode, mapNode, outputNode}
+		if secondOutput {
+			inputNode.OutputPort = append(inputNode.OutputPort,
+				models.Port{ID: 11, Type: models.PortNodeFlowOutput, NodeID: 1, ConnectedNodeID: 4},
+				models.Port{ID: 12, Type: models.PortTypeOutput, NodeID: 1, ConnectedNodeID: 4},
+			)
+			nodes[1] = inputNode
+			secondNode.InputPort = []models.Port{
+				{ID: 13, Type: models.PortNodeFlowInput, NodeID: 4, ConnectedNodeID: 1},
+				{ID: 14, Type: models.PortTypeInput, NodeID: 4, ConnectedNodeID: 1},
+			}
+			nodes = append(nodes, secondNode)
+		}
+		return &models.Job{ID: 1, Name: "Checkpoint Sources Job", Nodes: nodes}
+	}
+
+	renamed := models.MapOutputCol{Name: "order_id", DataType: "int", FuncType: models.FuncTypeDirect, InputRef: "A.id"}
+	computed := models.MapOutputCol{Name: "order_id", DataType: "int", FuncType: models.FuncTypeCustom, CustomType: models.CustomExpr, Expression: "A.id + 1"}
+
+	tests := []struct {
+		name     string
+		job      *models.Job
+		wantCode string
+		wantErr  string
+	}{
+		{
+			name:     "key renamed by a map",
+			job:      buildJob("SELECT id, amount FROM orders ORDER BY id", "order_id", renamed, false),
+			wantCode: `SELECT * FROM (SELECT id, amount FROM orders ORDER BY id) AS src WHERE id > `,
+		},
+		{
+			name:    "key computed by a map",
+			job:     buildJob("SELECT id, amount FROM orders ORDER BY id", "order_id", computed, false),
+			wantErr: `checkpoint key column "order_id" is computed by map node "Rename"`,
+		},
+		{
+			name:    "input feeding two checkpointed outputs",
+			job:     buildJob("SELECT id, amount FROM orders ORDER BY id", "order_id", renamed, true),
+			wantErr: `db_input node "Read Orders" feeds the checkpointed db_output nodes`,
+		},
+		{
+			name:     "offset after an ORDER BY",
+			job:      buildJob("SELECT id, amount FROM orders ORDER BY id;", "", renamed, false),
+			wantCode: `SET search_path TO public; SELECT id, amount FROM orders ORDER BY id OFFSET `,
+		},
+		{
+			name:    "offset without an ORDER BY",
+			job:     buildJob("SELECT id, amount FROM (SELECT * FROM orders ORDER BY id) o", "", renamed, false),
+			wantErr: "resuming by row offset needs a query ending with an ORDER BY",
+		},
+		{
+			name:    "offset after a LIMIT",
+			job:     buildJob("SELECT id, amount FROM orders ORDER BY id LIMIT 10", "", renamed, false),
+			wantErr: "cannot add an offset to a query with its own LIMIT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec := NewJobExecution(tt.job)
+			exec.Resume = true
+			_, err := exec.build()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected build error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("build failed: %v", err)
+			}
+			source, err := exec.generateSource()
+			if err != nil {
+				t.Fatalf("generateSource failed: %v", err)
+			}
+			if !strings.Contains(string(source), tt.wantCode) {
+				t.Errorf("generated code is missing %q", tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestDBOutputRejectGeneration(t *testing.T) {
+	conn := models.DBConnectionConfig{
+		Type:     models.DBTypePostgres,
+		Host:     "localhost",
+		Port:     5433,
+		Database: "test",
+		Username: "postgres",
+		Password: "postgres",
+		SSLMode:  "disable",
+	}
+
+	startNode := models.Node{
+		ID:    0,
+		Type:  models.NodeTypeStart,
+		Name:  "Start",
+		JobID: 1,
+	}
+
+	inputNode := models.Node{
+		ID:    1,
+		Type:  models.NodeTypeDBInput,
+		Name:  "Read Users",
+		JobID: 1,
+	}
+	inputNode.SetData(models.DBInputConfig{
+		Query:      "SELECT id, name FROM users;",
+		DbSchema:   "public",
+		Connection: conn,
+		DataModels: []models.DataModel{
+			{Name: "id", Type: "integer", GoType: "int"},
+			{Name: "name", Type: "varchar", GoType: "string"},
+		},
+		ErrorPolicy: &models.ErrorPolicy{Mode: models.ErrorPolicySkip},
+	})
+
+	outputNode := models.Node{
+		ID:    2,
+		Type:  models.NodeTypeDBOutput,
+		Name:  "Write Users",
+		JobID: 1,
+	}
+	outputNode.SetData(models.DBOutputConfig{
+		Table:      "users_copy",
+		Mode:       models.DbOutputModeInsert,
+		BatchSize:  100,
+		DbSchema:   "public",
+		Connection: conn,
+		DataModels: []models.DataModel{
+			{Name: "id", Type: "integer", GoType: "int"},
+			{Name: "name", Type: "varchar", GoType: "string"},
+		},
+		ErrorPolicy: &models.ErrorPolicy{Mode: models.ErrorPolicyReject},
+	})
+
+	quarantineNode := models.Node{
+		ID:    3,
+		Type:  models.NodeTypeDBOutput,
+		Name:  "Quarantine",
+		JobID: 1,
+	}
+	quarantineNode.SetData(models.DBOutputConfig{
+		Table:      "users_rejected",
+		Mode:       models.DbOutputModeInsert,
+		BatchSize:  100,
+		DbSchema:   "public",
+		Connection: conn,
+		DataModels: []models.DataModel{
+			{Name: "id", Type: "integer", GoType: "int"},
+			{Name: "name", Type: "varchar", GoType: "string"},
+			{Name: "reject_node_id", Type: "integer", GoType: "int"},
+			{Name: "reject_error", Type: "text", GoType: "string"},
+		},
+	})
+
+	startNode.OutputPort = []models.Port{
+		{ID: 1, Type: models.PortNodeFlowOutput, Node: inputNode, NodeID: 0, ConnectedNodeID: 1},
+	}
+	inputNode.InputPort = []models.Port{
+		{ID: 2, Type: models.PortNodeFlowInput, Node: startNode, NodeID: 1, ConnectedNodeID: 0},
+	}
+	inputNode.OutputPort = []models.Port{
+		{ID: 3, Type: models.PortNodeFlowOutput, Node: outputNode, NodeID: 1, ConnectedNodeID: 2},
+		{ID: 4, Type: models.PortTypeOutput, Node: outputNode, NodeID: 1, ConnectedNodeID: 2},
+	}
+	outputNode.InputPort = []models.Port{
+		{ID: 5, Type: models.PortNodeFlowInput, Node: inputNode, NodeID: 2, ConnectedNodeID: 1},
+		{ID: 6, Type: models.PortTypeInput, Node: inputNode, NodeID: 2, ConnectedNodeID: 1},
+	}
+	outputNode.OutputPort = []models.Port{
+		{ID: 7, Type: models.PortNodeFlowOutput, Node: quarantineNode, NodeID: 2, ConnectedNodeID: 3},
+		{ID: 8, Type: models.PortTypeReject, Node: quarantineNode, NodeID: 2, ConnectedNodeID: 3},
+	}
+	quarantineNode.InputPort = []models.Port{
+		{ID: 9, Type: models.PortNodeFlowInput, Node: outputNode, NodeID: 3, ConnectedNodeID: 2},
+		{ID: 10, Type: models.PortTypeInput, Node: outputNode, NodeID: 3, ConnectedNodeID: 2},
+	}
+
+	job := models.Job{
+		ID:    1,
+		Name:  "Reject Job",
+		Nodes: []models.Node{startNode, inputNode, outputNode, quarantineNode},
+	}
+
+	exec := NewJobExecution(&job)
+	if _, err := exec.build(); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+
+	source, err := exec.generateSource()
+	if err != nil {
+		t.Fatalf("generateSource failed: %v", err)
+	}
+
+	fmt.Println("=== REJECT GENERATED CODE ===")
+	fmt.Println(string(source))
+	fmt.Println("=== END ===")
+
+	for _, want := range []string{
+		`type Node2Reject struct`,
+		`in <-chan *Node1Row, reject chan<- *Node2Reject, progress lib.ProgressFunc`,
+		`reject <- &Node2Reject{Node1Row: *row, RejectNodeId: 2, RejectError: rowErr.Error()}`,
+		`in <-chan *Node2Reject, progress lib.ProgressFunc`,
+		`row.RejectNodeId, row.RejectError`,
+		`defer close(ch_8)`,
+		`onRowError(&row, fmt.Errorf("scan failed: %w", err))`,
+	} {
+		if !strings.Contains(string(source), want) {
+			t.Errorf("generated code is missing %q", want)
+		}
+	}
+
+	// A reject port without the reject policy is a configuration error
+	inputNode.OutputPort = append(inputNode.OutputPort,
+		models.Port{ID: 11, Type: models.PortTypeReject, Node: quarantineNode, NodeID: 1, ConnectedNodeID: 3})
+	job.Nodes[1] = inputNode
+	if _, err := NewJobExecution(&job).build(); err == nil {
+		t.Error("expected build to fail for a reject port on a node in skip mode")
+	}
+}
+
+func TestDBOutputDialectGeneration(t *testing.T) {
+	columns := []models.DataModel{
+		{Name: "id", Type: "integer", GoType: "int"},
+		{Name: "tenant", Type: "integer", GoType: "int"},
+		{Name: "name", Type: "varchar", GoType: "string"},
+	}
+
+	tests := []struct {
+		name      string
+		dbType    models.DBType
+		mode      models.DbOutputMode
+		batchSize int
+		configure func(*models.DBOutputConfig)
+		want      []string
+		wantErr   string // expected build error, want is ignored when set
+	}{
+		{
+			name:      "sqlserver_merge",
+			dbType:    models.DBTypeSQLServer,
+			mode:      models.DbOutputModeMerge,
+			batchSize: 5000,
+			want: []string{
+				`make([]*Node1Row, 0, 700)`,
+				`fmt.Sprintf("@p%d", len(args)+j+1)`,
+				`"MERGE INTO [dbo].[users] AS target USING (VALUES "`,
+				`") AS source ([id], [tenant], [name]) ON target.[id] = source.[id] AND target.[tenant] = source.[tenant] WHEN MATCHED THEN UPDATE SET target.[name] = source.[name] WHEN NOT MATCHED THEN INSERT ([id], [tenant], [name]) VALUES (source.[id], source.[tenant], source.[name]);"`,
+			},
+		},
+		{
+			name:      "sqlserver_update",
+			dbType:    models.DBTypeSQLServer,
+			mode:      models.DbOutputModeUpdate,
+			batchSize: 100,
+			want: []string{
+				`"UPDATE [dbo].[users] SET [name] = @p1 WHERE [id] = @p2 AND [tenant] = @p3", []any{row.Name, row.Id, row.Tenant}`,
+			},
+		},
+		{
+			name:      "postgres_update_all_keys",
+			dbType:    models.DBTypePostgres,
+			mode:      models.DbOutputModeUpdate,
+			batchSize: 100,
+			configure: func(c *models.DBOutputConfig) {
+				c.KeyColumns = []string{"id", "tenant", "name"}
+			},
+			wantErr: "every column is a key column",
+		},
+		{
+			name:      "sqlserver_delete",
+			dbType:    models.DBTypeSQLServer,
+			mode:      models.DbOutputModeDelete,
+			batchSize: 5000,
+			want: []string{
+				`make([]*Node1Row, 0, 1000)`,
+				`keyColumns := []string{"[id]", "[tenant]"}`,
+				`"DELETE FROM [dbo].[users] WHERE " + strings.Join(conditions, " OR ")`,
+			},
+		},
+		{
+			name:      "mysql_merge",
+			dbType:    models.DBTypeMySQL,
+			mode:      models.DbOutputModeMerge,
+			batchSize: 100,
+			want: []string{
+				`ph = append(ph, "?")`,
+				"\"INSERT INTO `dbo`.`users` (`id`, `tenant`, `name`) VALUES \"",
+				"\" ON DUPLICATE KEY UPDATE `name` = VALUES(`name`)\"",
+			},
+		},
+		{
+			name:      "mysql_delete",
+			dbType:    models.DBTypeMySQL,
+			mode:      models.DbOutputModeDelete,
+			batchSize: 100,
+			want: []string{
+				"\"DELETE FROM `dbo`.`users` WHERE (`id`, `tenant`) IN (\" + strings.Join(conditions, \", \") + \")\"",
+			},
+		},
+		{
+			name:      "postgres_bulk",
+			dbType:    models.DBTypePostgres,
+			mode:      models.DbOutputModeBulk,
+			batchSize: 100,
+			want: []string{
+				`tx.PrepareContext(ctx, "COPY \"dbo\".\"users\" (\"id\", \"tenant\", \"name\") FROM STDIN")`,
+				`stmt.ExecContext(ctx, row.Id, row.Tenant, row.Name)`,
+			},
+		},
+		{
+			name:      "sqlserver_bulk",
+			dbType:    models.DBTypeSQLServer,
+			mode:      models.DbOutputModeBulk,
+			batchSize: 100,
+			want: []string{
+				`mssql "github.com/denisenkom/go-mssqldb"`,
+				`mssql.CopyIn("[dbo].[users]", mssql.BulkOptions{}, "id", "tenant", "name")`,
+			},
+		},
+		{
+			name:      "mysql_bulk",
+			dbType:    models.DBTypeMySQL,
+			mode:      models.DbOutputModeBulk,
+			batchSize: 100,
+			want: []string{
+				`mysql.RegisterReaderHandler("node_2", func() io.Reader { return pr })`,
+				"LOAD DATA LOCAL INFILE 'Reader::node_2' INTO TABLE `dbo`.`users`",
+				`lib.WriteTSVRow(w, row.Id, row.Tenant, row.Name)`,
+			},
+		},
+		{
+			name:      "mysql_insert_managed",
+			dbType:    models.DBTypeMySQL,
+			mode:      models.DbOutputModeInsert,
+			batchSize: 100,
+			configure: func(c *models.DBOutputConfig) {
+				// auto-create and evolve the table from the upstream columns
+				c.DataModels = nil
+				c.AutoCreateTable = true
+				c.EvolveSchema = true
+			},
+			want: []string{
+				"lib.EnsureTable(ctx, db, \"mysql\", \"dbo\", \"users\", \"CREATE TABLE `dbo`.`users` (\\n  `id` INT NOT NULL,",
+				"{Name: \"name\", DDL: \"ALTER TABLE `dbo`.`users` ADD COLUMN `name` LONGTEXT\"},",
+				"\"INSERT INTO `dbo`.`users` (`id`, `tenant`, `name`) VALUES \"",
+			},
+		},
+		{
+			name:      "mysql_insert_evolve_upstream",
+			dbType:    models.DBTypeMySQL,
+			mode:      models.DbOutputModeInsert,
+			batchSize: 100,
+			configure: func(c *models.DBOutputConfig) {
+				// the upstream name column is added to the configured ones
+				c.DataModels = columns[:2]
+				c.EvolveSchema = true
+			},
+			want: []string{
+				"{Name: \"name\", DDL: \"ALTER TABLE `dbo`.`users` ADD COLUMN `name` LONGTEXT\"},",
+				"\"INSERT INTO `dbo`.`users` (`id`, `tenant`, `name`) VALUES \"",
+			},
+		},
+		{
+			name:      "sqlserver_scd2",
+			dbType:    models.DBTypeSQLServer,
+			mode:      models.DbOutputModeSCD2,
+			batchSize: 100,
+			want: []string{
+				`"UPDATE [dbo].[users] SET [valid_to] = @p1, [is_current] = @p2 WHERE [id] = @p3 AND [tenant] = @p4 AND [is_current] = @p5 AND NOT (([name] = @p6 OR ([name] IS NULL AND @p6 IS NULL)))"`,
+				`closeStmt.ExecContext(ctx, now, false, row.Id, row.Tenant, true, row.Name)`,
+				`"SELECT COUNT(*) FROM [dbo].[users] WHERE [id] = @p1 AND [tenant] = @p2 AND [is_current] = @p3"`,
+				`"INSERT INTO [dbo].[users] ([id], [tenant], [name], [valid_from], [valid_to], [is_current]) VALUES (@p1, @p2, @p3, @p4, NULL, @p5)"`,
+				`insertStmt.ExecContext(ctx, row.Id, row.Tenant, row.Name, now, true)`,
+				// a key repeated in a batch is versioned once, with its last row
+				"batch, duplicates = lib.LastByKey(batch, func(row *Node1Row) [2]any {\n\t\t\treturn [2]any{row.Id, row.Tenant}\n\t\t})",
+				`unchanged: %d, duplicate keys: %d", insertedRows, closedRows, unchangedRows, duplicateRows)`,
+			},
+		},
+		{
+			name:      "postgres_scd2",
+			dbType:    models.DBTypePostgres,
+			mode:      models.DbOutputModeSCD2,
+			batchSize: 100,
+			want: []string{
+				`AND NOT (\"name\" IS NOT DISTINCT FROM $6)"`,
+			},
+		},
+		{
+			name:      "postgres_bulk_truncate",
+			dbType:    models.DBTypePostgres,
+			mode:      models.DbOutputModeBulk,
+			batchSize: 100,
+			configure: func(c *models.DBOutputConfig) {
+				c.TruncateBeforeLoad = true
+			},
+			wantErr: "truncateBeforeLoad only applies to insert and merge modes",
+		},
+		{
+			name:      "postgres_scd2_commit_single",
+			dbType:    models.DBTypePostgres,
+			mode:      models.DbOutputModeSCD2,
+			batchSize: 100,
+			configure: func(c *models.DBOutputConfig) {
+				c.CommitStrategy = models.CommitSingle
+			},
+			wantErr: "scd2 mode manages its own transactions",
+		},
+		{
+			name:      "mysql_insert_truncate_single_tx",
+			dbType:    models.DBTypeMySQL,
+			mode:      models.DbOutputModeInsert,
+			batchSize: 100,
+			configure: func(c *models.DBOutputConfig) {
+				c.CommitStrategy = models.CommitSingle
+				c.TruncateBeforeLoad = true
+			},
+			want: []string{
+				`committer, err := lib.NewCommitter(ctx, db, -1)`,
+				"committer.Exec().ExecContext(ctx, \"DELETE FROM `dbo`.`users`\")",
+				`_, err := committer.Exec().ExecContext(ctx, query, args...)`,
+				`if err := committer.Commit(); err != nil {`,
+			},
+		},
+		{
+			name:      "postgres_insert_truncate_checkpoint",
+			dbType:    models.DBTypePostgres,
+			mode:      models.DbOutputModeInsert,
+			batchSize: 100,
+			configure: func(c *models.DBOutputConfig) {
+				c.TruncateBeforeLoad = true
+				c.Checkpoint = &models.CheckpointConfig{KeyColumn: "id"}
+			},
+			want: []string{
+				// a resumed run must not empty the rows written before its checkpoint
+				"if checkpoints[2] == nil {\n\t\tif _, err := committer.Exec().ExecContext(ctx, \"TRUNCATE TABLE \\\"dbo\\\".\\\"users\\\"\"); err != nil {",
+			},
+		},
+		{
+			name:      "sqlserver_update_commit_every",
+			dbType:    models.DBTypeSQLServer,
+			mode:      models.DbOutputModeUpdate,
+			batchSize: 100,
+			configure: func(c *models.DBOutputConfig) {
+				c.CommitStrategy = models.CommitBatches
+				c.CommitEvery = 10
+			},
+			want: []string{
+				`committer, err := lib.NewCommitter(ctx, db, 10)`,
+				`if err := committer.BatchDone(ctx); err != nil {`,
+			},
+		},
+		{
+			name:      "postgres_merge",
+			dbType:    models.DBTypePostgres,
+			mode:      models.DbOutputModeMerge,
+			batchSize: 100,
+			want: []string{
+				`fmt.Sprintf("$%d", len(args)+j+1)`,
+				`" ON CONFLICT (\"id\", \"tenant\") DO UPDATE SET \"name\" = EXCLUDED.\"name\""`,
+			},
+		},
+		{
+			name:      "sqlite_merge",
+			dbType:    models.DBTypeSQLite,
+			mode:      models.DbOutputModeMerge,
+			batchSize: 100,
+			want: []string{
+				`_ "modernc.org/sqlite"`,
+				`fmt.Sprintf("?%d", len(args)+j+1)`,
+				`" ON CONFLICT (\"id\", \"tenant\") DO UPDATE SET \"name\" = excluded.\"name\""`,
+			},
+		},
+		{
+			name:      "sqlite_insert_truncate_single_tx",
+			dbType:    models.DBTypeSQLite,
+			mode:      models.DbOutputModeInsert,
+			batchSize: 100,
+			configure: func(c *models.DBOutputConfig) {
+				c.CommitStrategy = models.CommitSingle
+				c.TruncateBeforeLoad = true
+			},
+			want: []string{
+				`committer.Exec().ExecContext(ctx, "DELETE FROM \"dbo\".\"users\"")`,
+			},
+		},
+		{
+			name:      "sqlite_scd2",
+			dbType:    models.DBTypeSQLite,
+			mode:      models.DbOutputModeSCD2,
+			batchSize: 100,
+			want: []string{
+				`AND NOT (\"name\" IS ?6)"`,
+			},
+		},
+		{
+			name:      "duckdb_merge",
+			dbType:    models.DBTypeDuckDB,
+			mode:      models.DbOutputModeMerge,
+			batchSize: 100,
+			want: []string{
+				`_ "github.com/duckdb/duckdb-go/v2"`,
+				`fmt.Sprintf("$%d", len(args)+j+1)`,
+				`" ON CONFLICT (\"id\", \"tenant\") DO UPDATE SET \"name\" = EXCLUDED.\"name\""`,
+			},
+		},
+		{
+			name:      "duckdb_insert_managed",
+			dbType:    models.DBTypeDuckDB,
+			mode:      models.DbOutputModeInsert,
+			batchSize: 100,
+			configure: func(c *models.DBOutputConfig) {
+				c.DataModels = nil
+				c.AutoCreateTable = true
+			},
+			want: []string{
+				`lib.EnsureTable(ctx, db, "duckdb", "dbo", "users", "CREATE TABLE \"dbo\".\"users\" (\n  \"id\" INTEGER NOT NULL,`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := models.DBConnectionConfig{
+				Type:     tt.dbType,
+				Host:     "localhost",
+				Port:     1433,
+				Database: "warehouse",
+				Username: "etl",
+				Password: "etl",
+			}
+
+			startNode := models.Node{ID: 0, Type: models.NodeTypeStart, Name: "Start", JobID: 1}
+			inputNode := models.Node{ID: 1, Type: models.NodeTypeDBInput, Name: "Read Users", JobID: 1}
+			inputNode.SetData(models.DBInputConfig{
+				Query:      "SELECT id, tenant, name FROM users",
+				Connection: conn,
+				DataModels: columns,
+			})
+			outputNode := models.Node{ID: 2, Type: models.NodeTypeDBOutput, Name: "Write Users", JobID: 1}
+			outputConfig := models.DBOutputConfig{
+				Table:      "users",
+				Mode:       tt.mode,
+				BatchSize:  tt.batchSize,
+				DbSchema:   "dbo",
+				Connection: conn,
+				DataModels: columns,
+				KeyColumns: []string{"id", "tenant"},
+			}
+			if tt.configure != nil {
+				tt.configure(&outputConfig)
+			}
+			outputNode.SetData(outputConfig)
+
+			startNode.OutputPort = []models.Port{
+				{ID: 1, Type: models.PortNodeFlowOutput, Node: inputNode, NodeID: 0, ConnectedNodeID: 1},
+			}
+			inputNode.InputPort = []models.Port{
+				{ID: 2, Type: models.PortNodeFlowInput, Node: startNode, NodeID: 1, ConnectedNodeID: 0},
+			}
+			inputNode.OutputPort = []models.Port{
+				{ID: 3, Type: models.PortNodeFlowOutput, Node: outputNode, NodeID: 1, ConnectedNodeID: 2},
+				{ID: 4, Type: models.PortTypeOutput, Node: outputNode, NodeID: 1, ConnectedNodeID: 2},
+			}
+			outputNode.InputPort = []models.Port{
+				{ID: 5, Type: models.PortNodeFlowInput, Node: inputNode, NodeID: 2, ConnectedNodeID: 1},
+				{ID: 6, Type: models.PortTypeInput, Node: inputNode, NodeID: 2, ConnectedNodeID: 1},
+			}
+
+			job := models.Job{
+				ID:    1,
+				Name:  "Dialect Job",
+				Nodes: []models.Node{startNode, inputNode, outputNode},
+			}
+
+			exec := NewJobExecution(&job)
+			_, err := exec.build()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected build error %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("build failed: %v", err)
+			}
+
+			source, err := exec.generateSource()
+			if err != nil {
+				t.Fatalf("generateSource failed: %v", err)
+			}
+
+			fmt.Println("=== DIALECT GENERATED CODE ===")
+			fmt.Println(string(source))
+			fmt.Println("=== END ===")
+
+			for _, want := range tt.want {
+				if !strings.Contains(string(source), want) {
+					t.Errorf("generated code is missing %q", want)
+				}
+			}
+		})
+	}
+}
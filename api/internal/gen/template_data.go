@@ -15,6 +15,31 @@ type TemplateData struct {
 	NatsURL  string
 	TenantID string
 	JobID    uint
+
+	// Max runtime in seconds before the pipeline context is cancelled (0 = unlimited)
+	MaxRuntime int
+
+	// Checkpointed db_output nodes, loaded before launching nodes when Resume is set
+	Checkpoints []CheckpointData
+	Resume      bool
+
+	// Watermarks is set when incremental db_input nodes report their high-water marks
+	Watermarks bool
+}
+
+// CheckpointData represents a db_output node whose progress is checkpointed
+type CheckpointData struct {
+	JobID  uint
+	NodeID int
+	DBVar  string // e.g. "db_<connID>"
+	Driver string
+}
+
+// ErrorPolicyTemplateData holds the error policy of a node that skips or rejects failing rows
+type ErrorPolicyTemplateData struct {
+	Mode       string // skip or reject
+	RowType    string // type of the rows the node processes
+	RejectType string // struct sent on the reject port (reject mode only)
 }
 
 // ImportData represents an import statement
@@ -48,9 +73,29 @@ type NodeFunctionData struct {
 
 // DBConnectionData represents a database connection
 type DBConnectionData struct {
-	ID         string
-	Driver     string
-	ConnString string
+	ID        string
+	Driver    string
+	DSNSecret string // name of the secret holding the connection string
+	Name      string // host:port/database or file, named in connection errors
+
+	// Pool settings, 0 = driver default
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime int // seconds
+	ConnMaxIdleTime int // seconds
+	PingTimeout     int // seconds, 0 = lib.DefaultPingTimeout
+
+	// MySQL reads its TLS files through a registered configuration
+	MySQLTLS *MySQLTLSData
+}
+
+// MySQLTLSData holds the TLS files a MySQL connection registers under Name
+type MySQLTLSData struct {
+	Name       string
+	CAFile     string
+	CertFile   string
+	KeyFile    string
+	ServerName string
 }
 
 // ChannelData represents a channel between nodes
@@ -70,6 +115,7 @@ type NodeLaunchData struct {
 	Args             []string
 	HasOutputChannel bool
 	OutputChannel    string
+	RejectChannel    string // closed with the output channel when the node has a reject port
 }
 
 // MapTransformTemplateData holds data for map transformation template
@@ -80,6 +126,11 @@ type MapTransformTemplateData struct {
 	InputType  string
 	OutputType string
 	Transforms string
+
+	// Parallel execution: Workers > 1 fans rows out to that many goroutines
+	Workers       int
+	ParallelMode  string
+	PartitionKeys []string // input row fields hashed in partitioned mode
 }
 
 // MapJoinTemplateData holds data for map join templates
@@ -122,11 +173,83 @@ type DBOutputInsertTemplateData struct {
 	NodeID         int
 	NodeName       string
 	InputType      string
-	TableName      string
-	ColumnNames    string
+	QueryPrefix    string // "INSERT INTO table (columns) VALUES ", followed by the row tuples
+	Placeholder    string // Go expression of a parameter placeholder (see Dialect.PlaceholderExpr)
 	NumColumns     int
 	FieldAccessors []string
 	BatchSize      int
+	Table          *DBOutputTableTemplateData      // nil = the table is not managed
+	Checkpoint     *DBOutputCheckpointTemplateData // nil = no checkpoint
+	Commit         *DBOutputCommitTemplateData     // nil = each batch commits on its own
+	OnError        *ErrorPolicyTemplateData        // nil = fail on the first error
+}
+
+// DBInputResumeTemplateData holds the resumable query of a db_input feeding a checkpointed output.
+// The checkpoint value is inserted between QueryBefore and QueryAfter.
+type DBInputResumeTemplateData struct {
+	OutputNodeID int
+	KeyColumn    string
+	Driver       string
+	QueryBefore  string
+	QueryAfter   string
+}
+
+// DBInputParallelTemplateData holds the partitioning of a db_input node reading in parallel
+type DBInputParallelTemplateData struct {
+	Column     string // quoted partition column
+	Partitions int
+	Strategy   string // range or modulo
+}
+
+// DBOutputCheckpointTemplateData holds checkpoint settings shared by db_output templates
+type DBOutputCheckpointTemplateData struct {
+	JobID    uint
+	Driver   string
+	KeyField string // Go field accessor of the checkpoint key column (empty = offset only)
+	Action   string // "insert" or "merge", used in error messages
+
+	// Statements isolating each row of a failed batch within the checkpoint transaction
+	Savepoint           string
+	RollbackToSavepoint string
+}
+
+// DBOutputSCD2TemplateData holds data for db_output SCD2 template.
+// The *Args fields are the Go expressions passed to the matching statement.
+type DBOutputSCD2TemplateData struct {
+	FuncName    string
+	NodeID      int
+	NodeName    string
+	InputType   string
+	BatchSize   int
+	KeyCount    int    // number of key columns
+	KeyArgs     string // key columns of a row, a batch keeps the last row of each key
+	CloseQuery  string // closes the current version when a tracked column changed
+	CloseArgs   string
+	ExistsQuery string // counts the current versions of a key
+	ExistsArgs  string
+	InsertQuery string // inserts a new current version
+	InsertArgs  string
+}
+
+// DBOutputCommitTemplateData holds the commit strategy of a db_output node
+type DBOutputCommitTemplateData struct {
+	Every    int    // batches per transaction, -1 = single transaction, 0 = autocommit
+	Truncate string // statement emptying the table before the load (empty = none)
+}
+
+// DBOutputTableTemplateData holds the statements creating or evolving the target table of a db_output
+type DBOutputTableTemplateData struct {
+	Driver     string
+	Schema     string
+	Table      string
+	Create     string              // CREATE TABLE statement (empty = the table must exist)
+	AddColumns []ColumnDDLTemplate // ALTER TABLE statements, empty unless the schema evolves
+}
+
+// ColumnDDLTemplate is the statement adding a column
+type ColumnDDLTemplate struct {
+	Name string
+	DDL  string
 }
 
 // DBOutputUpdateTemplateData holds data for db_output UPDATE template
@@ -135,40 +258,65 @@ type DBOutputUpdateTemplateData struct {
 	NodeID       int
 	NodeName     string
 	InputType    string
-	TableName    string
-	NumColumns   int
+	Query        string // single-row UPDATE, SET parameters first then WHERE parameters
 	BatchSize    int
-	SetColumns   []string // columns to SET
-	SetAccessors []string // Go field accessors for SET columns
-	KeyColumns   []string // columns for WHERE clause
-	KeyAccessors []string // Go field accessors for WHERE columns
+	SetAccessors []string                    // Go field accessors for SET columns
+	KeyAccessors []string                    // Go field accessors for WHERE columns
+	Commit       *DBOutputCommitTemplateData // nil = each batch commits on its own
+	OnError      *ErrorPolicyTemplateData
 }
 
-// DBOutputDeleteTemplateData holds data for db_output DELETE template
+// DBOutputDeleteTemplateData holds data for db_output DELETE template.
+// The row conditions are joined by RowSeparator between QueryPrefix and QuerySuffix.
 type DBOutputDeleteTemplateData struct {
 	FuncName     string
 	NodeID       int
 	NodeName     string
 	InputType    string
-	TableName    string
+	QueryPrefix  string
+	QuerySuffix  string
+	RowSeparator string
+	RowValueIn   bool   // rows are key tuples of an IN list, otherwise "k1 = .. AND k2 = .." conditions
+	Placeholder  string // Go expression of a parameter placeholder (see Dialect.PlaceholderExpr)
 	BatchSize    int
-	KeyColumns   []string
+	KeyColumns   []string // quoted key columns
 	KeyAccessors []string
+	Commit       *DBOutputCommitTemplateData // nil = each batch commits on its own
+	OnError      *ErrorPolicyTemplateData
 }
 
-// DBOutputMergeTemplateData holds data for db_output MERGE (UPSERT) template
+// DBOutputMergeTemplateData holds data for db_output MERGE (UPSERT) template.
+// The row tuples are inserted between QueryPrefix and QuerySuffix.
 type DBOutputMergeTemplateData struct {
 	FuncName       string
 	NodeID         int
 	NodeName       string
 	InputType      string
-	TableName      string
-	ColumnNames    string
+	QueryPrefix    string
+	QuerySuffix    string
+	Placeholder    string // Go expression of a parameter placeholder (see Dialect.PlaceholderExpr)
 	NumColumns     int
 	BatchSize      int
 	FieldAccessors []string
-	KeyColumns     []string // conflict columns
-	UpdateColumns  []string // columns to update on conflict
+	Table          *DBOutputTableTemplateData      // nil = the table is not managed
+	Checkpoint     *DBOutputCheckpointTemplateData // nil = no checkpoint
+	Commit         *DBOutputCommitTemplateData     // nil = each batch commits on its own
+	OnError        *ErrorPolicyTemplateData        // nil = fail on the first error
+}
+
+// DBOutputBulkTemplateData holds data for db_output BULK template
+type DBOutputBulkTemplateData struct {
+	FuncName       string
+	NodeID         int
+	NodeName       string
+	InputType      string
+	Dialect        string   // "postgres", "sqlserver" or "mysql"
+	Query          string   // COPY or LOAD DATA statement (postgres, mysql)
+	TableName      string   // quoted table name (sqlserver)
+	Columns        []string // column names (sqlserver)
+	ReaderName     string   // name of the reader handler streaming rows (mysql)
+	FieldAccessors []string
+	Table          *DBOutputTableTemplateData // nil = the table is not managed
 }
 
 // DBOutputTruncateTemplateData holds data for db_output TRUNCATE template
@@ -176,7 +324,7 @@ type DBOutputTruncateTemplateData struct {
 	FuncName  string
 	NodeID    int
 	NodeName  string
-	TableName string
+	TableName string // quoted, schema-qualified table name
 }
 
 // EmailOutputTemplateData holds data for email_output template
@@ -189,7 +337,7 @@ type EmailOutputTemplateData struct {
 	SmtpHost        string
 	SmtpPort        int
 	Username        string
-	Password        string
+	PasswordSecret  string // name of the SMTP password secret
 	UseTLS          bool
 	To              string
 	CC              string
@@ -197,4 +345,5 @@ type EmailOutputTemplateData struct {
 	Subject         string
 	Body            string
 	IsHTML          bool
+	OnError         *ErrorPolicyTemplateData
 }
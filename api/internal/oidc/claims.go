@@ -0,0 +1,50 @@
+package oidc
+
+import "strings"
+
+// Claims holds the claims of a verified ID token
+type Claims map[string]any
+
+// String returns a string claim, empty when it is missing or not a string
+func (c Claims) String(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// Bool returns a boolean claim, some providers send "true" as a string
+func (c Claims) Bool(name string) bool {
+	switch v := c[name].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
+
+// Strings returns the values of a claim holding a list or a single string. path may go through
+// nested objects with dots, e.g. "realm_access.roles".
+func (c Claims) Strings(path string) []string {
+	var value any = map[string]any(c)
+	for _, part := range strings.Split(path, ".") {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = obj[part]
+	}
+
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
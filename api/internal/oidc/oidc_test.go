@@ -0,0 +1,218 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockProvider is a local OpenID Connect provider issuing one code per authorization request
+type mockProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+
+	// set by authorize, checked by token
+	challenge string
+	nonce     string
+	// claims added to or overriding the ID token claims
+	claims jwt.MapClaims
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockProvider{t: t, key: key, kid: "k1", claims: jwt.MapClaims{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kid": m.kid,
+			"kty": "RSA",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+		if r.Form.Get("code") != "the-code" || base64.RawURLEncoding.EncodeToString(sum[:]) != m.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": "at",
+			"token_type":   "Bearer",
+			"id_token":     m.idToken(m.key),
+		})
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+// authorize plays the browser and the provider login page: it returns the code
+func (m *mockProvider) authorize(authURL string) string {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("response_type") != "code" {
+		m.t.Fatalf("unexpected authorization request %s", authURL)
+	}
+	m.challenge = q.Get("code_challenge")
+	m.nonce = q.Get("nonce")
+	return "the-code"
+}
+
+func (m *mockProvider) idToken(key *rsa.PrivateKey) string {
+	claims := jwt.MapClaims{
+		"iss":   m.server.URL,
+		"aud":   "dos",
+		"sub":   "user-1",
+		"email": "jane@example.com",
+		"nonce": m.nonce,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"iat":   time.Now().Unix(),
+	}
+	for k, v := range m.claims {
+		claims[k] = v
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		m.t.Fatal(err)
+	}
+	return signed
+}
+
+func (m *mockProvider) provider() *Provider {
+	p, err := NewProvider(context.Background(), Config{
+		Issuer:       m.server.URL,
+		ClientID:     "dos",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/api/v1/auth/oidc/callback",
+	})
+	if err != nil {
+		m.t.Fatalf("NewProvider failed: %v", err)
+	}
+	return p
+}
+
+func TestProvider_CodeFlow(t *testing.T) {
+	m := newMockProvider(t)
+	m.claims["groups"] = []string{"dos-admins", "staff"}
+	p := m.provider()
+
+	verifier, nonce := GenerateVerifier(), RandomString()
+	authURL := p.AuthCodeURL("the-state", nonce, verifier)
+	if !strings.Contains(authURL, "scope=openid+profile+email") || !strings.Contains(authURL, "state=the-state") {
+		t.Fatalf("unexpected authorization URL %s", authURL)
+	}
+	code := m.authorize(authURL)
+
+	claims, err := p.Exchange(context.Background(), code, verifier, nonce)
+	if err != nil {
+		t.Fatalf("Exchange failed: %v", err)
+	}
+	if claims.String("sub") != "user-1" || claims.String("email") != "jane@example.com" {
+		t.Errorf("unexpected claims %v", claims)
+	}
+	if groups := claims.Strings("groups"); len(groups) != 2 || groups[0] != "dos-admins" {
+		t.Errorf("unexpected groups %v", groups)
+	}
+
+	// The code is bound to the PKCE verifier
+	if _, err := p.Exchange(context.Background(), code, GenerateVerifier(), nonce); err == nil {
+		t.Errorf("exchange with another verifier succeeded")
+	}
+}
+
+func TestProvider_Verify(t *testing.T) {
+	m := newMockProvider(t)
+	p := m.provider()
+	m.nonce = "n1"
+
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	tests := []struct {
+		name    string
+		claims  jwt.MapClaims
+		key     *rsa.PrivateKey
+		nonce   string
+		wantErr string
+	}{
+		{name: "valid", nonce: "n1"},
+		{name: "nonce", nonce: "n2", wantErr: "nonce does not match"},
+		{name: "audience", claims: jwt.MapClaims{"aud": "other-app"}, nonce: "n1", wantErr: "audience"},
+		{name: "issuer", claims: jwt.MapClaims{"iss": "https://evil.example.com"}, nonce: "n1", wantErr: "issuer"},
+		{name: "expired", claims: jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}, nonce: "n1", wantErr: "expired"},
+		{name: "signature", key: other, nonce: "n1", wantErr: "signature"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.claims = tt.claims
+			key := tt.key
+			if key == nil {
+				key = m.key
+			}
+			_, err := p.Verify(context.Background(), m.idToken(key), tt.nonce)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Verify failed: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestClaims_Strings(t *testing.T) {
+	claims := Claims{
+		"groups":       []any{"a", "b", 3},
+		"role":         "admin",
+		"realm_access": map[string]any{"roles": []any{"dos-admin"}},
+	}
+	if got := claims.Strings("groups"); len(got) != 2 || got[1] != "b" {
+		t.Errorf("Strings(groups) = %v", got)
+	}
+	if got := claims.Strings("role"); len(got) != 1 || got[0] != "admin" {
+		t.Errorf("Strings(role) = %v", got)
+	}
+	if got := claims.Strings("realm_access.roles"); len(got) != 1 || got[0] != "dos-admin" {
+		t.Errorf("Strings(realm_access.roles) = %v", got)
+	}
+	if got := claims.Strings("missing.path"); got != nil {
+		t.Errorf("Strings(missing.path) = %v", got)
+	}
+}
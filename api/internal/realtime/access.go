@@ -0,0 +1,54 @@
+package realtime
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// JobAccess checks the subscriptions of a client: its login session must still be active and its
+// user must be allowed to follow the progress of the job
+type JobAccess interface {
+	CanAccess(jobID, userID uint) (bool, error)
+	SessionActive(sessionID uint) (bool, error)
+}
+
+// dbJobAccess reads the main database with the rules of the API (JobRepository.FindAccess and
+// SessionService.IsActive). The queries are written here so the realtime service does not link
+// the API models and the database drivers they pull in.
+type dbJobAccess struct {
+	db *gorm.DB
+}
+
+// NewDBJobAccess checks job access and sessions in the main database
+func NewDBJobAccess(db *gorm.DB) JobAccess {
+	return &dbJobAccess{db: db}
+}
+
+// jobAccessQuery is true when the user created the job, the job is public or it is shared with
+// the user, false for an unknown job
+const jobAccessQuery = `SELECT EXISTS (
+	SELECT 1 FROM job
+	WHERE job.id = ? AND (
+		job.creator_id = ?
+		OR job.visibility = 'public'
+		OR EXISTS (SELECT 1 FROM job_user_access WHERE job_user_access.job_id = job.id AND job_user_access.user_id = ?)
+	)
+)`
+
+// sessionActiveQuery is true when the session is neither revoked nor expired
+const sessionActiveQuery = `SELECT EXISTS (
+	SELECT 1 FROM session WHERE id = ? AND revoked_at IS NULL AND expires_at > ?
+)`
+
+func (a *dbJobAccess) CanAccess(jobID, userID uint) (bool, error) {
+	var allowed bool
+	err := a.db.Raw(jobAccessQuery, jobID, userID, userID).Scan(&allowed).Error
+	return allowed, err
+}
+
+func (a *dbJobAccess) SessionActive(sessionID uint) (bool, error) {
+	var active bool
+	err := a.db.Raw(sessionActiveQuery, sessionID, time.Now()).Scan(&active).Error
+	return active, err
+}
@@ -2,6 +2,7 @@ package realtime
 
 import (
 	"api/pkg"
+	"log"
 	"net/http"
 
 	"github.com/gorilla/websocket"
@@ -11,25 +12,41 @@ var upgrader = websocket.Upgrader{
 	CheckOrigin: func(r *http.Request) bool { return true },
 }
 
-// ServeWS handles the WebSocket upgrade with JWT authentication via query param.
-func ServeWS(hub *Hub, jwtSecret string, w http.ResponseWriter, r *http.Request) {
+// ServeWS handles the WebSocket upgrade with JWT authentication via query param. The login
+// session of the token must still be active, the subscriptions of the client are checked with
+// access.
+func ServeWS(hub *Hub, access JobAccess, jwtSecret string, w http.ResponseWriter, r *http.Request) {
 	token := r.URL.Query().Get("token")
 	if token == "" {
 		http.Error(w, "missing token", http.StatusUnauthorized)
 		return
 	}
 
-	if _, err := pkg.ValidateToken(token, jwtSecret); err != nil {
+	claims, err := pkg.ValidateToken(token, jwtSecret)
+	if err != nil {
 		http.Error(w, "invalid token", http.StatusUnauthorized)
 		return
 	}
 
+	if claims.SessionID != 0 {
+		active, err := access.SessionActive(claims.SessionID)
+		if err != nil {
+			log.Printf("ws session check for session %d: %v", claims.SessionID, err)
+			http.Error(w, "session check failed", http.StatusInternalServerError)
+			return
+		}
+		if !active {
+			http.Error(w, "session revoked or expired", http.StatusUnauthorized)
+			return
+		}
+	}
+
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		return
 	}
 
-	client := NewClient(hub, conn)
+	client := NewClient(hub, conn, claims.UserID, claims.SessionID, access)
 	hub.register <- client
 
 	go client.WritePump()
@@ -21,6 +21,12 @@ type Client struct {
 	hub  *Hub
 	conn *websocket.Conn
 	send chan []byte
+
+	// userID is the authenticated user and sessionID its login session (0 for tokens without
+	// one), access checks its subscriptions
+	userID    uint
+	sessionID uint
+	access    JobAccess
 }
 
 // incomingMsg represents a command from the client.
@@ -36,11 +42,14 @@ type outgoingMsg struct {
 	Payload json.RawMessage `json:"payload"`
 }
 
-func NewClient(hub *Hub, conn *websocket.Conn) *Client {
+func NewClient(hub *Hub, conn *websocket.Conn, userID, sessionID uint, access JobAccess) *Client {
 	return &Client{
-		hub:  hub,
-		conn: conn,
-		send: make(chan []byte, sendBufSize),
+		hub:       hub,
+		conn:      conn,
+		send:      make(chan []byte, sendBufSize),
+		userID:    userID,
+		sessionID: sessionID,
+		access:    access,
 	}
 }
 
@@ -76,7 +85,7 @@ func (c *Client) ReadPump() {
 		switch msg.Action {
 		case "subscribe":
 			if msg.JobID > 0 {
-				c.hub.subscribe <- subscribeMsg{client: c, jobID: msg.JobID}
+				c.hub.subscribe <- subscribeMsg{client: c, jobID: msg.JobID, denied: !c.canAccess(msg.JobID)}
 			}
 		default:
 			log.Printf("ws unknown action: %s", msg.Action)
@@ -84,6 +93,27 @@ func (c *Client) ReadPump() {
 	}
 }
 
+// canAccess checks the session is still active and the user may follow a job, the check runs
+// before the hub so a slow database does not hold up the other clients
+func (c *Client) canAccess(jobID uint) bool {
+	if c.sessionID != 0 {
+		active, err := c.access.SessionActive(c.sessionID)
+		if err != nil {
+			log.Printf("ws session check for session %d: %v", c.sessionID, err)
+			return false
+		}
+		if !active {
+			return false
+		}
+	}
+	allowed, err := c.access.CanAccess(jobID, c.userID)
+	if err != nil {
+		log.Printf("ws access check for job %d: %v", jobID, err)
+		return false
+	}
+	return allowed
+}
+
 // WritePump writes messages to the WebSocket connection.
 func (c *Client) WritePump() {
 	ticker := time.NewTicker(pingPeriod)
@@ -7,6 +7,14 @@ type Config struct {
 	TenantID     string
 	JWTSecret    string
 	RealtimePort string
+
+	// Main database, read to authorize the job subscriptions
+	DBHost     string
+	DBPort     string
+	DBUser     string
+	DBPassword string
+	DBName     string
+	DBSSLMode  string
 }
 
 func LoadConfig() Config {
@@ -15,6 +23,13 @@ func LoadConfig() Config {
 		TenantID:     getEnv("TENANT_ID", "default"),
 		JWTSecret:    getEnv("JWT_SECRET", ""),
 		RealtimePort: getEnv("REALTIME_PORT", ":8081"),
+
+		DBHost:     getEnv("DB_HOSTNAME", "localhost"),
+		DBPort:     getEnv("DB_PORT", "5432"),
+		DBUser:     getEnv("DB_USERNAME", "postgres"),
+		DBPassword: getEnv("DB_PASSWORD", ""),
+		DBName:     getEnv("DB_NAME", "data_open_studio"),
+		DBSSLMode:  getEnv("DB_SSL_MODE", "disable"),
 	}
 }
 
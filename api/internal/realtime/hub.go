@@ -1,6 +1,9 @@
 package realtime
 
-import "log"
+import (
+	"encoding/json"
+	"log"
+)
 
 // Hub manages WebSocket clients and routes messages by jobID.
 type Hub struct {
@@ -19,6 +22,8 @@ type Hub struct {
 type subscribeMsg struct {
 	client *Client
 	jobID  uint
+	// denied is set when the user cannot access the job, the client is told instead
+	denied bool
 }
 
 type broadcastMsg struct {
@@ -59,6 +64,18 @@ func (h *Hub) Run() {
 			}
 
 		case msg := <-h.subscribe:
+			// The client may have been dropped (full buffer) while its access was checked
+			if !h.clients[msg.client] {
+				continue
+			}
+			if msg.denied {
+				select {
+				case msg.client.send <- subscribeDenied(msg.jobID):
+				default:
+				}
+				log.Printf("client denied subscription to job %d", msg.jobID)
+				continue
+			}
 			if _, ok := h.subscriptions[msg.jobID]; !ok {
 				h.subscriptions[msg.jobID] = make(map[*Client]bool)
 			}
@@ -81,3 +98,9 @@ func (h *Hub) Run() {
 		}
 	}
 }
+
+// subscribeDenied is the message telling a client it cannot follow a job
+func subscribeDenied(jobID uint) []byte {
+	data, _ := json.Marshal(outgoingMsg{Type: "subscribe.denied", JobID: jobID})
+	return data
+}
@@ -0,0 +1,115 @@
+package realtime
+
+import (
+	"api/pkg"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const testSecret = "realtime-test-secret"
+
+// fakeAccess allows the jobs of its map and the sessions that are not revoked
+type fakeAccess struct {
+	mu      sync.Mutex
+	jobs    map[uint]bool
+	revoked map[uint]bool
+}
+
+func (f *fakeAccess) CanAccess(jobID, userID uint) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.jobs[jobID], nil
+}
+
+func (f *fakeAccess) SessionActive(sessionID uint) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return !f.revoked[sessionID], nil
+}
+
+func (f *fakeAccess) revoke(sessionID uint) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.revoked[sessionID] = true
+}
+
+// dialWS opens a connection to the test server with a token for the session
+func dialWS(t *testing.T, server *httptest.Server, sessionID uint) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	token, err := pkg.GenerateToken(7, sessionID, "user@example.com", "Doe", "Jane", "user", testSecret, 5)
+	if err != nil {
+		t.Fatalf("generate token: %v", err)
+	}
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws?token=" + token
+	return websocket.DefaultDialer.Dial(url, nil)
+}
+
+func subscribe(t *testing.T, conn *websocket.Conn, jobID uint) {
+	t.Helper()
+	if err := conn.WriteJSON(incomingMsg{Action: "subscribe", JobID: jobID}); err != nil {
+		t.Fatalf("subscribe to job %d: %v", jobID, err)
+	}
+}
+
+func readMsg(t *testing.T, conn *websocket.Conn) outgoingMsg {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var msg outgoingMsg
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read message: %v", err)
+	}
+	return msg
+}
+
+func TestServeWS_Subscribe(t *testing.T) {
+	access := &fakeAccess{jobs: map[uint]bool{1: true, 3: true}, revoked: map[uint]bool{9: true}}
+	hub := NewHub()
+	go hub.Run()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ServeWS(hub, access, testSecret, w, r)
+	}))
+	defer server.Close()
+
+	t.Run("revoked session is refused", func(t *testing.T) {
+		conn, resp, err := dialWS(t, server, 9)
+		if err == nil {
+			conn.Close()
+			t.Fatal("dial with a revoked session succeeded")
+		}
+		if resp == nil || resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("dial with a revoked session: got %v, want 401", resp)
+		}
+	})
+
+	conn, _, err := dialWS(t, server, 4)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	// Subscriptions of a client reach the hub in order: once job 2 is denied, job 1 is subscribed
+	subscribe(t, conn, 1)
+	subscribe(t, conn, 2)
+	if msg := readMsg(t, conn); msg.Type != "subscribe.denied" || msg.JobID != 2 {
+		t.Fatalf("subscribe to job 2: got %+v, want subscribe.denied", msg)
+	}
+
+	hub.broadcast <- broadcastMsg{jobID: 2, payload: []byte(`{"type":"progress","jobId":2}`)}
+	hub.broadcast <- broadcastMsg{jobID: 1, payload: []byte(`{"type":"progress","jobId":1}`)}
+	if msg := readMsg(t, conn); msg.Type != "progress" || msg.JobID != 1 {
+		t.Fatalf("broadcast: got %+v, want the progress of job 1 only", msg)
+	}
+
+	// A session revoked after the upgrade cannot subscribe anymore
+	access.revoke(4)
+	subscribe(t, conn, 3)
+	if msg := readMsg(t, conn); msg.Type != "subscribe.denied" || msg.JobID != 3 {
+		t.Fatalf("subscribe after revocation: got %+v, want subscribe.denied", msg)
+	}
+}
@@ -0,0 +1,128 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// Fields lists the JSON keys holding credentials in node data and trigger configurations
+var Fields = []string{"password", "privateKey", "clientKey"}
+
+func isField(key string) bool {
+	for _, f := range Fields {
+		if f == key {
+			return true
+		}
+	}
+	return false
+}
+
+// SealJSON seals the credential fields of a JSON document
+func SealJSON(raw []byte) ([]byte, error) {
+	return transformJSON(raw, func(_, value string) (string, error) {
+		return Seal(value)
+	})
+}
+
+// OpenJSON opens the credential fields of a JSON document
+func OpenJSON(raw []byte) ([]byte, error) {
+	return transformJSON(raw, func(_, value string) (string, error) {
+		return Open(value)
+	})
+}
+
+// RewrapJSON moves the credential fields of a JSON document to the active key of k
+func RewrapJSON(k *Keyring, raw []byte) ([]byte, bool, error) {
+	changed := false
+	out, err := transformJSON(raw, func(_, value string) (string, error) {
+		rewrapped, ok, err := k.Rewrap(value)
+		changed = changed || ok
+		return rewrapped, err
+	})
+	return out, changed, err
+}
+
+// RedactJSON blanks the credential fields of a JSON document so it can be returned by the API
+func RedactJSON(raw []byte) []byte {
+	out, err := transformJSON(raw, func(_, _ string) (string, error) {
+		return "", nil
+	})
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+// MergeJSON copies into incoming the credentials of stored that incoming left blank, so a
+// document read back from the API (see RedactJSON) can be saved without losing them.
+func MergeJSON(incoming, stored []byte) ([]byte, error) {
+	previous := map[string]string{}
+	if _, err := transformJSON(stored, func(path, value string) (string, error) {
+		previous[path] = value
+		return value, nil
+	}); err != nil {
+		return nil, err
+	}
+	return transformJSON(incoming, func(path, value string) (string, error) {
+		if value == "" {
+			return previous[path], nil
+		}
+		return value, nil
+	})
+}
+
+// transformJSON applies fn to the string values of the credential fields, path being the
+// JSON pointer of the field. The document is returned as is when fn changed nothing.
+func transformJSON(raw []byte, fn func(path, value string) (string, error)) ([]byte, error) {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return raw, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	changed, err := walk(doc, "", fn)
+	if err != nil || !changed {
+		return raw, err
+	}
+	return json.Marshal(doc)
+}
+
+func walk(node any, path string, fn func(path, value string) (string, error)) (bool, error) {
+	changed := false
+	switch v := node.(type) {
+	case map[string]any:
+		for key, child := range v {
+			childPath := path + "/" + key
+			if s, ok := child.(string); ok && isField(key) {
+				out, err := fn(childPath, s)
+				if err != nil {
+					return false, err
+				}
+				if out != s {
+					v[key] = out
+					changed = true
+				}
+				continue
+			}
+			c, err := walk(child, childPath, fn)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || c
+		}
+	case []any:
+		for i, child := range v {
+			c, err := walk(child, path+"/"+strconv.Itoa(i), fn)
+			if err != nil {
+				return false, err
+			}
+			changed = changed || c
+		}
+	}
+	return changed, nil
+}
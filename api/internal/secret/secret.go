@@ -0,0 +1,253 @@
+// Package secret encrypts the credentials stored in the database.
+//
+// Values are sealed with envelope encryption: each value is encrypted with its own random data key
+// (AES-256-GCM) and the data key is encrypted with the active master key. Master keys are
+// identified by an ID kept in the sealed value, so older keys keep decrypting after a rotation and
+// Rewrap moves a value to the active key by re-encrypting its data key only.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Prefix marks a sealed value: enc:v1:<key id>:<wrapped data key>:<ciphertext>
+const Prefix = "enc:v1:"
+
+const keySize = 32
+
+// Keyring holds the master keys. The active key seals, every key opens.
+type Keyring struct {
+	keys   map[string]cipher.AEAD
+	active string
+}
+
+// ParseKeyring reads master keys from "id:base64key,id:base64key", the first one being active.
+// An empty spec returns a nil keyring, values are then stored as they are.
+func ParseKeyring(spec string) (*Keyring, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	k := &Keyring{keys: map[string]cipher.AEAD{}}
+	for _, entry := range strings.Split(spec, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid master key entry %q: expected id:base64key", entry)
+		}
+		if _, dup := k.keys[id]; dup {
+			return nil, fmt.Errorf("master key %q is defined twice", id)
+		}
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("master key %q is not valid base64: %w", id, err)
+		}
+		if len(raw) != keySize {
+			return nil, fmt.Errorf("master key %q must be %d bytes, got %d", id, keySize, len(raw))
+		}
+		aead, err := newAEAD(raw)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+		if k.active == "" {
+			k.active = id
+		}
+	}
+	return k, nil
+}
+
+// ActiveKeyID returns the ID of the key new values are sealed with
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// Seal encrypts plain with a fresh data key wrapped by the active key. Empty and already sealed
+// values are returned unchanged.
+func (k *Keyring) Seal(plain string) (string, error) {
+	if plain == "" || IsSealed(plain) {
+		return plain, nil
+	}
+
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(data, []byte(plain))
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := seal(k.keys[k.active], dataKey)
+	if err != nil {
+		return "", err
+	}
+	return Prefix + k.active + ":" + encode(wrapped) + ":" + encode(ciphertext), nil
+}
+
+// Open decrypts a sealed value. Values that are not sealed (stored before encryption was
+// enabled) are returned unchanged.
+func (k *Keyring) Open(value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+	id, wrapped, ciphertext, err := parse(value)
+	if err != nil {
+		return "", err
+	}
+	dataKey, err := k.unwrap(id, wrapped)
+	if err != nil {
+		return "", err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	plain, err := open(data, ciphertext)
+	if err != nil {
+		return "", errors.New("secret: value cannot be decrypted")
+	}
+	return string(plain), nil
+}
+
+// Rewrap moves a value to the active key and reports whether it changed. Sealed values keep their
+// ciphertext and only get their data key re-encrypted; plaintext values are sealed.
+func (k *Keyring) Rewrap(value string) (string, bool, error) {
+	if value == "" {
+		return value, false, nil
+	}
+	if !IsSealed(value) {
+		sealed, err := k.Seal(value)
+		return sealed, err == nil, err
+	}
+
+	id, wrapped, ciphertext, err := parse(value)
+	if err != nil {
+		return "", false, err
+	}
+	if id == k.active {
+		return value, false, nil
+	}
+	dataKey, err := k.unwrap(id, wrapped)
+	if err != nil {
+		return "", false, err
+	}
+	rewrapped, err := seal(k.keys[k.active], dataKey)
+	if err != nil {
+		return "", false, err
+	}
+	return Prefix + k.active + ":" + encode(rewrapped) + ":" + encode(ciphertext), true, nil
+}
+
+func (k *Keyring) unwrap(id string, wrapped []byte) ([]byte, error) {
+	master, ok := k.keys[id]
+	if !ok {
+		return nil, fmt.Errorf("secret: master key %q is not configured", id)
+	}
+	dataKey, err := open(master, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("secret: data key cannot be decrypted with master key %q", id)
+	}
+	return dataKey, nil
+}
+
+// IsSealed reports whether value was produced by Seal
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, Prefix)
+}
+
+var (
+	mu      sync.RWMutex
+	current *Keyring
+)
+
+// SetKeyring installs the keyring used by the package-level functions
+func SetKeyring(k *Keyring) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = k
+}
+
+// Current returns the installed keyring, nil when encryption is disabled
+func Current() *Keyring {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Seal seals plain with the installed keyring, or returns it unchanged when there is none
+func Seal(plain string) (string, error) {
+	k := Current()
+	if k == nil {
+		return plain, nil
+	}
+	return k.Seal(plain)
+}
+
+// Open opens value with the installed keyring. A sealed value without keyring is an error rather
+// than ciphertext handed to a database driver as a password.
+func Open(value string) (string, error) {
+	k := Current()
+	if k == nil {
+		if IsSealed(value) {
+			return "", errors.New("secret: value is encrypted but no master key is configured")
+		}
+		return value, nil
+	}
+	return k.Open(value)
+}
+
+func parse(value string) (string, []byte, []byte, error) {
+	parts := strings.Split(strings.TrimPrefix(value, Prefix), ":")
+	if len(parts) != 3 {
+		return "", nil, nil, errors.New("secret: malformed sealed value")
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", nil, nil, errors.New("secret: malformed sealed value")
+	}
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", nil, nil, errors.New("secret: malformed sealed value")
+	}
+	return parts[0], wrapped, ciphertext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal returns nonce || ciphertext
+func seal(aead cipher.AEAD, plain []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plain, nil), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func encode(b []byte) string {
+	return base64.RawStdEncoding.EncodeToString(b)
+}
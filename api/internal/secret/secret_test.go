@@ -0,0 +1,106 @@
+package secret
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func testKey(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(rune(b)), keySize)))
+}
+
+func TestKeyring_SealOpenRotate(t *testing.T) {
+	old, err := ParseKeyring("k1:" + testKey('a'))
+	if err != nil {
+		t.Fatalf("ParseKeyring failed: %v", err)
+	}
+
+	sealed, err := old.Seal("s3cret")
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if !strings.HasPrefix(sealed, Prefix+"k1:") || strings.Contains(sealed, "s3cret") {
+		t.Fatalf("unexpected sealed value %q", sealed)
+	}
+	if again, _ := old.Seal("s3cret"); again == sealed {
+		t.Errorf("two seals of the same value are identical")
+	}
+	if plain, err := old.Open(sealed); err != nil || plain != "s3cret" {
+		t.Fatalf("Open = %q, %v", plain, err)
+	}
+	if plain, err := old.Open("legacy"); err != nil || plain != "legacy" {
+		t.Errorf("plaintext values must open to themselves, got %q, %v", plain, err)
+	}
+
+	// k2 becomes active, k1 still opens the values it sealed
+	rotated, err := ParseKeyring("k2:" + testKey('b') + ",k1:" + testKey('a'))
+	if err != nil {
+		t.Fatalf("ParseKeyring failed: %v", err)
+	}
+	if plain, err := rotated.Open(sealed); err != nil || plain != "s3cret" {
+		t.Fatalf("Open with the previous key = %q, %v", plain, err)
+	}
+	rewrapped, changed, err := rotated.Rewrap(sealed)
+	if err != nil || !changed || !strings.HasPrefix(rewrapped, Prefix+"k2:") {
+		t.Fatalf("Rewrap = %q, %v, %v", rewrapped, changed, err)
+	}
+	if _, changed, _ := rotated.Rewrap(rewrapped); changed {
+		t.Errorf("values sealed with the active key must not be rewrapped")
+	}
+
+	onlyNew, _ := ParseKeyring("k2:" + testKey('b'))
+	if plain, err := onlyNew.Open(rewrapped); err != nil || plain != "s3cret" {
+		t.Errorf("Open after rotation = %q, %v", plain, err)
+	}
+	if _, err := onlyNew.Open(sealed); err == nil || !strings.Contains(err.Error(), `"k1" is not configured`) {
+		t.Errorf("expected a missing key error, got %v", err)
+	}
+}
+
+func TestParseKeyring_Invalid(t *testing.T) {
+	for _, spec := range []string{"nokey", "k1:not-base64!", "k1:" + base64.StdEncoding.EncodeToString([]byte("short")), "k1:" + testKey('a') + ",k1:" + testKey('b')} {
+		if _, err := ParseKeyring(spec); err == nil {
+			t.Errorf("ParseKeyring(%q) succeeded", spec)
+		}
+	}
+	if k, err := ParseKeyring(""); k != nil || err != nil {
+		t.Errorf("an empty spec must disable encryption, got %v, %v", k, err)
+	}
+}
+
+func TestJSONFields(t *testing.T) {
+	k, _ := ParseKeyring("k1:" + testKey('a'))
+	SetKeyring(k)
+	defer SetKeyring(nil)
+
+	doc := []byte(`{"query":"SELECT 1","batchSize":500,"connection":{"host":"db","password":"pw","tls":{"clientKey":"pem"}}}`)
+	sealed, err := SealJSON(doc)
+	if err != nil {
+		t.Fatalf("SealJSON failed: %v", err)
+	}
+	if strings.Contains(string(sealed), `"pw"`) || strings.Contains(string(sealed), `"pem"`) || !strings.Contains(string(sealed), `"batchSize":500`) {
+		t.Fatalf("unexpected sealed document %s", sealed)
+	}
+
+	opened, err := OpenJSON(sealed)
+	if err != nil || !strings.Contains(string(opened), `"password":"pw"`) || !strings.Contains(string(opened), `"clientKey":"pem"`) {
+		t.Fatalf("OpenJSON = %s, %v", opened, err)
+	}
+
+	redacted := RedactJSON(opened)
+	if strings.Contains(string(redacted), "pw") || !strings.Contains(string(redacted), `"password":""`) {
+		t.Fatalf("unexpected redacted document %s", redacted)
+	}
+
+	edited := []byte(strings.Replace(string(redacted), `"host":"db"`, `"host":"db2"`, 1))
+	merged, err := MergeJSON(edited, opened)
+	if err != nil || !strings.Contains(string(merged), `"host":"db2"`) || !strings.Contains(string(merged), `"password":"pw"`) {
+		t.Fatalf("MergeJSON = %s, %v", merged, err)
+	}
+
+	plain := []byte(`{"query":"SELECT 1"}`)
+	if out, _ := SealJSON(plain); string(out) != string(plain) {
+		t.Errorf("documents without credentials must be kept as is, got %s", out)
+	}
+}
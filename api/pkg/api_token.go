@@ -0,0 +1,35 @@
+package pkg
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+)
+
+// APITokenPrefix starts every personal access token, telling them apart from JWTs
+const APITokenPrefix = "dos_pat_"
+
+// GenerateAPIToken returns a new personal access token, the prefix shown to identify it and the
+// hash to store
+func GenerateAPIToken() (token string, prefix string, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err = rand.Read(raw); err != nil {
+		return "", "", "", err
+	}
+	token = APITokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	return token, token[:len(APITokenPrefix)+4], HashAPIToken(token), nil
+}
+
+// HashAPIToken returns the hex SHA-256 a token is stored and looked up by. Tokens are random, a
+// slow password hash is not needed.
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIToken reports whether a bearer token is a personal access token
+func IsAPIToken(token string) bool {
+	return strings.HasPrefix(token, APITokenPrefix)
+}
@@ -13,21 +13,37 @@ type JWTClaims struct {
 	Prenom string `json:"prenom"`
 	Nom    string `json:"nom"`
 	Role   string `json:"role"`
+	// SessionID is the session the token was issued for, access tokens die with their session
+	SessionID uint `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// RefreshTokenClaims identify the session a refresh token belongs to and the generation of the
+// token within that session: only the latest generation is accepted
 type RefreshTokenClaims struct {
-	UserID uint `json:"userId"`
+	UserID     uint `json:"userId"`
+	SessionID  uint `json:"sid"`
+	Generation uint `json:"gen"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uint, email string, nom string, prenom string, role string, secret string, expirationMinutes int) (string, error) {
+// OIDCStateClaims keeps the state, nonce and PKCE verifier of a single sign-on login between the
+// redirect to the provider and the callback, in a cookie of the browser
+type OIDCStateClaims struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	jwt.RegisteredClaims
+}
+
+func GenerateToken(userID uint, sessionID uint, email string, nom string, prenom string, role string, secret string, expirationMinutes int) (string, error) {
 	claims := JWTClaims{
-		UserID: userID,
-		Email:  email,
-		Prenom: prenom,
-		Nom:    nom,
-		Role:   role,
+		UserID:    userID,
+		Email:     email,
+		Prenom:    prenom,
+		Nom:       nom,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute * time.Duration(expirationMinutes))),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -38,9 +54,11 @@ func GenerateToken(userID uint, email string, nom string, prenom string, role st
 	return token.SignedString([]byte(secret))
 }
 
-func GenerateRefreshToken(userID uint, secret string, expirationDays int) (string, error) {
+func GenerateRefreshToken(userID uint, sessionID uint, generation uint, secret string, expirationDays int) (string, error) {
 	claims := RefreshTokenClaims{
-		UserID: userID,
+		UserID:     userID,
+		SessionID:  sessionID,
+		Generation: generation,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * 24 * time.Duration(expirationDays))),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -88,3 +106,42 @@ func ValidateRefreshToken(tokenString string, secret string) (*RefreshTokenClaim
 
 	return nil, errors.New("invalid refresh token")
 }
+
+func GenerateOIDCStateToken(state string, nonce string, verifier string, secret string, expiration time.Duration) (string, error) {
+	claims := OIDCStateClaims{
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(oidcStateKey(secret))
+}
+
+// oidcStateKey derives the key of state tokens so they are never accepted as access tokens
+func oidcStateKey(secret string) []byte {
+	return []byte(secret + "/oidc-state")
+}
+
+func ValidateOIDCStateToken(tokenString string, secret string) (*OIDCStateClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &OIDCStateClaims{}, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return oidcStateKey(secret), nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*OIDCStateClaims); ok && token.Valid && claims.State != "" {
+		return claims, nil
+	}
+
+	return nil, errors.New("invalid oidc state")
+}
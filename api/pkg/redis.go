@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"math/rand/v2"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -58,6 +60,71 @@ func RedisExists(key string) (bool, error) {
 	return n > 0, nil
 }
 
+// RedisTTL returns the time to live of a key, 0 if it does not exist or never expires.
+func RedisTTL(key string) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ttl, err := api.Redis.PTTL(ctx, key).Result()
+	if err != nil || ttl < 0 {
+		return 0, err
+	}
+	return ttl, nil
+}
+
+// RedisIncr increments a counter and returns its new value. The counter expires ttl after its
+// first increment.
+func RedisIncr(key string, ttl time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	n, err := api.Redis.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if n == 1 {
+		if err := api.Redis.PExpire(ctx, key, ttl).Err(); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// slidingWindowScript keeps the hits of a key in a sorted set scored by time (ms). It drops the
+// hits older than the window and records a new one when fewer than the limit remain, returning
+// {1, 0}; otherwise {0, ms until the oldest hit leaves the window}.
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+if redis.call('ZCARD', KEYS[1]) >= tonumber(ARGV[3]) then
+	local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	return {0, tonumber(oldest[2]) + window - now}
+end
+redis.call('ZADD', KEYS[1], now, ARGV[4])
+redis.call('PEXPIRE', KEYS[1], window)
+return {1, 0}
+`)
+
+// RedisSlidingWindow records a hit on key unless limit hits already happened in the last window.
+// When refused, it returns how long until the next hit is allowed.
+func RedisSlidingWindow(key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now().UnixMilli()
+	// Hits of the same millisecond need distinct members
+	member := fmt.Sprintf("%d-%d", now, rand.Uint64())
+	res, err := slidingWindowScript.Run(ctx, api.Redis, []string{key}, now, window.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if len(res) != 2 {
+		return false, 0, errors.New("unexpected sliding window result")
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
+
 // IsRedisNil returns true if the error is a redis key-not-found error.
 func IsRedisNil(err error) bool {
 	return errors.Is(err, redis.Nil)